@@ -0,0 +1,104 @@
+package immutable
+
+import "golang.org/x/exp/constraints"
+
+// Adapter provides a mutable map API backed by an OrderedMap. Each change replaces the adapter's
+// map with a new version, so snapshots taken with Snapshot are unaffected by later changes. This
+// eases migrating code written against mutable maps to persistent ones.
+//
+// Adapter is safe for concurrent use. Concurrent changes are applied atomically, and none of them
+// are lost.
+//
+// The zero value is an empty adapter ready to use. An Adapter must not be copied after first use.
+type Adapter[K constraints.Ordered, V any] struct {
+	// The counters come first so that they're 64-bit aligned on 32-bit platforms.
+	sets    atomicUint64
+	deletes atomicUint64
+	retries atomicUint64
+	m       atomicPointer[OrderedMap[K, V]]
+}
+
+// Get returns the value associated with the given key if set.
+//
+// Complexity: O(log n) worst-case
+func (a *Adapter[K, V]) Get(key K) (V, bool) {
+	return a.m.Load().Get(key)
+}
+
+// Len returns the number of elements in the map.
+//
+// Complexity: O(1) worst-case
+func (a *Adapter[K, V]) Len() int {
+	return a.m.Load().Len()
+}
+
+// Set associates a value with the given key. Like OrderedMap.Set, it panics if the key is NaN.
+//
+// Complexity: O(log n) worst-case, if there is no contention
+func (a *Adapter[K, V]) Set(key K, value V) {
+	a.sets.Add(1)
+	a.update(func(m *OrderedMap[K, V]) *OrderedMap[K, V] {
+		return m.Set(key, value)
+	})
+}
+
+// Delete removes a key from the map.
+//
+// Complexity: O(log n) worst-case, if there is no contention
+func (a *Adapter[K, V]) Delete(key K) {
+	a.deletes.Add(1)
+	a.update(func(m *OrderedMap[K, V]) *OrderedMap[K, V] {
+		return m.Delete(key)
+	})
+}
+
+// update replaces the map with the result of f, retrying if another goroutine changed it first.
+func (a *Adapter[K, V]) update(f func(m *OrderedMap[K, V]) *OrderedMap[K, V]) {
+	for {
+		old := a.m.Load()
+		if updated := f(old); updated == old || a.m.CompareAndSwap(old, updated) {
+			return
+		}
+		a.retries.Add(1)
+	}
+}
+
+// Sets returns the number of times Set has been called.
+//
+// Complexity: O(1) worst-case
+func (a *Adapter[K, V]) Sets() uint64 {
+	return a.sets.Load()
+}
+
+// Deletes returns the number of times Delete has been called, including for keys which weren't
+// present.
+//
+// Complexity: O(1) worst-case
+func (a *Adapter[K, V]) Deletes() uint64 {
+	return a.deletes.Load()
+}
+
+// Retries returns the number of times a change made by Set or Delete had to be applied again
+// because another goroutine changed the map first. A high rate of retries indicates heavy
+// contention.
+//
+// Complexity: O(1) worst-case
+func (a *Adapter[K, V]) Retries() uint64 {
+	return a.retries.Load()
+}
+
+// Snapshot returns the current version of the map. It is unaffected by later changes made through
+// the adapter.
+//
+// Complexity: O(1) worst-case
+func (a *Adapter[K, V]) Snapshot() *OrderedMap[K, V] {
+	return a.m.Load()
+}
+
+// Restore replaces the contents of the adapter with the given map, such as one previously returned
+// by Snapshot.
+//
+// Complexity: O(1) worst-case
+func (a *Adapter[K, V]) Restore(m *OrderedMap[K, V]) {
+	a.m.Store(m)
+}
@@ -0,0 +1,59 @@
+package immutable
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdapter(t *testing.T) {
+	var a Adapter[string, int]
+	assert.Equal(t, 0, a.Len())
+	_, ok := a.Get("foo")
+	assert.False(t, ok)
+	assert.Nil(t, a.Snapshot())
+
+	a.Set("foo", 1)
+	a.Set("bar", 2)
+	snapshot := a.Snapshot()
+	a.Delete("foo")
+	a.Delete("baz")
+
+	assert.Equal(t, 1, a.Len())
+	_, ok = a.Get("foo")
+	assert.False(t, ok)
+	v, ok := a.Get("bar")
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	assert.Equal(t, 2, snapshot.Len())
+	assert.Equal(t, uint64(2), a.Sets())
+	assert.Equal(t, uint64(2), a.Deletes())
+	assert.Equal(t, uint64(0), a.Retries())
+
+	a.Restore(snapshot)
+	v, ok = a.Get("foo")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+}
+
+func TestAdapter_Concurrency(t *testing.T) {
+	var a Adapter[int, int]
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				a.Set(i*100+j, j)
+				a.Delete(-1)
+				a.Get(j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 800, a.Len())
+	assert.Equal(t, uint64(800), a.Sets())
+	assert.Equal(t, uint64(800), a.Deletes())
+	assert.NoError(t, a.Snapshot().CheckInvariants())
+}
@@ -0,0 +1,39 @@
+package immutable
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// atomicPointer is an atomic *T, like atomic.Pointer, which isn't available before Go 1.19. The
+// zero value is nil.
+type atomicPointer[T any] struct {
+	p unsafe.Pointer
+}
+
+func (p *atomicPointer[T]) Load() *T {
+	return (*T)(atomic.LoadPointer(&p.p))
+}
+
+func (p *atomicPointer[T]) Store(v *T) {
+	atomic.StorePointer(&p.p, unsafe.Pointer(v))
+}
+
+func (p *atomicPointer[T]) CompareAndSwap(old, new *T) bool {
+	return atomic.CompareAndSwapPointer(&p.p, unsafe.Pointer(old), unsafe.Pointer(new))
+}
+
+// atomicUint64 is an atomic uint64, like atomic.Uint64, which isn't available before Go 1.19.
+// Unlike atomic.Uint64, it isn't automatically aligned, so on 32-bit platforms it must be placed at
+// the start of a struct, before any fields which aren't 64-bit aligned.
+type atomicUint64 struct {
+	v uint64
+}
+
+func (u *atomicUint64) Load() uint64 {
+	return atomic.LoadUint64(&u.v)
+}
+
+func (u *atomicUint64) Add(delta uint64) uint64 {
+	return atomic.AddUint64(&u.v, delta)
+}
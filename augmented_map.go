@@ -0,0 +1,365 @@
+package immutable
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+// AugmentedMap implements a map with in-order iteration which also maintains an aggregate of its
+// elements, such as the sum of their values or the maximum of some field. Aggregates of arbitrary
+// key ranges can be queried in logarithmic time, which makes AugmentedMap a building block for
+// prefix sums, interval trees, and similar structures.
+//
+// Aggregates are computed with a monoid: measure maps each element to an aggregate, combine merges
+// two aggregates, and identity is the aggregate of no elements. combine must be associative, and
+// identity must be its identity element, but combine doesn't need to be commutative. Aggregates are
+// always combined in ascending key order. measure and combine must be pure functions.
+//
+// Like OrderedMap, AugmentedMap doesn't support NaN keys.
+//
+// AugmentedMap must be created with NewAugmentedMap, which provides the monoid. Nil and the zero
+// value for AugmentedMap are empty maps whose aggregates are the zero value of A, but since they have
+// no monoid, Set panics if called on them.
+type AugmentedMap[K constraints.Ordered, V, A any] struct {
+	root   *augmentedMapNode[K, V, A]
+	monoid *augmentedMapMonoid[K, V, A]
+}
+
+type augmentedMapMonoid[K constraints.Ordered, V, A any] struct {
+	measure  func(key K, value V) A
+	combine  func(a, b A) A
+	identity A
+}
+
+type augmentedMapNode[K constraints.Ordered, V, A any] struct {
+	height    int
+	len       int
+	left      *augmentedMapNode[K, V, A]
+	right     *augmentedMapNode[K, V, A]
+	key       K
+	value     V
+	aggregate A
+}
+
+// NewAugmentedMap returns an empty map which maintains aggregates using the given monoid.
+//
+// Complexity: O(1) worst-case
+func NewAugmentedMap[K constraints.Ordered, V, A any](measure func(key K, value V) A, combine func(a, b A) A, identity A) *AugmentedMap[K, V, A] {
+	return &AugmentedMap[K, V, A]{
+		monoid: &augmentedMapMonoid[K, V, A]{
+			measure:  measure,
+			combine:  combine,
+			identity: identity,
+		},
+	}
+}
+
+func (m *AugmentedMap[K, V, A]) withRoot(root *augmentedMapNode[K, V, A]) *AugmentedMap[K, V, A] {
+	return &AugmentedMap[K, V, A]{
+		root:   root,
+		monoid: m.monoid,
+	}
+}
+
+func (m *AugmentedMap[K, V, A]) rootNode() *augmentedMapNode[K, V, A] {
+	if m == nil {
+		return nil
+	}
+	return m.root
+}
+
+// identity returns the aggregate of no elements, which is the zero value of A if the map has no
+// monoid.
+func (m *AugmentedMap[K, V, A]) identity() A {
+	if m == nil || m.monoid == nil {
+		var zero A
+		return zero
+	}
+	return m.monoid.identity
+}
+
+func (n *augmentedMapNode[K, V, A]) Height() int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func (n *augmentedMapNode[K, V, A]) Len() int {
+	if n == nil {
+		return 0
+	}
+	return n.len
+}
+
+func (mo *augmentedMapMonoid[K, V, A]) aggregate(n *augmentedMapNode[K, V, A]) A {
+	if n == nil {
+		return mo.identity
+	}
+	return n.aggregate
+}
+
+func (mo *augmentedMapMonoid[K, V, A]) node(key K, value V, left, right *augmentedMapNode[K, V, A]) *augmentedMapNode[K, V, A] {
+	height := left.Height()
+	if right.Height() > height {
+		height = right.Height()
+	}
+	return &augmentedMapNode[K, V, A]{
+		height:    height + 1,
+		len:       1 + left.Len() + right.Len(),
+		left:      left,
+		right:     right,
+		key:       key,
+		value:     value,
+		aggregate: mo.combine(mo.combine(mo.aggregate(left), mo.measure(key, value)), mo.aggregate(right)),
+	}
+}
+
+// balance returns a node with the given contents, whose subtrees' heights may differ by at most
+// two, rotating if necessary to restore balance.
+func (mo *augmentedMapMonoid[K, V, A]) balance(key K, value V, left, right *augmentedMapNode[K, V, A]) *augmentedMapNode[K, V, A] {
+	if left.Height() > right.Height()+1 {
+		if left.left.Height() >= left.right.Height() {
+			return mo.node(left.key, left.value, left.left, mo.node(key, value, left.right, right))
+		}
+		return mo.node(
+			left.right.key, left.right.value,
+			mo.node(left.key, left.value, left.left, left.right.left),
+			mo.node(key, value, left.right.right, right),
+		)
+	} else if right.Height() > left.Height()+1 {
+		if right.right.Height() >= right.left.Height() {
+			return mo.node(right.key, right.value, mo.node(key, value, left, right.left), right.right)
+		}
+		return mo.node(
+			right.left.key, right.left.value,
+			mo.node(key, value, left, right.left.left),
+			mo.node(right.key, right.value, right.left.right, right.right),
+		)
+	}
+	return mo.node(key, value, left, right)
+}
+
+// Empty returns true if the map is empty.
+//
+// Complexity: O(1) worst-case
+func (m *AugmentedMap[K, V, A]) Empty() bool {
+	return m.rootNode() == nil
+}
+
+// Len returns the number of elements in the map.
+//
+// Complexity: O(1) worst-case
+func (m *AugmentedMap[K, V, A]) Len() int {
+	return m.rootNode().Len()
+}
+
+// Get returns the value associated with the given key if set.
+//
+// Complexity: O(log n) worst-case
+func (m *AugmentedMap[K, V, A]) Get(key K) (V, bool) {
+	for n := m.rootNode(); n != nil && key == key; {
+		if key < n.key {
+			n = n.left
+		} else if n.key < key {
+			n = n.right
+		} else {
+			return n.value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Set associates a value with the given key. It panics if the key is NaN, or if the map wasn't
+// created with NewAugmentedMap.
+//
+// Complexity: O(log n) worst-case
+func (m *AugmentedMap[K, V, A]) Set(key K, value V) *AugmentedMap[K, V, A] {
+	if m == nil || m.monoid == nil {
+		panic("immutable: AugmentedMap must be created with NewAugmentedMap")
+	}
+	orderedMapCheckKey(key)
+	return m.withRoot(m.monoid.insert(m.root, key, value))
+}
+
+func (mo *augmentedMapMonoid[K, V, A]) insert(n *augmentedMapNode[K, V, A], key K, value V) *augmentedMapNode[K, V, A] {
+	if n == nil {
+		return mo.node(key, value, nil, nil)
+	} else if key < n.key {
+		return mo.balance(n.key, n.value, mo.insert(n.left, key, value), n.right)
+	} else if n.key < key {
+		return mo.balance(n.key, n.value, n.left, mo.insert(n.right, key, value))
+	}
+	return mo.node(key, value, n.left, n.right)
+}
+
+// Delete removes a key from the map.
+//
+// Complexity: O(log n) worst-case
+func (m *AugmentedMap[K, V, A]) Delete(key K) *AugmentedMap[K, V, A] {
+	if _, ok := m.Get(key); !ok {
+		return m
+	}
+	return m.withRoot(m.monoid.delete(m.root, key))
+}
+
+// delete removes the given key, which must be present in the subtree.
+func (mo *augmentedMapMonoid[K, V, A]) delete(n *augmentedMapNode[K, V, A], key K) *augmentedMapNode[K, V, A] {
+	if key < n.key {
+		return mo.balance(n.key, n.value, mo.delete(n.left, key), n.right)
+	} else if n.key < key {
+		return mo.balance(n.key, n.value, n.left, mo.delete(n.right, key))
+	} else if n.left == nil {
+		return n.right
+	} else if n.right == nil {
+		return n.left
+	}
+	successor := n.right
+	for successor.left != nil {
+		successor = successor.left
+	}
+	return mo.balance(successor.key, successor.value, n.left, mo.delete(n.right, successor.key))
+}
+
+// Aggregate returns the aggregate of every element in the map.
+//
+// Complexity: O(1) worst-case
+func (m *AugmentedMap[K, V, A]) Aggregate() A {
+	if m.Empty() {
+		return m.identity()
+	}
+	return m.monoid.aggregate(m.root)
+}
+
+// AggregateRange returns the aggregate of the elements with keys greater than or equal to lo and
+// less than hi.
+//
+// Complexity: O(log n) worst-case
+func (m *AugmentedMap[K, V, A]) AggregateRange(lo, hi K) A {
+	if m.Empty() {
+		return m.identity()
+	}
+	mo := m.monoid
+	n := m.root
+	for n != nil {
+		if n.key < lo {
+			n = n.right
+		} else if !(n.key < hi) {
+			n = n.left
+		} else {
+			return mo.combine(mo.combine(mo.aggregateAtLeast(n.left, lo), mo.measure(n.key, n.value)), mo.aggregateLess(n.right, hi))
+		}
+	}
+	return mo.identity
+}
+
+// AggregateLess returns the aggregate of the elements with keys less than the given key.
+//
+// Complexity: O(log n) worst-case
+func (m *AugmentedMap[K, V, A]) AggregateLess(key K) A {
+	if m.Empty() {
+		return m.identity()
+	}
+	return m.monoid.aggregateLess(m.root, key)
+}
+
+// aggregateAtLeast returns the aggregate of the elements of the subtree with keys greater than or
+// equal to lo.
+func (mo *augmentedMapMonoid[K, V, A]) aggregateAtLeast(n *augmentedMapNode[K, V, A], lo K) A {
+	ret := mo.identity
+	for n != nil {
+		if n.key < lo {
+			n = n.right
+		} else {
+			ret = mo.combine(mo.combine(mo.measure(n.key, n.value), mo.aggregate(n.right)), ret)
+			n = n.left
+		}
+	}
+	return ret
+}
+
+// aggregateLess returns the aggregate of the elements of the subtree with keys less than hi.
+func (mo *augmentedMapMonoid[K, V, A]) aggregateLess(n *augmentedMapNode[K, V, A], hi K) A {
+	ret := mo.identity
+	for n != nil {
+		if n.key < hi {
+			ret = mo.combine(ret, mo.combine(mo.aggregate(n.left), mo.measure(n.key, n.value)))
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return ret
+}
+
+// Search returns the element with the smallest key for which f returns true when given the aggregate
+// of that element and every element before it. f must be monotonic: once it returns true for some
+// element, it must return true for every element after it. If f never returns true, false is
+// returned. For example, if the aggregate is a sum of non-negative weights, this can find the
+// element at which a running total first exceeds some threshold.
+//
+// Complexity: O(log n) worst-case
+func (m *AugmentedMap[K, V, A]) Search(f func(aggregate A) bool) (K, V, bool) {
+	var key K
+	var value V
+	if m.Empty() {
+		return key, value, false
+	}
+	mo := m.monoid
+	acc := mo.identity
+	for n := m.root; n != nil; {
+		if left := mo.combine(acc, mo.aggregate(n.left)); n.left != nil && f(left) {
+			n = n.left
+		} else if self := mo.combine(left, mo.measure(n.key, n.value)); f(self) {
+			return n.key, n.value, true
+		} else {
+			acc = self
+			n = n.right
+		}
+	}
+	return key, value, false
+}
+
+// ForEach calls f for each element in the map in ascending order of keys, stopping early if f
+// returns false.
+//
+// Complexity: O(n) worst-case
+func (m *AugmentedMap[K, V, A]) ForEach(f func(key K, value V) bool) {
+	m.rootNode().forEach(f)
+}
+
+func (n *augmentedMapNode[K, V, A]) forEach(f func(key K, value V) bool) bool {
+	return n == nil || (n.left.forEach(f) && f(n.key, n.value) && n.right.forEach(f))
+}
+
+// CheckInvariants verifies the structural integrity of the map, returning an error describing the
+// first problem found. It checks that keys are in ascending order, that the tree is AVL balanced,
+// and that cached heights and lengths are correct.
+//
+// Complexity: O(n) worst-case
+func (m *AugmentedMap[K, V, A]) CheckInvariants() error {
+	if m.Empty() {
+		return nil
+	}
+	return m.monoid.checkInvariants(m.root, nil, nil)
+}
+
+func (mo *augmentedMapMonoid[K, V, A]) checkInvariants(n *augmentedMapNode[K, V, A], lo, hi *K) error {
+	if n == nil {
+		return nil
+	} else if (lo != nil && !(*lo < n.key)) || (hi != nil && !(n.key < *hi)) {
+		return fmt.Errorf("key %v is out of order", n.key)
+	} else if err := mo.checkInvariants(n.left, lo, &n.key); err != nil {
+		return err
+	} else if err := mo.checkInvariants(n.right, &n.key, hi); err != nil {
+		return err
+	}
+	if diff := n.left.Height() - n.right.Height(); diff < -1 || diff > 1 {
+		return fmt.Errorf("node with key %v is unbalanced", n.key)
+	} else if expected := mo.node(n.key, n.value, n.left, n.right); n.height != expected.height || n.len != expected.len {
+		return fmt.Errorf("node with key %v has incorrect height or length", n.key)
+	}
+	return nil
+}
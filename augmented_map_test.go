@@ -0,0 +1,144 @@
+package immutable
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSumMap() *AugmentedMap[int, int, int] {
+	return NewAugmentedMap(func(key, value int) int {
+		return value
+	}, func(a, b int) int {
+		return a + b
+	}, 0)
+}
+
+func TestAugmentedMap(t *testing.T) {
+	m := newSumMap()
+	assert.True(t, m.Empty())
+	assert.Equal(t, 0, m.Aggregate())
+	_, _, ok := m.Search(func(sum int) bool { return sum > 0 })
+	assert.False(t, ok)
+
+	for i := 1; i <= 10; i++ {
+		m = m.Set(i, i)
+	}
+	require.NoError(t, m.CheckInvariants())
+	assert.Equal(t, 10, m.Len())
+	assert.Equal(t, 55, m.Aggregate())
+	assert.Equal(t, 2+3+4, m.AggregateRange(2, 5))
+	assert.Equal(t, 0, m.AggregateRange(5, 5))
+	assert.Equal(t, 55, m.AggregateRange(-100, 100))
+	assert.Equal(t, 1+2+3, m.AggregateLess(4))
+	assert.Equal(t, 0, m.AggregateLess(1))
+
+	key, value, ok := m.Search(func(sum int) bool { return sum >= 10 })
+	assert.True(t, ok)
+	assert.Equal(t, 4, key)
+	assert.Equal(t, 4, value)
+	_, _, ok = m.Search(func(sum int) bool { return sum > 55 })
+	assert.False(t, ok)
+
+	m2 := m.Set(5, 100).Delete(1)
+	assert.Equal(t, 55-5+100-1, m2.Aggregate())
+	assert.Equal(t, 55, m.Aggregate())
+	assert.Same(t, m2, m2.Delete(1))
+
+	v, ok := m2.Get(5)
+	assert.True(t, ok)
+	assert.Equal(t, 100, v)
+	_, ok = m2.Get(1)
+	assert.False(t, ok)
+
+	var keys []int
+	m2.ForEach(func(key, value int) bool {
+		keys = append(keys, key)
+		return key < 4
+	})
+	assert.Equal(t, []int{2, 3, 4}, keys)
+
+	f := NewAugmentedMap(func(key float64, value int) int { return value }, func(a, b int) int { return a + b }, 0)
+	assert.Panics(t, func() { f.Set(math.NaN(), 1) })
+	_, ok = f.Set(1, 1).Get(math.NaN())
+	assert.False(t, ok)
+}
+
+func TestAugmentedMap_Zero(t *testing.T) {
+	for _, m := range []*AugmentedMap[int, int, int]{nil, {}} {
+		assert.True(t, m.Empty())
+		assert.Equal(t, 0, m.Len())
+		_, ok := m.Get(1)
+		assert.False(t, ok)
+		assert.Same(t, m, m.Delete(1))
+		assert.Equal(t, 0, m.Aggregate())
+		assert.Equal(t, 0, m.AggregateRange(0, 10))
+		assert.Equal(t, 0, m.AggregateLess(10))
+		_, _, ok = m.Search(func(sum int) bool { return true })
+		assert.False(t, ok)
+		m.ForEach(func(key, value int) bool {
+			t.Fail()
+			return true
+		})
+		assert.NoError(t, m.CheckInvariants())
+		assert.PanicsWithValue(t, "immutable: AugmentedMap must be created with NewAugmentedMap", func() {
+			m.Set(1, 1)
+		})
+	}
+
+	// Empty maps created with NewAugmentedMap have the monoid's identity as their aggregate.
+	m := NewAugmentedMap(func(key, value int) int { return value }, func(a, b int) int {
+		if b < a {
+			return b
+		}
+		return a
+	}, math.MaxInt)
+	assert.Equal(t, math.MaxInt, m.Aggregate())
+	assert.Equal(t, math.MaxInt, m.AggregateRange(0, 10))
+	assert.Equal(t, math.MaxInt, m.AggregateLess(10))
+}
+
+func TestAugmentedMap_NonCommutative(t *testing.T) {
+	m := NewAugmentedMap(func(key int, value string) string {
+		return value
+	}, func(a, b string) string {
+		return a + b
+	}, "")
+	for _, i := range rand.Perm(26) {
+		m = m.Set(i, string(rune('a'+i)))
+	}
+	assert.Equal(t, "abcdefghijklmnopqrstuvwxyz", m.Aggregate())
+	assert.Equal(t, "cdef", m.AggregateRange(2, 6))
+}
+
+func TestAugmentedMap_Fuzz(t *testing.T) {
+	m := newSumMap()
+	ref := map[int]int{}
+	for i := 0; i < 2000; i++ {
+		key := rand.Intn(200)
+		if rand.Intn(3) == 0 {
+			m = m.Delete(key)
+			delete(ref, key)
+		} else {
+			value := rand.Intn(100)
+			m = m.Set(key, value)
+			ref[key] = value
+		}
+		require.NoError(t, m.CheckInvariants(), fmt.Sprintf("i=%v", i))
+		require.Equal(t, len(ref), m.Len())
+
+		lo := rand.Intn(220) - 10
+		hi := lo + rand.Intn(100)
+		expected := 0
+		for k, v := range ref {
+			if k >= lo && k < hi {
+				expected += v
+			}
+		}
+		require.Equal(t, expected, m.AggregateRange(lo, hi))
+	}
+}
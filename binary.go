@@ -0,0 +1,472 @@
+package immutable
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// The binary encoding consists of a version byte, a byte identifying the kind of container, the
+// number of elements as a uvarint, and then each element. Elements of maps are encoded as the key
+// followed by the value. Elements of stacks are encoded from top to bottom, and elements of queues
+// are encoded from front to back.
+//
+// Individual keys and values are encoded according to their kind:
+//
+//   - Types implementing encoding.BinaryMarshaler are encoded as a uvarint length followed by the
+//     marshaled bytes.
+//   - Booleans are encoded as a single byte.
+//   - Signed integers are encoded as varints and unsigned integers are encoded as uvarints.
+//   - Floats are encoded as their IEEE 754 bits in little-endian byte order. Complex numbers are
+//     encoded as their real part followed by their imaginary part.
+//   - Strings and byte slices are encoded as a uvarint length followed by the bytes.
+//
+// Other types are not supported.
+const binaryFormatVersion = 1
+
+const (
+	binaryKindOrderedMap = 'm'
+	binaryKindQueue      = 'q'
+	binaryKindStack      = 's'
+)
+
+var (
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+
+	errBinaryTruncated = errors.New("immutable: binary data is truncated")
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+//
+// Keys and values must be booleans, numbers, strings, byte slices, or implement
+// encoding.BinaryMarshaler.
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMap[K, V]) MarshalBinary() ([]byte, error) {
+	return m.AppendBinary(nil)
+}
+
+// AppendBinary appends the binary encoding of the map to b. See MarshalBinary.
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMap[K, V]) AppendBinary(b []byte) ([]byte, error) {
+	b = appendBinaryHeader(b, binaryKindOrderedMap, m.Len())
+	var err error
+	for e := m.Min(); e != nil; e = e.Next() {
+		if b, err = appendBinaryValue(b, e.Key()); err != nil {
+			return nil, err
+		}
+		if b, err = appendBinaryValue(b, e.Value()); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+//
+// Encoded keys don't need to be in order, and if a key is repeated, its last value is used. To
+// reject such data instead, use UnmarshalBinaryStrict.
+//
+// Because maps are immutable, this must only be used to initialize a new map that has not been
+// shared, such as a zero value.
+//
+// Complexity: O(n log n) worst-case, O(n) if the encoded keys are in ascending order
+func (m *OrderedMap[K, V]) UnmarshalBinary(data []byte) error {
+	return m.unmarshalBinary(data, false)
+}
+
+// UnmarshalBinaryStrict is like UnmarshalBinary, but returns a descriptive error if the data isn't
+// exactly what MarshalBinary would produce for some map. The keys must be encoded in strictly
+// ascending order, so repeated keys are rejected, and each key and value must be encoded exactly as
+// MarshalBinary would encode it, such as without redundant varint bytes, so no two distinct inputs
+// decode to the same map. This is useful when decoding data from
+// untrusted sources, or when the encoding is used to identify the map's contents.
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMap[K, V]) UnmarshalBinaryStrict(data []byte) error {
+	return m.unmarshalBinary(data, true)
+}
+
+func (m *OrderedMap[K, V]) unmarshalBinary(data []byte, strict bool) error {
+	n, data, err := decodeBinaryHeader(data, binaryKindOrderedMap)
+	if err != nil {
+		return err
+	}
+	keys := make([]K, n)
+	values := make([]V, n)
+	for i := range keys {
+		if data, err = decodeBinaryElement(data, &keys[i], strict, "key", i); err != nil {
+			return err
+		} else if keys[i] != keys[i] {
+			return fmt.Errorf("immutable: key at index %v is NaN", i)
+		}
+		if data, err = decodeBinaryElement(data, &values[i], strict, "value", i); err != nil {
+			return err
+		}
+	}
+	if len(data) > 0 {
+		return fmt.Errorf("immutable: %v bytes of trailing data", len(data))
+	}
+	for i := 1; i < len(keys); i++ {
+		if !(keys[i-1] < keys[i]) {
+			if strict {
+				return fmt.Errorf("immutable: key %v at index %v is not greater than the preceding key %v", keys[i], i, keys[i-1])
+			}
+			keys, values = orderedMapBuilderSort(keys, values)
+			break
+		}
+	}
+	decoded := orderedMapFromSorted(keys, values, false)
+	*m = OrderedMap[K, V]{}
+	if decoded != nil {
+		*m = *decoded
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+//
+// Items must be booleans, numbers, strings, byte slices, or implement encoding.BinaryMarshaler.
+//
+// Complexity: O(n) worst-case
+func (s *Stack[T]) MarshalBinary() ([]byte, error) {
+	return s.AppendBinary(nil)
+}
+
+// AppendBinary appends the binary encoding of the stack to b. See MarshalBinary.
+//
+// Complexity: O(n) worst-case
+func (s *Stack[T]) AppendBinary(b []byte) ([]byte, error) {
+	return appendBinaryItems(b, binaryKindStack, s.ToSlice())
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+//
+// Because stacks are immutable, this must only be used to initialize a new stack that has not been
+// shared, such as a zero value.
+//
+// Complexity: O(n) worst-case
+func (s *Stack[T]) UnmarshalBinary(data []byte) error {
+	return s.unmarshalBinary(data, false)
+}
+
+// UnmarshalBinaryStrict is like UnmarshalBinary, but returns a descriptive error if the data isn't
+// exactly what MarshalBinary would produce for some stack, such as if an item is encoded with
+// redundant varint bytes.
+//
+// Complexity: O(n) worst-case
+func (s *Stack[T]) UnmarshalBinaryStrict(data []byte) error {
+	return s.unmarshalBinary(data, true)
+}
+
+func (s *Stack[T]) unmarshalBinary(data []byte, strict bool) error {
+	items, err := decodeBinaryItems[T](data, binaryKindStack, strict)
+	if err != nil {
+		return err
+	}
+	*s = Stack[T]{}
+	if decoded := StackFromSlice(items); decoded != nil {
+		*s = *decoded
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+//
+// Items must be booleans, numbers, strings, byte slices, or implement encoding.BinaryMarshaler.
+//
+// Complexity: O(n) worst-case
+func (q *Queue[T]) MarshalBinary() ([]byte, error) {
+	return q.AppendBinary(nil)
+}
+
+// AppendBinary appends the binary encoding of the queue to b. See MarshalBinary.
+//
+// Complexity: O(n) worst-case
+func (q *Queue[T]) AppendBinary(b []byte) ([]byte, error) {
+	return appendBinaryItems(b, binaryKindQueue, q.ToSlice())
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+//
+// Because queues are immutable, this must only be used to initialize a new queue that has not been
+// shared, such as a zero value.
+//
+// Complexity: O(n) worst-case
+func (q *Queue[T]) UnmarshalBinary(data []byte) error {
+	return q.unmarshalBinary(data, false)
+}
+
+// UnmarshalBinaryStrict is like UnmarshalBinary, but returns a descriptive error if the data isn't
+// exactly what MarshalBinary would produce for some queue, such as if an item is encoded with
+// redundant varint bytes.
+//
+// Complexity: O(n) worst-case
+func (q *Queue[T]) UnmarshalBinaryStrict(data []byte) error {
+	return q.unmarshalBinary(data, true)
+}
+
+func (q *Queue[T]) unmarshalBinary(data []byte, strict bool) error {
+	items, err := decodeBinaryItems[T](data, binaryKindQueue, strict)
+	if err != nil {
+		return err
+	}
+	*q = *QueueFromSlice(items)
+	return nil
+}
+
+func appendBinaryHeader(b []byte, kind byte, n int) []byte {
+	b = append(b, binaryFormatVersion, kind)
+	return appendUvarint(b, uint64(n))
+}
+
+func decodeBinaryHeader(data []byte, kind byte) (int, []byte, error) {
+	if len(data) < 2 {
+		return 0, nil, errBinaryTruncated
+	} else if data[0] != binaryFormatVersion {
+		return 0, nil, fmt.Errorf("immutable: unsupported binary format version %v", data[0])
+	} else if data[1] != kind {
+		return 0, nil, fmt.Errorf("immutable: binary data is for the wrong kind of container: %q", data[1])
+	}
+	n, data, err := decodeUvarint(data[2:])
+	if err != nil {
+		return 0, nil, err
+	} else if n > uint64(len(data)) {
+		// Every element occupies at least one byte.
+		return 0, nil, errBinaryTruncated
+	}
+	return int(n), data, nil
+}
+
+func appendBinaryItems[T any](b []byte, kind byte, items []T) ([]byte, error) {
+	b = appendBinaryHeader(b, kind, len(items))
+	var err error
+	for _, item := range items {
+		if b, err = appendBinaryValue(b, item); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func decodeBinaryItems[T any](data []byte, kind byte, strict bool) ([]T, error) {
+	n, data, err := decodeBinaryHeader(data, kind)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]T, n)
+	for i := range items {
+		if data, err = decodeBinaryElement(data, &items[i], strict, "item", i); err != nil {
+			return nil, err
+		}
+	}
+	if len(data) > 0 {
+		return nil, fmt.Errorf("immutable: %v bytes of trailing data", len(data))
+	}
+	return items, nil
+}
+
+// decodeBinaryElement decodes a value like decodeBinaryValue. If strict is true, it also returns an
+// error if the value isn't encoded exactly as appendBinaryValue would encode it. Values which decode
+// equally but are encoded differently, such as integers with redundant varint bytes, would
+// otherwise go unnoticed. what and i describe the value in the error.
+func decodeBinaryElement(data []byte, ptr any, strict bool, what string, i int) ([]byte, error) {
+	rest, err := decodeBinaryValue(data, ptr)
+	if err != nil || !strict {
+		return rest, err
+	}
+	value := reflect.ValueOf(ptr).Elem().Interface()
+	if canonical, err := appendBinaryValue(nil, value); err != nil {
+		return nil, err
+	} else if string(canonical) != string(data[:len(data)-len(rest)]) {
+		return nil, fmt.Errorf("immutable: %v %v at index %v is not canonically encoded", what, value, i)
+	}
+	return rest, nil
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func appendVarint(b []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutVarint(buf[:], v)]...)
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+func decodeUvarint(data []byte) (uint64, []byte, error) {
+	v, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, nil, errors.New("immutable: invalid uvarint in binary data")
+	}
+	return v, data[n:], nil
+}
+
+func decodeVarint(data []byte) (int64, []byte, error) {
+	v, n := binary.Varint(data)
+	if n <= 0 {
+		return 0, nil, errors.New("immutable: invalid varint in binary data")
+	}
+	return v, data[n:], nil
+}
+
+func decodeBinaryBytes(data []byte) ([]byte, []byte, error) {
+	n, data, err := decodeUvarint(data)
+	if err != nil {
+		return nil, nil, err
+	} else if n > uint64(len(data)) {
+		return nil, nil, errBinaryTruncated
+	}
+	return data[:n], data[n:], nil
+}
+
+func appendBinaryValue(b []byte, value any) ([]byte, error) {
+	if m, ok := value.(encoding.BinaryMarshaler); ok {
+		buf, err := m.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		return append(appendUvarint(b, uint64(len(buf))), buf...), nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(b, 1), nil
+		}
+		return append(b, 0), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendVarint(b, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendUvarint(b, v.Uint()), nil
+	case reflect.Float32:
+		return appendUint32(b, math.Float32bits(float32(v.Float()))), nil
+	case reflect.Float64:
+		return appendUint64(b, math.Float64bits(v.Float())), nil
+	case reflect.Complex64:
+		c := v.Complex()
+		b = appendUint32(b, math.Float32bits(float32(real(c))))
+		return appendUint32(b, math.Float32bits(float32(imag(c)))), nil
+	case reflect.Complex128:
+		c := v.Complex()
+		b = appendUint64(b, math.Float64bits(real(c)))
+		return appendUint64(b, math.Float64bits(imag(c))), nil
+	case reflect.String:
+		return append(appendUvarint(b, uint64(v.Len())), v.String()...), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return append(appendUvarint(b, uint64(v.Len())), v.Bytes()...), nil
+		}
+	}
+	return nil, fmt.Errorf("immutable: unsupported type for binary encoding: %T", value)
+}
+
+// decodeBinaryValue decodes a value into the given pointer and returns the remaining data.
+func decodeBinaryValue(data []byte, ptr any) ([]byte, error) {
+	v := reflect.ValueOf(ptr).Elem()
+
+	if u, ok := ptr.(encoding.BinaryUnmarshaler); ok {
+		buf, rest, err := decodeBinaryBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		return rest, u.UnmarshalBinary(buf)
+	} else if v.Kind() == reflect.Pointer && v.Type().Implements(binaryUnmarshalerType) {
+		buf, rest, err := decodeBinaryBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		return rest, v.Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(buf)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if len(data) < 1 {
+			return nil, errBinaryTruncated
+		} else if data[0] > 1 {
+			return nil, fmt.Errorf("immutable: invalid boolean in binary data: %v", data[0])
+		}
+		v.SetBool(data[0] == 1)
+		return data[1:], nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, rest, err := decodeVarint(data)
+		if err != nil {
+			return nil, err
+		} else if v.OverflowInt(n) {
+			return nil, fmt.Errorf("immutable: %v overflows %v", n, v.Type())
+		}
+		v.SetInt(n)
+		return rest, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, rest, err := decodeUvarint(data)
+		if err != nil {
+			return nil, err
+		} else if v.OverflowUint(n) {
+			return nil, fmt.Errorf("immutable: %v overflows %v", n, v.Type())
+		}
+		v.SetUint(n)
+		return rest, nil
+	case reflect.Float32:
+		if len(data) < 4 {
+			return nil, errBinaryTruncated
+		}
+		v.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(data))))
+		return data[4:], nil
+	case reflect.Float64:
+		if len(data) < 8 {
+			return nil, errBinaryTruncated
+		}
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(data)))
+		return data[8:], nil
+	case reflect.Complex64:
+		if len(data) < 8 {
+			return nil, errBinaryTruncated
+		}
+		re := math.Float32frombits(binary.LittleEndian.Uint32(data))
+		im := math.Float32frombits(binary.LittleEndian.Uint32(data[4:]))
+		v.SetComplex(complex(float64(re), float64(im)))
+		return data[8:], nil
+	case reflect.Complex128:
+		if len(data) < 16 {
+			return nil, errBinaryTruncated
+		}
+		re := math.Float64frombits(binary.LittleEndian.Uint64(data))
+		im := math.Float64frombits(binary.LittleEndian.Uint64(data[8:]))
+		v.SetComplex(complex(re, im))
+		return data[16:], nil
+	case reflect.String:
+		buf, rest, err := decodeBinaryBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		v.SetString(string(buf))
+		return rest, nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			buf, rest, err := decodeBinaryBytes(data)
+			if err != nil {
+				return nil, err
+			}
+			v.SetBytes(append([]byte{}, buf...))
+			return rest, nil
+		}
+	}
+	return nil, fmt.Errorf("immutable: unsupported type for binary decoding: %v", v.Type())
+}
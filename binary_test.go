@@ -0,0 +1,195 @@
+package immutable
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedMap_Binary(t *testing.T) {
+	var m *OrderedMap[string, int]
+	buf, err := m.MarshalBinary()
+	require.NoError(t, err)
+	var decoded OrderedMap[string, int]
+	require.NoError(t, decoded.UnmarshalBinary(buf))
+	assert.True(t, decoded.Empty())
+
+	for i := 0; i < 100; i++ {
+		m = m.Set(string(rune('a'+i%26))+string(rune('a'+i/26)), i-50)
+	}
+	buf, err = m.MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, decoded.UnmarshalBinary(buf))
+	require.NoError(t, decoded.CheckInvariants())
+	assert.Equal(t, m.ToMap(), decoded.ToMap())
+
+	buf, err = m.AppendBinary([]byte("prefix"))
+	require.NoError(t, err)
+	assert.Equal(t, "prefix", string(buf[:6]))
+
+	// Trailing, truncated, and mismatched data should be rejected.
+	buf, err = m.MarshalBinary()
+	require.NoError(t, err)
+	assert.Error(t, decoded.UnmarshalBinary(append(buf, 0)))
+	assert.Error(t, decoded.UnmarshalBinary(buf[:len(buf)-1]))
+	assert.Error(t, decoded.UnmarshalBinary(nil))
+	var wrongType OrderedMap[int, int]
+	assert.Error(t, wrongType.UnmarshalBinary(buf))
+	var wrongKind Stack[string]
+	assert.Error(t, wrongKind.UnmarshalBinary(buf))
+}
+
+func TestOrderedMap_UnmarshalBinaryStrict(t *testing.T) {
+	encode := func(keys ...float64) []byte {
+		buf := appendBinaryHeader(nil, binaryKindOrderedMap, len(keys))
+		for i, k := range keys {
+			buf, _ = appendBinaryValue(buf, k)
+			buf, _ = appendBinaryValue(buf, i)
+		}
+		return buf
+	}
+
+	var decoded OrderedMap[float64, int]
+	require.NoError(t, decoded.UnmarshalBinaryStrict(encode(1, 2, 3)))
+	assert.Equal(t, map[float64]int{1: 0, 2: 1, 3: 2}, decoded.ToMap())
+
+	var empty OrderedMap[float64, int]
+	require.NoError(t, empty.UnmarshalBinaryStrict(encode()))
+	assert.True(t, empty.Empty())
+
+	for _, keys := range [][]float64{{2, 1}, {1, 1}} {
+		var lenient OrderedMap[float64, int]
+		assert.NoError(t, lenient.UnmarshalBinary(encode(keys...)))
+
+		var strict OrderedMap[float64, int]
+		err := strict.UnmarshalBinaryStrict(encode(keys...))
+		assert.ErrorContains(t, err, "is not greater than the preceding key")
+		assert.True(t, strict.Empty())
+	}
+
+	var nan OrderedMap[float64, int]
+	assert.ErrorContains(t, nan.UnmarshalBinary(encode(math.NaN())), "NaN")
+	assert.ErrorContains(t, nan.UnmarshalBinaryStrict(encode(1, math.NaN())), "NaN")
+
+	buf := encode(1, 2)
+	assert.ErrorContains(t, decoded.UnmarshalBinaryStrict(append(buf, 0)), "trailing data")
+	assert.Error(t, decoded.UnmarshalBinaryStrict(buf[:len(buf)-1]))
+
+	// The integer key 1 is encoded as the varint 0x02, which can also be padded with a redundant
+	// continuation byte.
+	header := appendBinaryHeader(nil, binaryKindOrderedMap, 2)
+	canonical := append(append([]byte{}, header...), 0x00, 0x00, 0x02, 0x02)
+	overlong := append(append([]byte{}, header...), 0x00, 0x00, 0x82, 0x00, 0x02)
+	var lenient OrderedMap[int, int]
+	require.NoError(t, lenient.UnmarshalBinary(overlong))
+	assert.Equal(t, map[int]int{0: 0, 1: 1}, lenient.ToMap())
+	var strict OrderedMap[int, int]
+	assert.ErrorContains(t, strict.UnmarshalBinaryStrict(overlong), "not canonically encoded")
+	require.NoError(t, strict.UnmarshalBinaryStrict(canonical))
+	assert.Equal(t, map[int]int{0: 0, 1: 1}, strict.ToMap())
+
+	// The same goes for values.
+	overlong = append(append([]byte{}, header...), 0x00, 0x00, 0x02, 0x82, 0x00)
+	require.NoError(t, lenient.UnmarshalBinary(overlong))
+	assert.Equal(t, map[int]int{0: 0, 1: 1}, lenient.ToMap())
+	assert.ErrorContains(t, strict.UnmarshalBinaryStrict(overlong), "value 1 at index 1 is not canonically encoded")
+}
+
+func TestOrderedMap_BinaryTypes(t *testing.T) {
+	type myString string
+	type unsupportedValue struct {
+		X int
+	}
+	var m *OrderedMap[float64, bool]
+	m = m.Set(math.Inf(-1), true).Set(1.5, false).Set(-0.25, true)
+	binaryRoundTrip(t, m, func(decoded *OrderedMap[float64, bool]) {
+		assert.Equal(t, m.ToMap(), decoded.ToMap())
+	})
+
+	var nested *OrderedMap[myString, *OrderedMap[int, []byte]]
+	nested = nested.Set("foo", (*OrderedMap[int, []byte])(nil).Set(1, []byte("bar")))
+	binaryRoundTrip(t, nested, func(decoded *OrderedMap[myString, *OrderedMap[int, []byte]]) {
+		v, ok := decoded.Get("foo")
+		require.True(t, ok)
+		assert.Equal(t, map[int][]byte{1: []byte("bar")}, v.ToMap())
+	})
+
+	var times *OrderedMap[int8, time.Time]
+	now := time.Unix(1000, 0).UTC()
+	times = times.Set(-1, now)
+	binaryRoundTrip(t, times, func(decoded *OrderedMap[int8, time.Time]) {
+		v, _ := decoded.Get(-1)
+		assert.True(t, now.Equal(v))
+	})
+
+	var unsupported *OrderedMap[int, unsupportedValue]
+	_, err := unsupported.Set(1, unsupportedValue{}).MarshalBinary()
+	assert.Error(t, err)
+}
+
+func binaryRoundTrip[K ~int8 | ~float64 | ~string, V any](t *testing.T, m *OrderedMap[K, V], check func(decoded *OrderedMap[K, V])) {
+	buf, err := m.MarshalBinary()
+	require.NoError(t, err)
+	var decoded OrderedMap[K, V]
+	require.NoError(t, decoded.UnmarshalBinary(buf))
+	check(&decoded)
+	var strict OrderedMap[K, V]
+	require.NoError(t, strict.UnmarshalBinaryStrict(buf))
+	check(&strict)
+}
+
+func TestStack_Binary(t *testing.T) {
+	s := StackFromSlice([]uint16{1, 2, 3})
+	buf, err := s.MarshalBinary()
+	require.NoError(t, err)
+	var decoded Stack[uint16]
+	require.NoError(t, decoded.UnmarshalBinary(buf))
+	assert.Equal(t, []uint16{1, 2, 3}, decoded.ToSlice())
+
+	buf, err = (*Stack[uint16])(nil).MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, decoded.UnmarshalBinary(buf))
+	assert.True(t, decoded.Empty())
+
+	var overflow Stack[uint8]
+	buf, err = StackFromSlice([]uint16{1000}).MarshalBinary()
+	require.NoError(t, err)
+	assert.Error(t, overflow.UnmarshalBinary(buf))
+
+	// The item 1 is encoded as the uvarint 0x01, which can also be padded with a redundant
+	// continuation byte.
+	overlong := append(appendBinaryHeader(nil, binaryKindStack, 2), 0x02, 0x81, 0x00)
+	require.NoError(t, decoded.UnmarshalBinary(overlong))
+	assert.Equal(t, []uint16{2, 1}, decoded.ToSlice())
+	var strict Stack[uint16]
+	assert.ErrorContains(t, strict.UnmarshalBinaryStrict(overlong), "item 1 at index 1 is not canonically encoded")
+	buf, err = s.MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, strict.UnmarshalBinaryStrict(buf))
+	assert.Equal(t, []uint16{1, 2, 3}, strict.ToSlice())
+}
+
+func TestQueue_Binary(t *testing.T) {
+	q := QueueFromSlice([]string{"a", "b", "c"})
+	buf, err := q.MarshalBinary()
+	require.NoError(t, err)
+	var decoded Queue[string]
+	require.NoError(t, decoded.UnmarshalBinary(buf))
+	assert.Equal(t, []string{"a", "b", "c"}, decoded.ToSlice())
+	assert.Equal(t, []string{"b", "c", "d"}, decoded.PopFront().PushBack("d").ToSlice())
+
+	var strict Queue[string]
+	require.NoError(t, strict.UnmarshalBinaryStrict(buf))
+	assert.Equal(t, []string{"a", "b", "c"}, strict.ToSlice())
+	assert.ErrorContains(t, strict.UnmarshalBinaryStrict(append(buf, 0)), "trailing data")
+
+	// The string "a" has its length encoded as the uvarint 0x01, which can also be padded with a
+	// redundant continuation byte.
+	overlong := append(appendBinaryHeader(nil, binaryKindQueue, 1), 0x81, 0x00, 'a')
+	require.NoError(t, decoded.UnmarshalBinary(overlong))
+	assert.Equal(t, []string{"a"}, decoded.ToSlice())
+	assert.ErrorContains(t, strict.UnmarshalBinaryStrict(overlong), "not canonically encoded")
+}
@@ -0,0 +1,395 @@
+package immutable
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+const (
+	bitsetShift     = 5
+	bitsetBranching = 1 << bitsetShift
+
+	bitsetLeafWords = 32
+	bitsetLeafShift = 11 // log2(bitsetLeafWords * 64)
+)
+
+// Bitset implements a set of non-negative integers.
+//
+// The set is represented as a trie of fixed-size word chunks, so sets that are sparse or clustered
+// use little memory, and unchanged chunks are shared between versions.
+//
+// Nil and the zero value for Bitset are both empty sets.
+type Bitset struct {
+	height int
+	root   *bitsetNode
+}
+
+// bitsetNode is either an interior node with bitsetBranching children or a leaf with
+// bitsetLeafWords words. Nodes with no bits set are always represented by nil.
+type bitsetNode struct {
+	count    int
+	children []*bitsetNode
+	words    []uint64
+}
+
+// Empty returns true if no bits are set.
+//
+// Complexity: O(1) worst-case
+func (b *Bitset) Empty() bool {
+	return b == nil || b.root == nil
+}
+
+// Count returns the number of bits that are set.
+//
+// Complexity: O(1) worst-case
+func (b *Bitset) Count() int {
+	if b.Empty() {
+		return 0
+	}
+	return b.root.count
+}
+
+// Test returns true if the given bit is set.
+//
+// Complexity: O(log n) worst-case
+func (b *Bitset) Test(i int) bool {
+	if b.Empty() || i < 0 || !bitsetCovers(b.height, i) {
+		return false
+	}
+	n := b.root
+	for h := b.height; h > 0 && n != nil; h-- {
+		n = n.children[bitsetChildIndex(h, i)]
+	}
+	return n != nil && n.words[(i&(1<<bitsetLeafShift-1))>>6]&(1<<(i&63)) != 0
+}
+
+// Set sets the given bit. It panics if i is negative.
+//
+// Complexity: O(log n) worst-case
+func (b *Bitset) Set(i int) *Bitset {
+	if i < 0 {
+		panic("immutable: negative Bitset index")
+	}
+	var ret Bitset
+	if b != nil {
+		ret = *b
+	}
+	for !bitsetCovers(ret.height, i) {
+		ret.root = ret.root.lift()
+		ret.height++
+	}
+	root := ret.root.with(ret.height, i, true)
+	if root == ret.root && b != nil {
+		return b
+	}
+	ret.root = root
+	return &ret
+}
+
+// Clear clears the given bit.
+//
+// Complexity: O(log n) worst-case
+func (b *Bitset) Clear(i int) *Bitset {
+	if !b.Test(i) {
+		return b
+	}
+	return newBitset(b.height, b.root.with(b.height, i, false))
+}
+
+// And returns the intersection of the two sets.
+//
+// Complexity: O(n + m) worst-case, but subtrees shared by the two sets are not traversed
+func (b *Bitset) And(other *Bitset) *Bitset {
+	return bitsetCombine(b, other, func(x, y *bitsetNode) (*bitsetNode, bool) {
+		if x == nil || y == nil {
+			return nil, true
+		} else if x == y {
+			return x, true
+		}
+		return nil, false
+	}, func(x, y uint64) uint64 {
+		return x & y
+	})
+}
+
+// Or returns the union of the two sets.
+//
+// Complexity: O(n + m) worst-case, but subtrees shared by the two sets are not traversed
+func (b *Bitset) Or(other *Bitset) *Bitset {
+	return bitsetCombine(b, other, func(x, y *bitsetNode) (*bitsetNode, bool) {
+		if x == nil {
+			return y, true
+		} else if y == nil || x == y {
+			return x, true
+		}
+		return nil, false
+	}, func(x, y uint64) uint64 {
+		return x | y
+	})
+}
+
+// Xor returns the symmetric difference of the two sets.
+//
+// Complexity: O(n + m) worst-case, but subtrees shared by the two sets are not traversed
+func (b *Bitset) Xor(other *Bitset) *Bitset {
+	return bitsetCombine(b, other, func(x, y *bitsetNode) (*bitsetNode, bool) {
+		if x == nil {
+			return y, true
+		} else if y == nil {
+			return x, true
+		} else if x == y {
+			return nil, true
+		}
+		return nil, false
+	}, func(x, y uint64) uint64 {
+		return x ^ y
+	})
+}
+
+// NextSet returns the smallest set bit that is greater than or equal to i. The set bits can be
+// iterated over like so:
+//
+//	for i, ok := b.NextSet(0); ok; i, ok = b.NextSet(i + 1) {
+//		...
+//	}
+//
+// Complexity: O(log n) worst-case, amortized O(1) if iterating over a dense set
+func (b *Bitset) NextSet(i int) (int, bool) {
+	if b.Empty() {
+		return 0, false
+	} else if i < 0 {
+		i = 0
+	} else if !bitsetCovers(b.height, i) {
+		return 0, false
+	}
+	return b.root.nextSet(b.height, i)
+}
+
+// CheckInvariants verifies the structural integrity of the set, returning an error describing the
+// first problem found. It checks the shape of every leaf and interior node, that no node is empty,
+// and that every node's cached count of set bits is correct.
+//
+// Complexity: O(n) worst-case
+func (b *Bitset) CheckInvariants() error {
+	if b.Empty() {
+		return nil
+	} else if b.height < 0 {
+		return fmt.Errorf("invalid height %v", b.height)
+	}
+	return b.root.checkInvariants(b.height)
+}
+
+func (n *bitsetNode) checkInvariants(height int) error {
+	count := 0
+	if height == 0 {
+		if len(n.words) != bitsetLeafWords || n.children != nil {
+			return fmt.Errorf("invalid leaf")
+		}
+		for _, w := range n.words {
+			count += bits.OnesCount64(w)
+		}
+	} else {
+		if len(n.children) != bitsetBranching || n.words != nil {
+			return fmt.Errorf("invalid interior node")
+		}
+		for _, c := range n.children {
+			if c == nil {
+				continue
+			} else if err := c.checkInvariants(height - 1); err != nil {
+				return err
+			}
+			count += c.count
+		}
+	}
+	if count == 0 {
+		return fmt.Errorf("node has no bits set")
+	} else if count != n.count {
+		return fmt.Errorf("node has %v bits set, but %v were recorded", count, n.count)
+	}
+	return nil
+}
+
+func newBitset(height int, root *bitsetNode) *Bitset {
+	// Remove any levels that are no longer needed.
+	for height > 0 && (root == nil || root.children[0] != nil && root.children[0].count == root.count) {
+		if root != nil {
+			root = root.children[0]
+		}
+		height--
+	}
+	if root == nil {
+		return nil
+	}
+	return &Bitset{
+		height: height,
+		root:   root,
+	}
+}
+
+func bitsetCombine(a, b *Bitset, shortcut func(x, y *bitsetNode) (*bitsetNode, bool), op func(x, y uint64) uint64) *Bitset {
+	var x, y Bitset
+	if a != nil {
+		x = *a
+	}
+	if b != nil {
+		y = *b
+	}
+	for x.height < y.height {
+		x.root = x.root.lift()
+		x.height++
+	}
+	for y.height < x.height {
+		y.root = y.root.lift()
+		y.height++
+	}
+	return newBitset(x.height, bitsetCombineNodes(x.root, y.root, x.height, shortcut, op))
+}
+
+func bitsetCombineNodes(x, y *bitsetNode, height int, shortcut func(x, y *bitsetNode) (*bitsetNode, bool), op func(x, y uint64) uint64) *bitsetNode {
+	if n, ok := shortcut(x, y); ok {
+		return n
+	}
+	ret := &bitsetNode{}
+	if height == 0 {
+		ret.words = make([]uint64, bitsetLeafWords)
+		for i := range ret.words {
+			ret.words[i] = op(x.words[i], y.words[i])
+			ret.count += bits.OnesCount64(ret.words[i])
+		}
+	} else {
+		ret.children = make([]*bitsetNode, bitsetBranching)
+		for i := range ret.children {
+			var xc, yc *bitsetNode
+			if x != nil {
+				xc = x.children[i]
+			}
+			if y != nil {
+				yc = y.children[i]
+			}
+			if c := bitsetCombineNodes(xc, yc, height-1, shortcut, op); c != nil {
+				ret.children[i] = c
+				ret.count += c.count
+			}
+		}
+	}
+	if ret.count == 0 {
+		return nil
+	}
+	return ret
+}
+
+// bitsetCovers returns true if a trie of the given height can hold bit i.
+func bitsetCovers(height, i int) bool {
+	shift := bitsetLeafShift + bitsetShift*height
+	return shift >= bits.UintSize-1 || i>>shift == 0
+}
+
+func bitsetChildIndex(height, i int) int {
+	return (i >> (bitsetLeafShift + bitsetShift*(height-1))) & (bitsetBranching - 1)
+}
+
+// lift returns a node one level higher which contains n as its first child.
+func (n *bitsetNode) lift() *bitsetNode {
+	if n == nil {
+		return nil
+	}
+	ret := &bitsetNode{
+		count:    n.count,
+		children: make([]*bitsetNode, bitsetBranching),
+	}
+	ret.children[0] = n
+	return ret
+}
+
+// with returns a copy of the node with bit i set to the given value.
+func (n *bitsetNode) with(height, i int, value bool) *bitsetNode {
+	var count int
+	if n != nil {
+		count = n.count
+	}
+
+	if height == 0 {
+		w, mask := (i&(1<<bitsetLeafShift-1))>>6, uint64(1)<<(i&63)
+		if n != nil && (n.words[w]&mask != 0) == value {
+			return n
+		} else if n == nil && !value {
+			return nil
+		}
+		words := make([]uint64, bitsetLeafWords)
+		if n != nil {
+			copy(words, n.words)
+		}
+		if value {
+			words[w] |= mask
+			count++
+		} else {
+			words[w] &^= mask
+			count--
+		}
+		if count == 0 {
+			return nil
+		}
+		return &bitsetNode{
+			count: count,
+			words: words,
+		}
+	}
+
+	idx := bitsetChildIndex(height, i)
+	var old *bitsetNode
+	if n != nil {
+		old = n.children[idx]
+	}
+	child := old.with(height-1, i, value)
+	if child == old {
+		return n
+	}
+	if old != nil {
+		count -= old.count
+	}
+	if child != nil {
+		count += child.count
+	}
+	if count == 0 {
+		return nil
+	}
+	children := make([]*bitsetNode, bitsetBranching)
+	if n != nil {
+		copy(children, n.children)
+	}
+	children[idx] = child
+	return &bitsetNode{
+		count:    count,
+		children: children,
+	}
+}
+
+func (n *bitsetNode) nextSet(height, i int) (int, bool) {
+	if height == 0 {
+		for w := i >> 6; w < bitsetLeafWords; w++ {
+			word := n.words[w]
+			if w == i>>6 {
+				word &= ^uint64(0) << (i & 63)
+			}
+			if word != 0 {
+				return w<<6 + bits.TrailingZeros64(word), true
+			}
+		}
+		return 0, false
+	}
+	shift := bitsetLeafShift + bitsetShift*(height-1)
+	for c := i >> shift; c < bitsetBranching; c++ {
+		child := n.children[c]
+		if child == nil {
+			continue
+		}
+		start := 0
+		if c == i>>shift {
+			start = i & (1<<shift - 1)
+		}
+		if r, ok := child.nextSet(height-1, start); ok {
+			return c<<shift + r, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,98 @@
+package immutable
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitset(t *testing.T) {
+	var b *Bitset
+	assert.True(t, b.Empty())
+	assert.Equal(t, 0, b.Count())
+	assert.False(t, b.Test(0))
+	_, ok := b.NextSet(0)
+	assert.False(t, ok)
+
+	b2 := b.Set(3).Set(100000).Set(64)
+	assert.True(t, b.Empty())
+	assert.False(t, b2.Empty())
+	assert.Equal(t, 3, b2.Count())
+	assert.True(t, b2.Test(3))
+	assert.True(t, b2.Test(64))
+	assert.True(t, b2.Test(100000))
+	assert.False(t, b2.Test(4))
+	assert.False(t, b2.Test(-1))
+	assert.False(t, b2.Test(1<<40))
+	assert.Same(t, b2, b2.Set(3))
+
+	var set []int
+	for i, ok := b2.NextSet(0); ok; i, ok = b2.NextSet(i + 1) {
+		set = append(set, i)
+	}
+	assert.Equal(t, []int{3, 64, 100000}, set)
+
+	b3 := b2.Clear(100000)
+	assert.Equal(t, 2, b3.Count())
+	assert.False(t, b3.Test(100000))
+	assert.Equal(t, 0, b3.height)
+	assert.NoError(t, b3.CheckInvariants())
+	assert.True(t, b3.Clear(3).Clear(64).Empty())
+
+	assert.Panics(t, func() {
+		b.Set(-1)
+	})
+}
+
+func TestBitset_Operations(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		var a, b *Bitset
+		refA, refB := map[int]bool{}, map[int]bool{}
+		for j := 0; j < 200; j++ {
+			n := rand.Intn(1 << (rand.Intn(5) * 5))
+			if rand.Intn(2) == 0 {
+				a = a.Set(n)
+				refA[n] = true
+			} else {
+				b = b.Set(n)
+				refB[n] = true
+			}
+		}
+		if i%10 == 0 {
+			b = a
+			refB = refA
+		}
+
+		and, or, xor := a.And(b), a.Or(b), a.Xor(b)
+		refAnd, refOr, refXor := map[int]bool{}, map[int]bool{}, map[int]bool{}
+		for n := range refA {
+			refOr[n] = true
+			if refB[n] {
+				refAnd[n] = true
+			} else {
+				refXor[n] = true
+			}
+		}
+		for n := range refB {
+			refOr[n] = true
+			if !refA[n] {
+				refXor[n] = true
+			}
+		}
+		for _, tc := range []struct {
+			b   *Bitset
+			ref map[int]bool
+		}{{a, refA}, {b, refB}, {and, refAnd}, {or, refOr}, {xor, refXor}} {
+			require.NoError(t, tc.b.CheckInvariants())
+			require.Equal(t, len(tc.ref), tc.b.Count())
+			actual := map[int]bool{}
+			for n, ok := tc.b.NextSet(0); ok; n, ok = tc.b.NextSet(n + 1) {
+				require.True(t, tc.b.Test(n))
+				actual[n] = true
+			}
+			require.Equal(t, tc.ref, actual)
+		}
+	}
+}
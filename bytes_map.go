@@ -0,0 +1,150 @@
+package immutable
+
+import "unsafe"
+
+// BytesMap implements an ordered map keyed by byte slices. Keys are ordered lexicographically by
+// byte, like bytes.Compare, with shorter keys ordered before longer keys that they are a prefix of.
+//
+// It's stored as an OrderedMap with BytesKey keys, but unlike converting each key to a BytesKey,
+// lookups don't copy or allocate. Set copies the key so that the map is unaffected by later
+// modifications to it, while SetAliased stores the given slice itself, which avoids the copy when
+// the caller can guarantee that the slice is never modified again.
+//
+// Nil and the zero value for BytesMap are both empty maps.
+type BytesMap[V any] struct {
+	m *OrderedMap[BytesKey, V]
+}
+
+// bytesMapKey returns a key which aliases the given bytes. It must only be stored in a map if the
+// bytes are never modified.
+func bytesMapKey(b []byte) BytesKey {
+	// A slice header begins with the same fields as a string header.
+	return BytesKey(*(*string)(unsafe.Pointer(&b)))
+}
+
+// bytesMapKeyBytes returns a slice which aliases the given key. It must not be modified.
+func bytesMapKeyBytes(k BytesKey) []byte {
+	return unsafe.Slice(*(**byte)(unsafe.Pointer(&k)), len(k))
+}
+
+func (m *BytesMap[V]) orderedMap() *OrderedMap[BytesKey, V] {
+	if m == nil {
+		return nil
+	}
+	return m.m
+}
+
+// OrderedMap returns the map's contents as an OrderedMap.
+//
+// Complexity: O(1) worst-case
+func (m *BytesMap[V]) OrderedMap() *OrderedMap[BytesKey, V] {
+	return m.orderedMap()
+}
+
+// Empty returns true if the map is empty.
+//
+// Complexity: O(1) worst-case
+func (m *BytesMap[V]) Empty() bool {
+	return m.orderedMap().Empty()
+}
+
+// Len returns the number of elements in the map.
+//
+// Complexity: O(1) worst-case
+func (m *BytesMap[V]) Len() int {
+	return m.orderedMap().Len()
+}
+
+// Get returns the value associated with the given key if set.
+//
+// Complexity: O(log n) worst-case
+func (m *BytesMap[V]) Get(key []byte) (V, bool) {
+	return m.orderedMap().Get(bytesMapKey(key))
+}
+
+// Contains returns true if the given key is set.
+//
+// Complexity: O(log n) worst-case
+func (m *BytesMap[V]) Contains(key []byte) bool {
+	return m.orderedMap().Contains(bytesMapKey(key))
+}
+
+// Set associates a value with a copy of the given key.
+//
+// Complexity: O(log n) worst-case
+func (m *BytesMap[V]) Set(key []byte, value V) *BytesMap[V] {
+	return &BytesMap[V]{
+		m: m.orderedMap().Set(NewBytesKey(key), value),
+	}
+}
+
+// SetAliased is like Set, but stores the given slice as the key without copying it. The slice must
+// never be modified afterwards, or the map will be corrupted.
+//
+// Complexity: O(log n) worst-case
+func (m *BytesMap[V]) SetAliased(key []byte, value V) *BytesMap[V] {
+	return &BytesMap[V]{
+		m: m.orderedMap().Set(bytesMapKey(key), value),
+	}
+}
+
+// Delete removes a key from the map.
+//
+// Complexity: O(log n) worst-case
+func (m *BytesMap[V]) Delete(key []byte) *BytesMap[V] {
+	ret := m.orderedMap().Delete(bytesMapKey(key))
+	if ret == m.orderedMap() {
+		return m
+	}
+	return &BytesMap[V]{
+		m: ret,
+	}
+}
+
+// DeletePrefix removes all elements whose keys begin with prefix.
+//
+// Complexity: O(log n + p) worst-case, where p is the length of the prefix
+func (m *BytesMap[V]) DeletePrefix(prefix []byte) *BytesMap[V] {
+	ret := DeletePrefix(m.orderedMap(), bytesMapKey(prefix))
+	if ret == m.orderedMap() {
+		return m
+	}
+	return &BytesMap[V]{
+		m: ret,
+	}
+}
+
+// ForEach calls f for each element in the map in ascending order of keys, stopping early if f
+// returns false. The keys given to f are shared with the map and must not be modified.
+//
+// Complexity: O(n) worst-case
+func (m *BytesMap[V]) ForEach(f func(key []byte, value V) bool) {
+	m.orderedMap().ForEach(func(key BytesKey, value V) bool {
+		return f(bytesMapKeyBytes(key), value)
+	})
+}
+
+// ForEachRange is like ForEach, but only calls f for keys greater than or equal to start and less
+// than end. If end is nil, iteration continues to the end of the map.
+//
+// Complexity: O(log n + k) amortized, where k is the number of elements in the range
+func (m *BytesMap[V]) ForEachRange(start, end []byte, f func(key []byte, value V) bool) {
+	it := m.orderedMap().Iterator()
+	it.Seek(bytesMapKey(start))
+	for it.Next() {
+		if end != nil && it.Key() >= bytesMapKey(end) {
+			return
+		} else if !f(bytesMapKeyBytes(it.Key()), it.Value()) {
+			return
+		}
+	}
+}
+
+// CheckInvariants verifies the structural integrity of the map, returning an error describing the
+// first problem found. It checks the red-black invariants of the underlying map and that its keys
+// are in ascending order.
+//
+// Complexity: O(n) worst-case
+func (m *BytesMap[V]) CheckInvariants() error {
+	return m.orderedMap().CheckInvariants()
+}
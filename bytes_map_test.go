@@ -0,0 +1,126 @@
+package immutable
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBytesMap(t *testing.T) {
+	var m *BytesMap[int]
+	assert.True(t, m.Empty())
+	assert.Equal(t, 0, m.Len())
+	assert.Nil(t, m.OrderedMap())
+	assert.Same(t, m, m.Delete([]byte("a")))
+	assert.False(t, m.Contains(nil))
+	assert.Equal(t, 1, (&BytesMap[int]{}).Set(nil, 1).Len())
+
+	key := []byte("b")
+	m = m.Set(key, 2).Set([]byte("a"), 1).Set([]byte("ab"), 3).Set([]byte{}, 0)
+	key[0] = 'z'
+	v, ok := m.Get([]byte("b"))
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	assert.False(t, m.Contains(key))
+	assert.True(t, m.Contains(nil))
+
+	var keys []string
+	m.ForEach(func(key []byte, value int) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+	assert.Equal(t, []string{"", "a", "ab", "b"}, keys)
+
+	keys = nil
+	m.ForEachRange([]byte("a"), []byte("b"), func(key []byte, value int) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+	assert.Equal(t, []string{"a", "ab"}, keys)
+
+	keys = nil
+	m.ForEachRange([]byte("aa"), nil, func(key []byte, value int) bool {
+		keys = append(keys, string(key))
+		return len(keys) < 1
+	})
+	assert.Equal(t, []string{"ab"}, keys)
+
+	aliased := []byte("c")
+	m2 := m.SetAliased(aliased, 4)
+	assert.True(t, m2.Contains([]byte("c")))
+	assert.False(t, m.Contains([]byte("c")))
+
+	m2 = m2.Delete([]byte("a"))
+	assert.Equal(t, 4, m2.Len())
+	assert.Equal(t, 4, m.Len())
+	assert.NoError(t, m2.OrderedMap().CheckInvariants())
+}
+
+func TestBytesMap_Random(t *testing.T) {
+	var m *BytesMap[int]
+	ref := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		key := make([]byte, rand.Intn(3))
+		for j := range key {
+			key[j] = byte(rand.Intn(4))
+		}
+		if rand.Intn(3) == 0 {
+			m = m.Delete(key)
+			delete(ref, string(key))
+		} else {
+			m = m.Set(key, i)
+			ref[string(key)] = i
+		}
+		require.NoError(t, m.CheckInvariants())
+		require.Equal(t, len(ref), m.Len())
+	}
+	for k, expected := range ref {
+		v, ok := m.Get([]byte(k))
+		require.True(t, ok)
+		require.Equal(t, expected, v)
+	}
+}
+
+func TestBytesMap_Allocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector affects allocations")
+	}
+	m := (*BytesMap[int])(nil).Set([]byte("foo"), 1)
+	key := []byte("foo")
+	assert.Equal(t, 0.0, testing.AllocsPerRun(100, func() {
+		m.Get(key)
+	}))
+}
+
+var bytesMapValueResult int
+
+func BenchmarkBytesMap_Get(b *testing.B) {
+	for _, n := range []int{100, 10000, 1000000} {
+		var m *BytesMap[int]
+		keys := make([][]byte, n)
+		for i := range keys {
+			keys[i] = []byte(fmt.Sprintf("key%08d", i))
+			m = m.SetAliased(keys[i], i)
+		}
+		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bytesMapValueResult, _ = m.Get(keys[i%n])
+			}
+		})
+	}
+}
+
+func TestBytesMap_DeletePrefix(t *testing.T) {
+	var m *BytesMap[int]
+	assert.Nil(t, m.DeletePrefix([]byte("a")))
+	m = m.Set([]byte("a"), 1).Set([]byte("ab"), 2).Set([]byte("b"), 3)
+	assert.Same(t, m, m.DeletePrefix([]byte("c")))
+	m2 := m.DeletePrefix([]byte("a"))
+	assert.Equal(t, 1, m2.Len())
+	assert.True(t, m2.Contains([]byte("b")))
+	assert.Equal(t, 3, m.Len())
+}
@@ -0,0 +1,234 @@
+package immutable
+
+import "fmt"
+
+// chunkTree is a persistent AVL tree whose leaves hold chunks of a sequence. Interior nodes always
+// have two children. It supports concatenation and splitting by index in logarithmic time, and is
+// the basis for sequence types such as Rope.
+//
+// Chunks are never modified once they're part of a tree, so they may be shared between trees.
+type chunkTree[T any] struct {
+	height int
+	len    int
+	left   *chunkTree[T]
+	right  *chunkTree[T]
+	chunk  []T
+}
+
+// chunkTreeFromSlice builds a balanced tree containing the given items, which the tree takes
+// ownership of.
+func chunkTreeFromSlice[T any](items []T, chunkSize int) *chunkTree[T] {
+	if len(items) == 0 {
+		return nil
+	}
+	leaves := make([]*chunkTree[T], 0, (len(items)+chunkSize-1)/chunkSize)
+	for len(items) > 0 {
+		n := chunkSize
+		if n > len(items) {
+			n = len(items)
+		}
+		leaves = append(leaves, newChunkTreeLeaf(items[:n:n]))
+		items = items[n:]
+	}
+	return chunkTreeBuild(leaves)
+}
+
+func chunkTreeBuild[T any](leaves []*chunkTree[T]) *chunkTree[T] {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	mid := len(leaves) / 2
+	return newChunkTreeNode(chunkTreeBuild(leaves[:mid]), chunkTreeBuild(leaves[mid:]))
+}
+
+func newChunkTreeLeaf[T any](chunk []T) *chunkTree[T] {
+	return &chunkTree[T]{
+		height: 1,
+		len:    len(chunk),
+		chunk:  chunk,
+	}
+}
+
+func newChunkTreeNode[T any](left, right *chunkTree[T]) *chunkTree[T] {
+	height := left.height
+	if right.height > height {
+		height = right.height
+	}
+	return &chunkTree[T]{
+		height: height + 1,
+		len:    left.len + right.len,
+		left:   left,
+		right:  right,
+	}
+}
+
+func (t *chunkTree[T]) Len() int {
+	if t == nil {
+		return 0
+	}
+	return t.len
+}
+
+func (t *chunkTree[T]) Height() int {
+	if t == nil {
+		return 0
+	}
+	return t.height
+}
+
+func (t *chunkTree[T]) isLeaf() bool {
+	return t.left == nil
+}
+
+// at returns the item at index i, which must be in range.
+func (t *chunkTree[T]) at(i int) T {
+	for !t.isLeaf() {
+		if i < t.left.len {
+			t = t.left
+		} else {
+			i -= t.left.len
+			t = t.right
+		}
+	}
+	return t.chunk[i]
+}
+
+// set returns a tree with the item at index i, which must be in range, replaced by the given value.
+// Only the chunk containing the item is copied.
+func (t *chunkTree[T]) set(i int, value T) *chunkTree[T] {
+	if t.isLeaf() {
+		chunk := append([]T(nil), t.chunk...)
+		chunk[i] = value
+		return newChunkTreeLeaf(chunk)
+	} else if i < t.left.len {
+		return newChunkTreeNode(t.left.set(i, value), t.right)
+	}
+	return newChunkTreeNode(t.left, t.right.set(i-t.left.len, value))
+}
+
+// push returns a tree with the given item added to the end. The item is added to the last chunk if
+// it has room, so trees built by pushing items one at a time have full chunks.
+func (t *chunkTree[T]) push(value T, chunkSize int) *chunkTree[T] {
+	if t == nil {
+		return newChunkTreeLeaf([]T{value})
+	} else if !t.isLeaf() {
+		return chunkTreeBalance(t.left, t.right.push(value, chunkSize))
+	} else if t.len < chunkSize {
+		chunk := make([]T, t.len+1)
+		copy(chunk, t.chunk)
+		chunk[t.len] = value
+		return newChunkTreeLeaf(chunk)
+	}
+	return newChunkTreeNode(t, newChunkTreeLeaf([]T{value}))
+}
+
+// chunkTreeBalance returns a node containing the given subtrees, whose heights may differ by at most
+// two, rotating if necessary to restore balance.
+func chunkTreeBalance[T any](left, right *chunkTree[T]) *chunkTree[T] {
+	if left.Height() > right.Height()+1 {
+		if left.left.height >= left.right.height {
+			return newChunkTreeNode(left.left, newChunkTreeNode(left.right, right))
+		}
+		return newChunkTreeNode(
+			newChunkTreeNode(left.left, left.right.left),
+			newChunkTreeNode(left.right.right, right),
+		)
+	} else if right.Height() > left.Height()+1 {
+		if right.right.height >= right.left.height {
+			return newChunkTreeNode(newChunkTreeNode(left, right.left), right.right)
+		}
+		return newChunkTreeNode(
+			newChunkTreeNode(left, right.left.left),
+			newChunkTreeNode(right.left.right, right.right),
+		)
+	}
+	return newChunkTreeNode(left, right)
+}
+
+// chunkTreeConcat returns a tree containing the items of a followed by the items of b. Adjacent
+// leaves which are small enough are merged.
+func chunkTreeConcat[T any](a, b *chunkTree[T], chunkSize int) *chunkTree[T] {
+	if a == nil {
+		return b
+	} else if b == nil {
+		return a
+	} else if a.height > b.height+1 {
+		return chunkTreeBalance(a.left, chunkTreeConcat(a.right, b, chunkSize))
+	} else if b.height > a.height+1 {
+		return chunkTreeBalance(chunkTreeConcat(a, b.left, chunkSize), b.right)
+	} else if a.isLeaf() && b.isLeaf() && a.len+b.len <= chunkSize {
+		chunk := make([]T, a.len+b.len)
+		copy(chunk, a.chunk)
+		copy(chunk[a.len:], b.chunk)
+		return newChunkTreeLeaf(chunk)
+	}
+	return newChunkTreeNode(a, b)
+}
+
+// split returns a tree containing the first i items and a tree containing the rest.
+func (t *chunkTree[T]) split(i int, chunkSize int) (left, right *chunkTree[T]) {
+	if i <= 0 {
+		return nil, t
+	} else if i >= t.Len() {
+		return t, nil
+	} else if t.isLeaf() {
+		return newChunkTreeLeaf(t.chunk[:i:i]), newChunkTreeLeaf(t.chunk[i:])
+	} else if i <= t.left.len {
+		left, right = t.left.split(i, chunkSize)
+		return left, chunkTreeConcat(right, t.right, chunkSize)
+	}
+	left, right = t.right.split(i-t.left.len, chunkSize)
+	return chunkTreeConcat(t.left, left, chunkSize), right
+}
+
+// appendTo appends the items of the tree to dst.
+func (t *chunkTree[T]) appendTo(dst []T) []T {
+	if t == nil {
+		return dst
+	} else if t.isLeaf() {
+		return append(dst, t.chunk...)
+	}
+	return t.right.appendTo(t.left.appendTo(dst))
+}
+
+// forEachChunk calls f with each chunk in order, stopping early if f returns false.
+func (t *chunkTree[T]) forEachChunk(f func(chunk []T) bool) bool {
+	if t == nil {
+		return true
+	} else if t.isLeaf() {
+		return f(t.chunk)
+	}
+	return t.left.forEachChunk(f) && t.right.forEachChunk(f)
+}
+
+func (t *chunkTree[T]) checkInvariants(chunkSize int) error {
+	if t == nil {
+		return nil
+	} else if t.isLeaf() {
+		if t.right != nil {
+			return fmt.Errorf("node has a right child but no left child")
+		} else if len(t.chunk) == 0 || len(t.chunk) > chunkSize {
+			return fmt.Errorf("leaf has %v items", len(t.chunk))
+		} else if t.len != len(t.chunk) || t.height != 1 {
+			return fmt.Errorf("leaf has incorrect length or height")
+		}
+		return nil
+	} else if t.right == nil {
+		return fmt.Errorf("node has a left child but no right child")
+	} else if t.chunk != nil {
+		return fmt.Errorf("interior node has a chunk")
+	}
+	if err := t.left.checkInvariants(chunkSize); err != nil {
+		return err
+	} else if err := t.right.checkInvariants(chunkSize); err != nil {
+		return err
+	}
+	if t.len != t.left.len+t.right.len {
+		return fmt.Errorf("node has incorrect length %v", t.len)
+	} else if diff := t.left.height - t.right.height; diff < -1 || diff > 1 {
+		return fmt.Errorf("node is unbalanced")
+	} else if t.height != newChunkTreeNode(t.left, t.right).height {
+		return fmt.Errorf("node has incorrect height %v", t.height)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package immutable
+
+import "fmt"
+
+const chunkedQueueChunkSize = 32
+
+// ChunkedQueue implements a first in, first out container.
+//
+// Unlike Queue, which stores one node per item, ChunkedQueue stores items in contiguous chunks.
+// This makes PopFront and Front considerably more cache-friendly for large queues at the cost of
+// occasionally copying a chunk's worth of items during PushBack.
+//
+// Nil and the zero value for ChunkedQueue are both empty queues.
+type ChunkedQueue[T any] struct {
+	// The remaining items in the chunk at the front of the queue. If the queue is non-empty, this is
+	// never empty.
+	front []T
+
+	// Full chunks between the front and back.
+	middle *Queue[[]T]
+
+	// Items pushed since the last full chunk, most recent on top.
+	back    *Stack[T]
+	backLen int
+}
+
+// ChunkedQueueFromSlice returns a new queue containing the given items. The first item will be at
+// the front.
+//
+// Complexity: O(n) worst-case
+func ChunkedQueueFromSlice[T any](items []T) *ChunkedQueue[T] {
+	if len(items) == 0 {
+		return nil
+	}
+	chunks := make([]T, len(items))
+	copy(chunks, items)
+	if len(chunks) <= chunkedQueueChunkSize {
+		return &ChunkedQueue[T]{
+			front: chunks,
+		}
+	}
+	ret := &ChunkedQueue[T]{
+		front:  chunks[:chunkedQueueChunkSize:chunkedQueueChunkSize],
+		middle: &Queue[[]T]{},
+	}
+	chunks = chunks[chunkedQueueChunkSize:]
+	for len(chunks) >= chunkedQueueChunkSize {
+		ret.middle = ret.middle.PushBack(chunks[:chunkedQueueChunkSize:chunkedQueueChunkSize])
+		chunks = chunks[chunkedQueueChunkSize:]
+	}
+	// The remainder doesn't fill a chunk, so it goes in the back like items pushed individually.
+	for _, item := range chunks {
+		ret.back = ret.back.Push(item)
+	}
+	ret.backLen = len(chunks)
+	return ret
+}
+
+// Empty returns true if the queue is empty.
+//
+// Complexity: O(1) worst-case
+func (q *ChunkedQueue[T]) Empty() bool {
+	return q == nil || len(q.front) == 0
+}
+
+// Front returns the item at the front of the queue. It panics if the queue is empty.
+//
+// Complexity: O(1) worst-case
+func (q *ChunkedQueue[T]) Front() T {
+	if q.Empty() {
+		panic("immutable: ChunkedQueue is empty")
+	}
+	return q.front[0]
+}
+
+// TryFront returns the item at the front of the queue, or false if the queue is empty.
+//
+// Complexity: O(1) worst-case
+func (q *ChunkedQueue[T]) TryFront() (T, bool) {
+	if q.Empty() {
+		var zero T
+		return zero, false
+	}
+	return q.front[0], true
+}
+
+// PopFront removes the item at the front of the queue. It panics if the queue is empty.
+//
+// Complexity: O(1) worst-case
+func (q *ChunkedQueue[T]) PopFront() *ChunkedQueue[T] {
+	if q.Empty() {
+		panic("immutable: ChunkedQueue is empty")
+	} else if len(q.front) > 1 {
+		return &ChunkedQueue[T]{
+			front:   q.front[1:],
+			middle:  q.middle,
+			back:    q.back,
+			backLen: q.backLen,
+		}
+	} else if !q.middle.Empty() {
+		return &ChunkedQueue[T]{
+			front:   q.middle.Front(),
+			middle:  q.middle.PopFront(),
+			back:    q.back,
+			backLen: q.backLen,
+		}
+	} else if q.backLen > 0 {
+		return &ChunkedQueue[T]{
+			front: chunkedQueueFlush(q.back, q.backLen),
+		}
+	}
+	return nil
+}
+
+// PushBack pushes an item onto the back of the queue.
+//
+// Complexity: O(1) worst-case
+func (q *ChunkedQueue[T]) PushBack(value T) *ChunkedQueue[T] {
+	if q.Empty() {
+		return &ChunkedQueue[T]{
+			front: []T{value},
+		}
+	} else if q.middle.Empty() && q.backLen == 0 && len(q.front) < chunkedQueueChunkSize {
+		front := make([]T, len(q.front)+1)
+		copy(front, q.front)
+		front[len(q.front)] = value
+		return &ChunkedQueue[T]{
+			front: front,
+		}
+	}
+	back := q.back.Push(value)
+	if backLen := q.backLen + 1; backLen < chunkedQueueChunkSize {
+		return &ChunkedQueue[T]{
+			front:   q.front,
+			middle:  q.middle,
+			back:    back,
+			backLen: backLen,
+		}
+	}
+	middle := q.middle
+	if middle == nil {
+		middle = &Queue[[]T]{}
+	}
+	return &ChunkedQueue[T]{
+		front:  q.front,
+		middle: middle.PushBack(chunkedQueueFlush(back, chunkedQueueChunkSize)),
+	}
+}
+
+// ToSlice returns the items in the queue as a slice, starting with the front item.
+//
+// Complexity: O(n) worst-case
+func (q *ChunkedQueue[T]) ToSlice() []T {
+	if q.Empty() {
+		return nil
+	}
+	ret := append([]T(nil), q.front...)
+	for m := q.middle; !m.Empty(); m = m.PopFront() {
+		ret = append(ret, m.Front()...)
+	}
+	return append(ret, chunkedQueueFlush(q.back, q.backLen)...)
+}
+
+// CheckInvariants verifies the structural integrity of the queue, returning an error describing the
+// first problem found. It checks that the middle chunks are full, that the front chunk and the back
+// stack are within the chunk size, and that the recorded length of the back is correct.
+//
+// Complexity: O(n) worst-case
+func (q *ChunkedQueue[T]) CheckInvariants() error {
+	if q.Empty() {
+		if q != nil && (!q.middle.Empty() || q.backLen != 0 || !q.back.Empty()) {
+			return fmt.Errorf("queue has no front chunk but is not empty")
+		}
+		return nil
+	} else if len(q.front) > chunkedQueueChunkSize {
+		return fmt.Errorf("front chunk has length %v", len(q.front))
+	}
+	if err := q.middle.CheckInvariants(); err != nil {
+		return err
+	}
+	for m := q.middle; !m.Empty(); m = m.PopFront() {
+		if len(m.Front()) != chunkedQueueChunkSize {
+			return fmt.Errorf("middle chunk has length %v", len(m.Front()))
+		}
+	}
+	backLen := 0
+	for s := q.back; !s.Empty(); s = s.Pop() {
+		backLen++
+	}
+	if backLen != q.backLen {
+		return fmt.Errorf("back has length %v, but %v was recorded", backLen, q.backLen)
+	} else if backLen >= chunkedQueueChunkSize {
+		return fmt.Errorf("back has length %v", backLen)
+	}
+	return nil
+}
+
+// chunkedQueueFlush copies the top n items of the stack into a chunk, oldest first.
+func chunkedQueueFlush[T any](s *Stack[T], n int) []T {
+	chunk := make([]T, n)
+	for i := n - 1; i >= 0; i-- {
+		chunk[i] = s.Peek()
+		s = s.Pop()
+	}
+	return chunk
+}
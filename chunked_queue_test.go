@@ -0,0 +1,147 @@
+package immutable
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkedQueue(t *testing.T) {
+	var q ChunkedQueue[string]
+	assert.True(t, q.Empty())
+	q2 := q.PushBack("foo")
+	assert.True(t, q.Empty())
+	assert.False(t, q2.Empty())
+	assert.Equal(t, q2.Front(), "foo")
+	q3 := q2.PushBack("bar")
+	assert.Equal(t, q3.Front(), "foo")
+	assert.Equal(t, q3.PopFront().Front(), "bar")
+	q4 := q3.PushBack("baz")
+	assert.Equal(t, q4.Front(), "foo")
+	assert.Equal(t, q4.PopFront().Front(), "bar")
+	assert.Equal(t, q4.PopFront().PopFront().Front(), "baz")
+	assert.True(t, q4.PopFront().PopFront().PopFront().Empty())
+}
+
+func TestChunkedQueue_TryFront(t *testing.T) {
+	var q *ChunkedQueue[int]
+	_, ok := q.TryFront()
+	assert.False(t, ok)
+	assert.PanicsWithValue(t, "immutable: ChunkedQueue is empty", func() { q.Front() })
+	assert.PanicsWithValue(t, "immutable: ChunkedQueue is empty", func() { q.PopFront() })
+
+	v, ok := q.PushBack(1).TryFront()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+}
+
+func TestChunkedQueue_Fuzz(t *testing.T) {
+	var ref []int
+	var q *ChunkedQueue[int]
+	for i := 0; i < 100000; i++ {
+		if len(ref) > 0 && rand.Intn(3) == 0 {
+			require.False(t, q.Empty())
+			require.Equal(t, ref[0], q.Front())
+			ref = ref[1:]
+			q = q.PopFront()
+		} else {
+			ref = append(ref, i)
+			q = q.PushBack(i)
+		}
+		require.Equal(t, len(ref) == 0, q.Empty())
+		if i%100 == 0 {
+			require.NoError(t, q.CheckInvariants())
+		}
+	}
+	for _, v := range ref {
+		require.Equal(t, v, q.Front())
+		q = q.PopFront()
+	}
+	assert.True(t, q.Empty())
+}
+
+func TestChunkedQueue_Persistence(t *testing.T) {
+	var q *ChunkedQueue[int]
+	for i := 0; i < 100; i++ {
+		q = q.PushBack(i)
+	}
+	a := q.PushBack(100)
+	b := q.PushBack(200)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, i, a.Front())
+		assert.Equal(t, i, b.Front())
+		a = a.PopFront()
+		b = b.PopFront()
+	}
+	assert.Equal(t, 100, a.Front())
+	assert.Equal(t, 200, b.Front())
+}
+
+func TestChunkedQueueFromSlice(t *testing.T) {
+	assert.True(t, ChunkedQueueFromSlice[int](nil).Empty())
+	assert.Empty(t, ChunkedQueueFromSlice[int](nil).ToSlice())
+
+	for _, n := range []int{1, 10, 32, 33, 40, 64, 100} {
+		items := make([]int, n)
+		for i := range items {
+			items[i] = i
+		}
+		q := ChunkedQueueFromSlice(items)
+		require.NoError(t, q.CheckInvariants())
+		require.NoError(t, q.PopFront().PushBack(n).CheckInvariants())
+		assert.Equal(t, 0, q.Front())
+		assert.Equal(t, items, q.ToSlice())
+		assert.Equal(t, append(items[1:], n), q.PopFront().PushBack(n).ToSlice())
+	}
+}
+
+var stringChunkedQueueResult *ChunkedQueue[string]
+
+func BenchmarkChunkedQueue_PushBack(b *testing.B) {
+	for _, n := range []int{100, 10000, 1000000} {
+		q := &ChunkedQueue[string]{}
+		for i := 0; i < n; i++ {
+			q = q.PushBack("foo")
+		}
+		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				stringChunkedQueueResult = q.PushBack("foo")
+			}
+		})
+	}
+}
+
+var intChunkedQueueResult *ChunkedQueue[int]
+
+func BenchmarkChunkedQueue_PopFront(b *testing.B) {
+	for _, n := range []int{100, 10000, 200000} {
+		q := &ChunkedQueue[int]{}
+		for i := 0; i < n; i++ {
+			q = q.PushBack(i)
+		}
+		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				intChunkedQueueResult = q.PopFront()
+			}
+		})
+	}
+}
+
+func BenchmarkChunkedQueue_Drain(b *testing.B) {
+	for _, n := range []int{100, 10000, 200000} {
+		q := &ChunkedQueue[int]{}
+		for i := 0; i < n; i++ {
+			q = q.PushBack(i)
+		}
+		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for q := q; !q.Empty(); q = q.PopFront() {
+					intChunkedQueueResult = q
+				}
+			}
+		})
+	}
+}
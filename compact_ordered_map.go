@@ -0,0 +1,239 @@
+package immutable
+
+import (
+	"fmt"
+	"sort"
+
+	"golang.org/x/exp/constraints"
+)
+
+const (
+	// compactOrderedMapMaxArrayLen is the largest number of elements stored as sorted arrays. Maps
+	// which grow beyond it are converted to trees.
+	compactOrderedMapMaxArrayLen = 16
+
+	// compactOrderedMapMinTreeLen is the smallest number of elements stored as a tree. Maps which
+	// shrink below it are converted back to arrays. The gap between the two thresholds prevents
+	// alternating insertions and deletions from converting back and forth on every operation.
+	compactOrderedMapMinTreeLen = compactOrderedMapMaxArrayLen / 2
+)
+
+// CompactOrderedMap implements an ordered map which stores small maps as sorted arrays of keys and
+// values, switching to an OrderedMap tree once it grows beyond a small threshold. The switch is
+// transparent to users of the map. Use NewCompactOrderedMap and OrderedMap to convert between the
+// two.
+//
+// Small maps stored this way use much less memory than trees, which need a node per element, and
+// lookups are faster since they binary search contiguous memory. Modifying a small map copies its
+// arrays, but since their length is bounded, that doesn't affect the time complexities.
+//
+// OrderedMap also stores small maps as sorted arrays, but of tree nodes, so that its elements can
+// still be navigated like those of any other tree. CompactOrderedMap drops the nodes' links and
+// colors entirely, which saves more memory at the cost of that flexibility.
+//
+// Nil and the zero value for CompactOrderedMap are both empty maps.
+type CompactOrderedMap[K constraints.Ordered, V any] struct {
+	// Exactly one of these representations is used. If tree is nil, the map is stored in keys and
+	// values.
+	keys   []K
+	values []V
+	tree   *OrderedMap[K, V]
+}
+
+// NewCompactOrderedMap returns a CompactOrderedMap with the same contents as the given map.
+//
+// Complexity: O(1) worst-case
+func NewCompactOrderedMap[K constraints.Ordered, V any](m *OrderedMap[K, V]) *CompactOrderedMap[K, V] {
+	if m.Empty() {
+		return nil
+	} else if m.Len() > compactOrderedMapMaxArrayLen {
+		return &CompactOrderedMap[K, V]{
+			tree: m,
+		}
+	}
+	ret := &CompactOrderedMap[K, V]{
+		keys:   make([]K, 0, m.Len()),
+		values: make([]V, 0, m.Len()),
+	}
+	m.ForEach(func(key K, value V) bool {
+		ret.keys = append(ret.keys, key)
+		ret.values = append(ret.values, value)
+		return true
+	})
+	return ret
+}
+
+// OrderedMap returns an OrderedMap with the same contents as the map.
+//
+// Complexity: O(1) worst-case
+func (m *CompactOrderedMap[K, V]) OrderedMap() *OrderedMap[K, V] {
+	if m == nil {
+		return nil
+	} else if m.tree != nil || len(m.keys) == 0 {
+		return m.tree
+	}
+	return orderedMapFromSorted(m.keys, m.values, false)
+}
+
+// Empty returns true if the map is empty.
+//
+// Complexity: O(1) worst-case
+func (m *CompactOrderedMap[K, V]) Empty() bool {
+	return m.Len() == 0
+}
+
+// Len returns the number of elements in the map.
+//
+// Complexity: O(1) worst-case
+func (m *CompactOrderedMap[K, V]) Len() int {
+	if m == nil {
+		return 0
+	} else if m.tree != nil {
+		return m.tree.Len()
+	}
+	return len(m.keys)
+}
+
+// search returns the index of the first key in the arrays which is greater than or equal to the
+// given key.
+func (m *CompactOrderedMap[K, V]) search(key K) int {
+	return sort.Search(len(m.keys), func(i int) bool {
+		return m.keys[i] >= key
+	})
+}
+
+// Get returns the value associated with the given key if set.
+//
+// Complexity: O(log n) worst-case
+func (m *CompactOrderedMap[K, V]) Get(key K) (V, bool) {
+	if m == nil || key != key {
+		var zero V
+		return zero, false
+	} else if m.tree != nil {
+		return m.tree.Get(key)
+	}
+	if i := m.search(key); i < len(m.keys) && m.keys[i] == key {
+		return m.values[i], true
+	}
+	var zero V
+	return zero, false
+}
+
+// Set associates a value with the given key.
+//
+// Complexity: O(log n) worst-case
+func (m *CompactOrderedMap[K, V]) Set(key K, value V) *CompactOrderedMap[K, V] {
+	orderedMapCheckKey(key)
+	if m != nil && m.tree != nil {
+		return &CompactOrderedMap[K, V]{
+			tree: m.tree.Set(key, value),
+		}
+	}
+	var keys []K
+	var values []V
+	if m != nil {
+		keys, values = m.keys, m.values
+	}
+	i := sort.Search(len(keys), func(i int) bool {
+		return keys[i] >= key
+	})
+	if i < len(keys) && keys[i] == key {
+		newValues := append([]V(nil), values...)
+		newValues[i] = value
+		return &CompactOrderedMap[K, V]{
+			keys:   keys,
+			values: newValues,
+		}
+	}
+	newKeys := make([]K, len(keys)+1)
+	copy(newKeys, keys[:i])
+	newKeys[i] = key
+	copy(newKeys[i+1:], keys[i:])
+	newValues := make([]V, len(values)+1)
+	copy(newValues, values[:i])
+	newValues[i] = value
+	copy(newValues[i+1:], values[i:])
+	if len(newKeys) > compactOrderedMapMaxArrayLen {
+		return &CompactOrderedMap[K, V]{
+			tree: orderedMapFromSorted(newKeys, newValues, false),
+		}
+	}
+	return &CompactOrderedMap[K, V]{
+		keys:   newKeys,
+		values: newValues,
+	}
+}
+
+// Delete removes a key from the map.
+//
+// Complexity: O(log n) worst-case
+func (m *CompactOrderedMap[K, V]) Delete(key K) *CompactOrderedMap[K, V] {
+	if _, ok := m.Get(key); !ok {
+		return m
+	} else if m.tree != nil {
+		tree := m.tree.Delete(key)
+		if tree.Len() < compactOrderedMapMinTreeLen {
+			return NewCompactOrderedMap(tree)
+		}
+		return &CompactOrderedMap[K, V]{
+			tree: tree,
+		}
+	} else if len(m.keys) == 1 {
+		return nil
+	}
+	i := m.search(key)
+	newKeys := make([]K, 0, len(m.keys)-1)
+	newKeys = append(append(newKeys, m.keys[:i]...), m.keys[i+1:]...)
+	newValues := make([]V, 0, len(m.values)-1)
+	newValues = append(append(newValues, m.values[:i]...), m.values[i+1:]...)
+	return &CompactOrderedMap[K, V]{
+		keys:   newKeys,
+		values: newValues,
+	}
+}
+
+// ForEach invokes f for each element in the map in ascending order of keys, stopping early if f
+// returns false.
+//
+// Complexity: O(n) worst-case
+func (m *CompactOrderedMap[K, V]) ForEach(f func(key K, value V) bool) {
+	if m == nil {
+		return
+	} else if m.tree != nil {
+		m.tree.ForEach(f)
+		return
+	}
+	for i, key := range m.keys {
+		if !f(key, m.values[i]) {
+			return
+		}
+	}
+}
+
+// CheckInvariants verifies the structural integrity of the map, returning an error describing the
+// first problem found. It checks that the map uses the representation appropriate for its size,
+// that array keys are in ascending order, and the invariants of the tree if there is one.
+//
+// Complexity: O(n) worst-case
+func (m *CompactOrderedMap[K, V]) CheckInvariants() error {
+	if m == nil {
+		return nil
+	} else if m.tree != nil {
+		if len(m.keys) != 0 || len(m.values) != 0 {
+			return fmt.Errorf("map has both a tree and arrays")
+		} else if n := m.tree.Len(); n < compactOrderedMapMinTreeLen {
+			return fmt.Errorf("tree has only %v elements", n)
+		}
+		return m.tree.CheckInvariants()
+	} else if len(m.keys) != len(m.values) {
+		return fmt.Errorf("map has %v keys but %v values", len(m.keys), len(m.values))
+	} else if len(m.keys) > compactOrderedMapMaxArrayLen {
+		return fmt.Errorf("arrays have %v elements", len(m.keys))
+	}
+	for i := 1; i < len(m.keys); i++ {
+		if !(m.keys[i-1] < m.keys[i]) {
+			return fmt.Errorf("keys are out of order")
+		}
+	}
+	return nil
+}
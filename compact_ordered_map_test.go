@@ -0,0 +1,114 @@
+package immutable
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactOrderedMap(t *testing.T) {
+	var m *CompactOrderedMap[int, int]
+	assert.True(t, m.Empty())
+	assert.Equal(t, 0, m.Len())
+	assert.Nil(t, m.OrderedMap())
+	assert.Nil(t, m.Delete(1))
+	assert.NoError(t, m.CheckInvariants())
+	_, ok := m.Get(1)
+	assert.False(t, ok)
+
+	assert.Equal(t, 0, (&CompactOrderedMap[int, int]{}).Len())
+	assert.Equal(t, 1, (&CompactOrderedMap[int, int]{}).Set(1, 1).Len())
+
+	ref := map[int]int{}
+	for i := 0; i < 10000; i++ {
+		// Drift between small and large sizes so that both representations are exercised.
+		keys := 10
+		if (i/500)%2 == 1 {
+			keys = 40
+		}
+		k := rand.Intn(keys)
+		if rand.Intn(3) == 0 {
+			delete(ref, k)
+			m = m.Delete(k)
+		} else {
+			ref[k] = i
+			m = m.Set(k, i)
+		}
+		require.NoError(t, m.CheckInvariants())
+		require.Equal(t, len(ref), m.Len())
+		require.Equal(t, len(ref) == 0, m.Empty())
+		v, ok := m.Get(k)
+		expected, expectedOk := ref[k]
+		require.Equal(t, expectedOk, ok)
+		require.Equal(t, expected, v)
+	}
+
+	actual := map[int]int{}
+	prev := math.MinInt
+	m.ForEach(func(key, value int) bool {
+		assert.Greater(t, key, prev)
+		prev = key
+		actual[key] = value
+		return true
+	})
+	assert.Equal(t, ref, actual)
+	assert.Equal(t, ref, m.OrderedMap().ToMap())
+	assert.NoError(t, m.OrderedMap().CheckInvariants())
+}
+
+func TestCompactOrderedMap_Representation(t *testing.T) {
+	var m *CompactOrderedMap[int, string]
+	for i := 0; i < compactOrderedMapMaxArrayLen; i++ {
+		m = m.Set(i, "a")
+	}
+	assert.Nil(t, m.tree)
+
+	m = m.Set(compactOrderedMapMaxArrayLen, "a")
+	assert.NotNil(t, m.tree)
+
+	for i := 0; m.Len() > compactOrderedMapMinTreeLen; i++ {
+		m = m.Delete(i)
+		assert.NotNil(t, m.tree)
+	}
+	m = m.Delete(compactOrderedMapMaxArrayLen)
+	assert.Nil(t, m.tree)
+	assert.NoError(t, m.CheckInvariants())
+}
+
+func TestCompactOrderedMap_NaN(t *testing.T) {
+	m := (*CompactOrderedMap[float64, int])(nil).Set(1, 1)
+	_, ok := m.Get(math.NaN())
+	assert.False(t, ok)
+	assert.Same(t, m, m.Delete(math.NaN()))
+	assert.Panics(t, func() {
+		m.Set(math.NaN(), 1)
+	})
+}
+
+func TestNewCompactOrderedMap(t *testing.T) {
+	assert.Nil(t, NewCompactOrderedMap[int, int](nil))
+
+	for _, n := range []int{1, compactOrderedMapMaxArrayLen, 100} {
+		var m *OrderedMap[int, int]
+		for i := 0; i < n; i++ {
+			m = m.Set(i, i*i)
+		}
+		c := NewCompactOrderedMap(m)
+		assert.NoError(t, c.CheckInvariants())
+		assert.Equal(t, m.ToMap(), c.OrderedMap().ToMap())
+	}
+}
+
+func BenchmarkCompactOrderedMap_Get(b *testing.B) {
+	var m *CompactOrderedMap[int, int]
+	for i := 0; i < compactOrderedMapMaxArrayLen; i++ {
+		m = m.Set(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Get(i % compactOrderedMapMaxArrayLen)
+	}
+}
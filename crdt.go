@@ -0,0 +1,128 @@
+package immutable
+
+import "golang.org/x/exp/constraints"
+
+// MergeLWW merges another map into this one with last-writer-wins semantics, as used by
+// conflict-free replicated data types. For keys in both maps, the value with the greater timestamp,
+// as returned by timestampOf, is kept.
+//
+// Merging is commutative, associative, and idempotent as long as the values for a key never share a
+// timestamp unless they're equal. Replicas typically guarantee that by including a replica ID in the
+// low bits of their timestamps. When timestamps are equal, the value from this map is kept.
+//
+// Keys are never removed by merging, so deletions must be represented by values, such as
+// tombstones with their own timestamps.
+//
+// Complexity: O(m log n) worst-case, where m is the size of the smaller map
+func (m *OrderedMap[K, V]) MergeLWW(other *OrderedMap[K, V], timestampOf func(V) int64) *OrderedMap[K, V] {
+	if m == other || other.Empty() {
+		return m
+	} else if m.Empty() {
+		return other
+	}
+
+	// Iterate over the smaller map, setting its elements in the larger one.
+	if other.Len() <= m.Len() {
+		ret := m
+		other.ForEach(func(key K, value V) bool {
+			if existing, ok := m.Get(key); !ok || timestampOf(value) > timestampOf(existing) {
+				ret = ret.Set(key, value)
+			}
+			return true
+		})
+		return ret
+	}
+	ret := other
+	m.ForEach(func(key K, value V) bool {
+		if existing, ok := other.Get(key); !ok || timestampOf(value) >= timestampOf(existing) {
+			ret = ret.Set(key, value)
+		}
+		return true
+	})
+	return ret
+}
+
+// GSet implements a grow-only set, the simplest conflict-free replicated data type. Items can be
+// added but never removed, so replicas which merge each other's sets always converge.
+//
+// Nil and the zero value for GSet are both empty sets.
+type GSet[T constraints.Ordered] struct {
+	m *OrderedMap[T, struct{}]
+}
+
+func (s *GSet[T]) orderedMap() *OrderedMap[T, struct{}] {
+	if s == nil {
+		return nil
+	}
+	return s.m
+}
+
+// Empty returns true if the set is empty.
+//
+// Complexity: O(1) worst-case
+func (s *GSet[T]) Empty() bool {
+	return s.orderedMap().Empty()
+}
+
+// Len returns the number of items in the set.
+//
+// Complexity: O(1) worst-case
+func (s *GSet[T]) Len() int {
+	return s.orderedMap().Len()
+}
+
+// Contains returns true if the item is in the set.
+//
+// Complexity: O(log n) worst-case
+func (s *GSet[T]) Contains(value T) bool {
+	return s.orderedMap().Contains(value)
+}
+
+// Add adds an item to the set. If it's already in the set, the set is returned unchanged. It panics
+// if the item is NaN.
+//
+// Complexity: O(log n) worst-case
+func (s *GSet[T]) Add(value T) *GSet[T] {
+	if s.Contains(value) {
+		return s
+	}
+	return &GSet[T]{
+		m: s.orderedMap().Set(value, struct{}{}),
+	}
+}
+
+// Merge returns the union of the two sets.
+//
+// Complexity: O(m log n) worst-case, where m is the size of the smaller set
+func (s *GSet[T]) Merge(other *GSet[T]) *GSet[T] {
+	if s.Len() < other.Len() {
+		s, other = other, s
+	}
+	ret := s
+	other.ForEach(func(value T) bool {
+		ret = ret.Add(value)
+		return true
+	})
+	return ret
+}
+
+// ForEach calls f for each item in the set in ascending order, stopping early if f returns false.
+//
+// Complexity: O(n) worst-case
+func (s *GSet[T]) ForEach(f func(value T) bool) {
+	s.orderedMap().ForEach(func(value T, _ struct{}) bool {
+		return f(value)
+	})
+}
+
+// ToSlice returns the items in the set in ascending order.
+//
+// Complexity: O(n) worst-case
+func (s *GSet[T]) ToSlice() []T {
+	ret := make([]T, 0, s.Len())
+	s.ForEach(func(value T) bool {
+		ret = append(ret, value)
+		return true
+	})
+	return ret
+}
@@ -0,0 +1,90 @@
+package immutable
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type crdtTestValue struct {
+	value     string
+	timestamp int64
+}
+
+func crdtTestTimestamp(v crdtTestValue) int64 {
+	return v.timestamp
+}
+
+func TestOrderedMap_MergeLWW(t *testing.T) {
+	a := OrderedMapOf(
+		MakePair("x", crdtTestValue{"a", 1}),
+		MakePair("y", crdtTestValue{"a", 4}),
+	)
+	b := OrderedMapOf(
+		MakePair("x", crdtTestValue{"b", 2}),
+		MakePair("y", crdtTestValue{"b", 3}),
+		MakePair("z", crdtTestValue{"b", 1}),
+	)
+	expected := map[string]crdtTestValue{
+		"x": {"b", 2},
+		"y": {"a", 4},
+		"z": {"b", 1},
+	}
+	assert.Equal(t, expected, a.MergeLWW(b, crdtTestTimestamp).ToMap())
+	assert.Equal(t, expected, b.MergeLWW(a, crdtTestTimestamp).ToMap())
+	assert.Same(t, a, a.MergeLWW(a, crdtTestTimestamp))
+	assert.Same(t, a, a.MergeLWW(nil, crdtTestTimestamp))
+	assert.Same(t, a, (*OrderedMap[string, crdtTestValue])(nil).MergeLWW(a, crdtTestTimestamp))
+
+	// Ties keep the receiver's value regardless of which map is smaller.
+	c := OrderedMapOf(MakePair("x", crdtTestValue{"c", 1}))
+	v, _ := a.MergeLWW(c, crdtTestTimestamp).Get("x")
+	assert.Equal(t, "a", v.value)
+	v, _ = c.MergeLWW(a, crdtTestTimestamp).Get("x")
+	assert.Equal(t, "c", v.value)
+}
+
+func TestOrderedMap_MergeLWW_Convergence(t *testing.T) {
+	// Each replica writes with unique timestamps, then they merge in different orders.
+	replicas := make([]*OrderedMap[int, crdtTestValue], 3)
+	for i := 0; i < 300; i++ {
+		r := i % len(replicas)
+		replicas[r] = replicas[r].Set(rand.Intn(50), crdtTestValue{timestamp: int64(i)})
+	}
+	forward := replicas[0].MergeLWW(replicas[1], crdtTestTimestamp).MergeLWW(replicas[2], crdtTestTimestamp)
+	backward := replicas[2].MergeLWW(replicas[1].MergeLWW(replicas[0], crdtTestTimestamp), crdtTestTimestamp)
+	require.NoError(t, forward.CheckInvariants())
+	assert.Equal(t, forward.ToMap(), backward.ToMap())
+	assert.Equal(t, forward.ToMap(), forward.MergeLWW(replicas[1], crdtTestTimestamp).ToMap())
+}
+
+func TestGSet(t *testing.T) {
+	var s *GSet[int]
+	assert.True(t, s.Empty())
+	assert.Equal(t, 0, s.Len())
+	assert.False(t, s.Contains(1))
+	assert.Equal(t, []int{}, s.ToSlice())
+	assert.Equal(t, 1, (&GSet[int]{}).Add(1).Len())
+
+	a := s.Add(3).Add(1)
+	assert.Same(t, a, a.Add(3))
+	b := s.Add(2).Add(3).Add(4).Add(5)
+
+	ab := a.Merge(b)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, ab.ToSlice())
+	assert.Equal(t, ab.ToSlice(), b.Merge(a).ToSlice())
+	assert.Same(t, ab, ab.Merge(a))
+	assert.Equal(t, []int{1, 3}, a.ToSlice())
+	assert.True(t, ab.Contains(4))
+	assert.False(t, ab.Empty())
+	assert.Same(t, a, a.Merge(nil))
+
+	n := 0
+	ab.ForEach(func(value int) bool {
+		n++
+		return n < 2
+	})
+	assert.Equal(t, 2, n)
+}
@@ -0,0 +1,92 @@
+package immutable
+
+import (
+	"encoding/json"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Cursor records a position in an ordered map as the key of the last element visited, rather than
+// as pointers into a particular version of the map. This means it can be serialized, such as in a
+// pagination token or a job's checkpoint, and later resumed against any version of the map with
+// OrderedMap.ResumeAfter, even after a process restart.
+//
+// A cursor which has visited an element with the zero key is distinct from one which hasn't visited
+// any elements yet.
+//
+// The zero value for Cursor is positioned before the first element.
+type Cursor[K constraints.Ordered] struct {
+	key     K
+	started bool
+}
+
+// CursorAfter returns a cursor positioned after the given key.
+//
+// Complexity: O(1) worst-case
+func CursorAfter[K constraints.Ordered](key K) Cursor[K] {
+	return Cursor[K]{
+		key:     key,
+		started: true,
+	}
+}
+
+// Key returns the key the cursor is positioned after, or false if it's positioned before the first
+// element.
+//
+// Complexity: O(1) worst-case
+func (c Cursor[K]) Key() (K, bool) {
+	return c.key, c.started
+}
+
+type cursorJSON[K constraints.Ordered] struct {
+	After *K `json:"after,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. The cursor is encoded as an object with the key in its
+// "after" field, which is omitted if the cursor is positioned before the first element.
+//
+// Complexity: O(1) worst-case
+func (c Cursor[K]) MarshalJSON() ([]byte, error) {
+	var v cursorJSON[K]
+	if c.started {
+		v.After = &c.key
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+//
+// Complexity: O(1) worst-case
+func (c *Cursor[K]) UnmarshalJSON(data []byte) error {
+	var v cursorJSON[K]
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*c = Cursor[K]{}
+	if v.After != nil {
+		*c = CursorAfter(*v.After)
+	}
+	return nil
+}
+
+// ResumeAfter returns an iterator positioned at the given cursor, so that the next call to Next
+// advances it to the minimum element with a key greater than the cursor's. The map doesn't need to
+// be the version the cursor was created from: if the element the cursor is positioned after has
+// since been deleted, iteration resumes with the next remaining one.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) ResumeAfter(c Cursor[K]) *OrderedMapIterator[K, V] {
+	it := m.Iterator()
+	if key, ok := c.Key(); ok {
+		it.seekAfter(key)
+	}
+	return it
+}
+
+// Cursor returns a cursor positioned after the current element. It must only be called after Next
+// returns true.
+//
+// Complexity: O(1) worst-case
+func (it *OrderedMapIterator[K, V]) Cursor() Cursor[K] {
+	return CursorAfter(it.current.key)
+}
@@ -0,0 +1,74 @@
+package immutable
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	var c Cursor[int]
+	_, ok := c.Key()
+	assert.False(t, ok)
+
+	c = CursorAfter(0)
+	key, ok := c.Key()
+	assert.True(t, ok)
+	assert.Equal(t, 0, key)
+
+	for _, c := range []Cursor[int]{{}, CursorAfter(0), CursorAfter(7)} {
+		data, err := json.Marshal(c)
+		require.NoError(t, err)
+		var decoded Cursor[int]
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, c, decoded)
+	}
+
+	data, err := json.Marshal(Cursor[string]{})
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(data))
+	data, err = json.Marshal(CursorAfter(""))
+	require.NoError(t, err)
+	assert.Equal(t, `{"after":""}`, string(data))
+
+	decoded := CursorAfter(1)
+	require.NoError(t, json.Unmarshal([]byte(`null`), &decoded))
+	assert.Equal(t, Cursor[int]{}, decoded)
+	assert.Error(t, json.Unmarshal([]byte(`{"after":"x"}`), &decoded))
+}
+
+func TestOrderedMap_ResumeAfter(t *testing.T) {
+	var m *OrderedMap[int, string]
+	for i := 0; i < 20; i += 2 {
+		m = m.Set(i, "")
+	}
+
+	// Visit a few elements, then serialize the cursor.
+	var keys []int
+	it := m.ResumeAfter(Cursor[int]{})
+	for i := 0; i < 3 && it.Next(); i++ {
+		keys = append(keys, it.Key())
+	}
+	data, err := json.Marshal(it.Cursor())
+	require.NoError(t, err)
+
+	// Resume against a newer version in which the cursor's key was deleted.
+	var c Cursor[int]
+	require.NoError(t, json.Unmarshal(data, &c))
+	m = m.Delete(4).Set(5, "").Set(1, "")
+	for it := m.ResumeAfter(c); it.Next(); {
+		keys = append(keys, it.Key())
+	}
+	assert.Equal(t, []int{0, 2, 4, 5, 6, 8, 10, 12, 14, 16, 18}, keys)
+
+	// The zero key is a valid position.
+	it = m.ResumeAfter(CursorAfter(0))
+	require.True(t, it.Next())
+	assert.Equal(t, 1, it.Key())
+
+	it = m.ResumeAfter(CursorAfter(18))
+	assert.False(t, it.Next())
+	assert.False(t, (*OrderedMap[int, string])(nil).ResumeAfter(CursorAfter(1)).Next())
+}
@@ -0,0 +1,77 @@
+package immutable
+
+import "golang.org/x/exp/constraints"
+
+// DerivedOrderedMap maintains a map derived from a source map by transforming and filtering its
+// elements. When the source changes, the derived map is updated incrementally using the difference
+// between the old and new versions of the source, rather than being recomputed from scratch. This
+// is useful for state stores which maintain views of their state.
+//
+// DerivedOrderedMap must be created with NewDerivedOrderedMap.
+type DerivedOrderedMap[K constraints.Ordered, V, U any] struct {
+	source  *OrderedMap[K, V]
+	derived *OrderedMap[K, U]
+	f       func(key K, value V) (U, bool)
+}
+
+// NewDerivedOrderedMap returns a derived map containing the result of calling f for each element of
+// the source. Elements for which f returns false are omitted. f must be a pure function of its
+// arguments.
+//
+// Complexity: O(n) worst-case, not including the cost of f
+func NewDerivedOrderedMap[K constraints.Ordered, V, U any](source *OrderedMap[K, V], f func(key K, value V) (U, bool)) *DerivedOrderedMap[K, V, U] {
+	var b OrderedMapBuilder[K, U]
+	for e := source.Min(); e != nil; e = e.Next() {
+		if u, ok := f(e.Key(), e.Value()); ok {
+			b.Set(e.Key(), u)
+		}
+	}
+	return &DerivedOrderedMap[K, V, U]{
+		source:  source,
+		derived: b.Build(),
+		f:       f,
+	}
+}
+
+// Source returns the source map that the derived map currently reflects.
+//
+// Complexity: O(1) worst-case
+func (d *DerivedOrderedMap[K, V, U]) Source() *OrderedMap[K, V] {
+	return d.source
+}
+
+// Map returns the derived map.
+//
+// Complexity: O(1) worst-case
+func (d *DerivedOrderedMap[K, V, U]) Map() *OrderedMap[K, U] {
+	return d.derived
+}
+
+// Update returns a derived map reflecting a new version of the source. f is only called for
+// elements that may have changed, so this is efficient when the new version is derived from the
+// current one.
+//
+// Complexity: O(k log n) worst-case, where k is the number of elements that differ between the
+// versions, not including the cost of f
+func (d *DerivedOrderedMap[K, V, U]) Update(source *OrderedMap[K, V]) *DerivedOrderedMap[K, V, U] {
+	if source == d.source {
+		return d
+	}
+	derived := d.derived
+	// Diff can't compare values of arbitrary types, so every element whose node isn't shared is
+	// treated as changed.
+	d.source.Diff(source, func(a, b V) bool { return false }, func(key K, _ V, _ bool, value V, exists bool) {
+		if exists {
+			if u, ok := d.f(key, value); ok {
+				derived = derived.Set(key, u)
+				return
+			}
+		}
+		derived = derived.Delete(key)
+	})
+	return &DerivedOrderedMap[K, V, U]{
+		source:  source,
+		derived: derived,
+		f:       d.f,
+	}
+}
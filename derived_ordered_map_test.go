@@ -0,0 +1,73 @@
+package immutable
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDerivedOrderedMap(t *testing.T) {
+	var source *OrderedMap[int, int]
+	for i := 0; i < 100; i++ {
+		source = source.Set(i, i)
+	}
+
+	calls := 0
+	d := NewDerivedOrderedMap(source, func(key, value int) (string, bool) {
+		calls++
+		return string(rune('a' + value%26)), value%2 == 0
+	})
+	assert.Equal(t, 100, calls)
+	assert.Equal(t, source, d.Source())
+	assert.Equal(t, 50, d.Map().Len())
+	v, ok := d.Map().Get(28)
+	assert.True(t, ok)
+	assert.Equal(t, "c", v)
+
+	assert.Equal(t, d, d.Update(source))
+
+	calls = 0
+	updated := d.Update(source.Set(28, 1).Set(29, 2).Delete(30).Set(1000, 0))
+	assert.Less(t, calls, 50)
+	assert.Equal(t, 50, d.Map().Len())
+	m := updated.Map()
+	require.NoError(t, m.CheckInvariants())
+	assert.Equal(t, 50, m.Len())
+	_, ok = m.Get(28)
+	assert.False(t, ok)
+	v, ok = m.Get(29)
+	assert.True(t, ok)
+	assert.Equal(t, "c", v)
+	_, ok = m.Get(30)
+	assert.False(t, ok)
+	v, ok = m.Get(1000)
+	assert.True(t, ok)
+	assert.Equal(t, "a", v)
+}
+
+func TestDerivedOrderedMap_Fuzz(t *testing.T) {
+	f := func(key, value int) (int, bool) {
+		return key + value, value%3 != 0
+	}
+	var source *OrderedMap[int, int]
+	d := NewDerivedOrderedMap(source, f)
+	for i := 0; i < 200; i++ {
+		for j := rand.Intn(10); j >= 0; j-- {
+			if rand.Intn(3) == 0 {
+				source = source.Delete(rand.Intn(100))
+			} else {
+				source = source.Set(rand.Intn(100), rand.Intn(100))
+			}
+		}
+		d = d.Update(source)
+		expected := NewDerivedOrderedMap(source, f).Map()
+		require.Equal(t, expected.Len(), d.Map().Len())
+		for e := expected.Min(); e != nil; e = e.Next() {
+			v, ok := d.Map().Get(e.Key())
+			require.True(t, ok)
+			require.Equal(t, e.Value(), v)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package immutable
+
+import "golang.org/x/exp/constraints"
+
+// DescendingOrderedMap implements an ordered map that orders its keys from greatest to least.
+//
+// It shares its representation with OrderedMap, so conversions between the two are free. This is
+// useful when the natural order can't be inverted by transforming keys, as is the case for strings.
+//
+// Nil and the zero value for DescendingOrderedMap are both empty maps.
+type DescendingOrderedMap[K constraints.Ordered, V any] struct {
+	m *OrderedMap[K, V]
+}
+
+// Descending returns a view of the map which orders its keys from greatest to least.
+//
+// Complexity: O(1) worst-case
+func (m *OrderedMap[K, V]) Descending() *DescendingOrderedMap[K, V] {
+	return &DescendingOrderedMap[K, V]{
+		m: m,
+	}
+}
+
+// Ascending returns a view of the map which orders its keys from least to greatest.
+//
+// Complexity: O(1) worst-case
+func (m *DescendingOrderedMap[K, V]) Ascending() *OrderedMap[K, V] {
+	if m == nil {
+		return nil
+	}
+	return m.m
+}
+
+// Empty returns true if the map is empty.
+//
+// Complexity: O(1) worst-case
+func (m *DescendingOrderedMap[K, V]) Empty() bool {
+	return m.Ascending().Empty()
+}
+
+// Len returns the number of elements in the map.
+//
+// Complexity: O(1) worst-case
+func (m *DescendingOrderedMap[K, V]) Len() int {
+	return m.Ascending().Len()
+}
+
+// Get returns the value associated with the given key if set.
+//
+// Complexity: O(log n) worst-case
+func (m *DescendingOrderedMap[K, V]) Get(key K) (V, bool) {
+	return m.Ascending().Get(key)
+}
+
+// Set associates a value with the given key.
+//
+// Complexity: O(log n) worst-case
+func (m *DescendingOrderedMap[K, V]) Set(key K, value V) *DescendingOrderedMap[K, V] {
+	return m.Ascending().Set(key, value).Descending()
+}
+
+// Delete removes a key from the map.
+//
+// Complexity: O(log n) worst-case
+func (m *DescendingOrderedMap[K, V]) Delete(key K) *DescendingOrderedMap[K, V] {
+	return m.Ascending().Delete(key).Descending()
+}
+
+// Min returns the first element in the map, which is the one with the greatest key.
+//
+// Complexity: O(log n) worst-case
+func (m *DescendingOrderedMap[K, V]) Min() *DescendingOrderedMapElement[K, V] {
+	return newDescendingOrderedMapElement(m.Ascending().Max())
+}
+
+// Max returns the last element in the map, which is the one with the least key.
+//
+// Complexity: O(log n) worst-case
+func (m *DescendingOrderedMap[K, V]) Max() *DescendingOrderedMapElement[K, V] {
+	return newDescendingOrderedMapElement(m.Ascending().Min())
+}
+
+// MinAfter returns the first element in the map that comes after the given key, which is the one
+// with the greatest key less than it.
+//
+// Complexity: O(log n) worst-case
+func (m *DescendingOrderedMap[K, V]) MinAfter(key K) *DescendingOrderedMapElement[K, V] {
+	return newDescendingOrderedMapElement(m.Ascending().MaxBefore(key))
+}
+
+// MaxBefore returns the last element in the map that comes before the given key, which is the one
+// with the least key greater than it.
+//
+// Complexity: O(log n) worst-case
+func (m *DescendingOrderedMap[K, V]) MaxBefore(key K) *DescendingOrderedMapElement[K, V] {
+	return newDescendingOrderedMapElement(m.Ascending().MinAfter(key))
+}
+
+// MinAtLeast returns the first element in the map that doesn't come before the given key, which is
+// the one with the greatest key less than or equal to it.
+//
+// Complexity: O(log n) worst-case
+func (m *DescendingOrderedMap[K, V]) MinAtLeast(key K) *DescendingOrderedMapElement[K, V] {
+	return newDescendingOrderedMapElement(m.Ascending().MaxAtMost(key))
+}
+
+// MaxAtMost returns the last element in the map that doesn't come after the given key, which is the
+// one with the least key greater than or equal to it.
+//
+// Complexity: O(log n) worst-case
+func (m *DescendingOrderedMap[K, V]) MaxAtMost(key K) *DescendingOrderedMapElement[K, V] {
+	return newDescendingOrderedMapElement(m.Ascending().MinAtLeast(key))
+}
+
+// DescendingOrderedMapElement represents a key-value pair and can be used to iterate over elements
+// in a map from greatest to least key.
+type DescendingOrderedMapElement[K constraints.Ordered, V any] struct {
+	e *OrderedMapElement[K, V]
+}
+
+func newDescendingOrderedMapElement[K constraints.Ordered, V any](e *OrderedMapElement[K, V]) *DescendingOrderedMapElement[K, V] {
+	if e == nil {
+		return nil
+	}
+	return &DescendingOrderedMapElement[K, V]{
+		e: e,
+	}
+}
+
+// Key returns the key of the represented element.
+func (e *DescendingOrderedMapElement[K, V]) Key() K {
+	return e.e.Key()
+}
+
+// Value returns the value of the represented element.
+func (e *DescendingOrderedMapElement[K, V]) Value() V {
+	return e.e.Value()
+}
+
+// Next returns the next element in the map, which is the one with the next lesser key.
+//
+// Complexity: O(log n) worst-case, amortized O(1) if iterating over the entire map
+func (e *DescendingOrderedMapElement[K, V]) Next() *DescendingOrderedMapElement[K, V] {
+	return newDescendingOrderedMapElement(e.e.Prev())
+}
+
+// Prev returns the previous element in the map, which is the one with the next greater key.
+//
+// Complexity: O(log n) worst-case, amortized O(1) if iterating over the entire map
+func (e *DescendingOrderedMapElement[K, V]) Prev() *DescendingOrderedMapElement[K, V] {
+	return newDescendingOrderedMapElement(e.e.Next())
+}
+
+// CountLess returns the number of elements that come before this element.
+//
+// Complexity: O(log n) worst-case
+func (e *DescendingOrderedMapElement[K, V]) CountLess() int {
+	return e.e.CountGreater()
+}
+
+// CountGreater returns the number of elements that come after this element.
+//
+// Complexity: O(log n) worst-case
+func (e *DescendingOrderedMapElement[K, V]) CountGreater() int {
+	return e.e.CountLess()
+}
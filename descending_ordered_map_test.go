@@ -0,0 +1,64 @@
+package immutable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescendingOrderedMap(t *testing.T) {
+	var m *DescendingOrderedMap[string, int]
+	assert.True(t, m.Empty())
+	assert.Equal(t, 0, m.Len())
+	assert.Nil(t, m.Min())
+	assert.Nil(t, m.Max())
+
+	m = m.Set("b", 2).Set("a", 1).Set("c", 3).Set("d", 4)
+	assert.False(t, m.Empty())
+	assert.Equal(t, 4, m.Len())
+
+	v, ok := m.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+
+	var keys []string
+	for e := m.Min(); e != nil; e = e.Next() {
+		keys = append(keys, e.Key())
+	}
+	assert.Equal(t, []string{"d", "c", "b", "a"}, keys)
+
+	keys = nil
+	for e := m.Max(); e != nil; e = e.Prev() {
+		keys = append(keys, e.Key())
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d"}, keys)
+
+	e := m.MinAfter("c")
+	require.NotNil(t, e)
+	assert.Equal(t, "b", e.Key())
+	assert.Equal(t, 2, e.Value())
+	assert.Equal(t, 2, e.CountLess())
+	assert.Equal(t, 1, e.CountGreater())
+	assert.Nil(t, m.MinAfter("a"))
+
+	e = m.MaxBefore("c")
+	require.NotNil(t, e)
+	assert.Equal(t, "d", e.Key())
+	assert.Nil(t, m.MaxBefore("d"))
+
+	e = m.MinAtLeast("c")
+	require.NotNil(t, e)
+	assert.Equal(t, "c", e.Key())
+	assert.Equal(t, "b", e.Next().Key())
+	assert.Nil(t, m.MinAtLeast("0"))
+
+	e = m.MaxAtMost("bb")
+	require.NotNil(t, e)
+	assert.Equal(t, "c", e.Key())
+	assert.Nil(t, m.MaxAtMost("e"))
+
+	m = m.Delete("d")
+	assert.Equal(t, "c", m.Min().Key())
+	assert.Equal(t, "a", m.Ascending().Min().Key())
+}
@@ -0,0 +1,50 @@
+package immutable
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DumpDOT writes the map's tree to w in the Graphviz DOT language, for debugging and for
+// understanding the shape of a particular map. Each node is labeled with its key and the number of
+// elements in its subtree, and is filled with its color. The output can be rendered with tools
+// such as dot:
+//
+//	dot -Tsvg map.dot > map.svg
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMap[K, V]) DumpDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph OrderedMap {\n")
+	b.WriteString("\tnode [shape=box, style=filled, fontcolor=white];\n")
+	next := 0
+	m.dumpDOT(&b, &next)
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dumpDOT writes the subtree's nodes and edges, returning the subtree's node ID, or -1 if it's
+// empty.
+func (m *OrderedMap[K, V]) dumpDOT(b *strings.Builder, next *int) int {
+	if m.Empty() {
+		return -1
+	}
+	id := *next
+	*next++
+	color := "black"
+	if m.color() == orderedMapRed {
+		color = "red"
+	}
+	fmt.Fprintf(b, "\tn%d [label=%q, fillcolor=%s];\n", id, fmt.Sprintf("%v\nlen=%d", m.key, m.size()), color)
+	for _, child := range []struct {
+		m     *OrderedMap[K, V]
+		label string
+	}{{m.left, "L"}, {m.right, "R"}} {
+		if childID := child.m.dumpDOT(b, next); childID >= 0 {
+			fmt.Fprintf(b, "\tn%d -> n%d [label=%s];\n", id, childID, child.label)
+		}
+	}
+	return id
+}
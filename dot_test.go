@@ -0,0 +1,39 @@
+package immutable
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type dotTestErrorWriter struct{}
+
+func (dotTestErrorWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestOrderedMap_DumpDOT(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, (*OrderedMap[int, int])(nil).DumpDOT(&b))
+	assert.Equal(t, "digraph OrderedMap {\n\tnode [shape=box, style=filled, fontcolor=white];\n}\n", b.String())
+
+	m := OrderedMapOf(MakePair("b", 0), MakePair("a", 0), MakePair("c\"", 0), MakePair("d", 0))
+	b.Reset()
+	require.NoError(t, m.DumpDOT(&b))
+	assert.Equal(t, `digraph OrderedMap {
+	node [shape=box, style=filled, fontcolor=white];
+	n0 [label="c\"\nlen=4", fillcolor=black];
+	n1 [label="b\nlen=2", fillcolor=black];
+	n2 [label="a\nlen=1", fillcolor=red];
+	n1 -> n2 [label=L];
+	n0 -> n1 [label=L];
+	n3 [label="d\nlen=1", fillcolor=black];
+	n0 -> n3 [label=R];
+}
+`, b.String())
+
+	assert.Error(t, m.DumpDOT(dotTestErrorWriter{}))
+}
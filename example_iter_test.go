@@ -0,0 +1,24 @@
+//go:build go1.23
+
+package immutable_test
+
+import (
+	"fmt"
+
+	"github.com/ccbrown/go-immutable"
+)
+
+func ExampleOrderedMap_All() {
+	m := immutable.OrderedMapOf(
+		immutable.MakePair("c", 3),
+		immutable.MakePair("a", 1),
+		immutable.MakePair("b", 2),
+	)
+	for k, v := range m.All() {
+		fmt.Println(k, v)
+	}
+	// Output:
+	// a 1
+	// b 2
+	// c 3
+}
@@ -0,0 +1,203 @@
+package immutable_test
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ccbrown/go-immutable"
+)
+
+// Nil is a valid, empty container, so there's no need to construct one before using it.
+func Example_nilContainers() {
+	var m *immutable.OrderedMap[string, int]
+	var q *immutable.Queue[string]
+	var s *immutable.Stack[string]
+	fmt.Println(m.Len(), q.Empty(), s.Empty())
+
+	m = m.Set("a", 1)
+	q = q.PushBack("a")
+	s = s.Push("a")
+	fmt.Println(m.Len(), q.Front(), s.Peek())
+	// Output:
+	// 0 true true
+	// 1 a a
+}
+
+// Set returns a new map, leaving the original unchanged. The result must be assigned to be used.
+func ExampleOrderedMap_Set() {
+	var v1 *immutable.OrderedMap[string, int]
+	v1 = v1.Set("a", 1)
+	v2 := v1.Set("b", 2)
+
+	fmt.Println(v1.Len(), v2.Len())
+	_, ok := v1.Get("b")
+	fmt.Println(ok)
+	// Output:
+	// 1 2
+	// false
+}
+
+func ExampleOrderedMap_Iterator() {
+	var m *immutable.OrderedMap[int, string]
+	for i := 0; i < 10; i++ {
+		m = m.Set(i*10, fmt.Sprint(i))
+	}
+	it := m.Iterator()
+	it.Seek(35)
+	for i := 0; i < 3 && it.Next(); i++ {
+		fmt.Println(it.Key(), it.Value())
+	}
+	// Output:
+	// 40 4
+	// 50 5
+	// 60 6
+}
+
+func ExampleOrderedMap_ResumeAfter() {
+	var m *immutable.OrderedMap[string, int]
+	for i, k := range []string{"a", "b", "c", "d", "e"} {
+		m = m.Set(k, i)
+	}
+
+	var cursor immutable.Cursor[string]
+	for {
+		var page []string
+		it := m.ResumeAfter(cursor)
+		for len(page) < 2 && it.Next() {
+			page = append(page, it.Key())
+			cursor = it.Cursor()
+		}
+		if len(page) == 0 {
+			break
+		}
+		fmt.Println(page)
+	}
+	// Output:
+	// [a b]
+	// [c d]
+	// [e]
+}
+
+func ExampleQueue() {
+	var q *immutable.Queue[string]
+	q = q.PushBack("a").PushBack("b")
+	afterPop := q.PopFront()
+
+	fmt.Println(q.Front(), afterPop.Front())
+	fmt.Println(q.ToSlice(), afterPop.ToSlice())
+	// Output:
+	// a b
+	// [a b] [b]
+}
+
+func ExampleQueue_Split() {
+	q := immutable.QueueOf(1, 2, 3, 4, 5)
+	batch, rest := q.Split(2)
+	fmt.Println(batch.ToSlice(), rest.ToSlice())
+	// Output:
+	// [1 2] [3 4 5]
+}
+
+func ExampleStack() {
+	var s *immutable.Stack[string]
+	s = s.Push("a").Push("b")
+	for !s.Empty() {
+		fmt.Println(s.Peek())
+		s = s.Pop()
+	}
+	// Output:
+	// b
+	// a
+}
+
+// Ref allows persistent containers to be shared between goroutines. Readers simply load the
+// current version, which never changes underneath them.
+func ExampleRef() {
+	var counts immutable.Ref[*immutable.OrderedMap[string, int]]
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counts.Update(func(m *immutable.OrderedMap[string, int]) *immutable.OrderedMap[string, int] {
+				n, _ := m.Get("hits")
+				return m.Set("hits", n+1)
+			})
+		}()
+	}
+	wg.Wait()
+
+	n, _ := counts.Load().Get("hits")
+	fmt.Println(n)
+	// Output:
+	// 10
+}
+
+func ExampleRef_Begin() {
+	type state struct {
+		pending *immutable.Queue[string]
+		done    *immutable.Stack[string]
+	}
+	ref := immutable.NewRef(state{
+		pending: immutable.QueueOf("a", "b"),
+	})
+
+	// Move an item from one container to the other, with no intermediate state visible to readers.
+	for {
+		txn := ref.Begin()
+		item := txn.State.pending.Front()
+		txn.State.pending = txn.State.pending.PopFront()
+		txn.State.done = txn.State.done.Push(item)
+		if txn.Commit() {
+			break
+		}
+	}
+
+	s := ref.Load()
+	fmt.Println(s.pending.ToSlice(), s.done.ToSlice())
+	// Output:
+	// [b] [a]
+}
+
+func ExampleOrderedMultimap() {
+	var m *immutable.OrderedMultimap[string, int]
+	m = m.Set("b", 1).Set("a", 2).Set("b", 3)
+	m.ForEach(func(k string, v int) bool {
+		fmt.Println(k, v)
+		return true
+	})
+	fmt.Println(m.Len(), m.Keys())
+	// Output:
+	// a 2
+	// b 1
+	// b 3
+	// 3 2
+}
+
+func ExampleRing() {
+	r := immutable.NewRing[int](3)
+	for i := 1; i <= 5; i++ {
+		r = r.Push(i)
+	}
+	fmt.Println(r.ToSlice())
+	// Output:
+	// [3 4 5]
+}
+
+func ExampleTreap_Union() {
+	var a, b *immutable.Treap[string, int]
+	a = a.Set("x", 1).Set("y", 2)
+	b = b.Set("y", 3).Set("z", 4)
+	sum := a.Union(b, func(key string, a, b int) int {
+		return a + b
+	})
+	sum.ForEach(func(key string, value int) bool {
+		fmt.Println(key, value)
+		return true
+	})
+	// Output:
+	// x 1
+	// y 5
+	// z 4
+}
@@ -0,0 +1,194 @@
+package immutable
+
+import "fmt"
+
+// FairQueue implements a first in, first out container which divides its items into sub-queues by
+// key and pops from the sub-queues in round-robin order. Items with the same key are popped in the
+// order they were pushed, but no key can starve the others, no matter how many items it has
+// pushed. This is useful for schedulers which must share work fairly between tenants or
+// connections.
+//
+// Keys can be any comparable type. A key takes its turn in the rotation from the time its
+// first item is pushed until its sub-queue is empty.
+//
+// Nil and the zero value for FairQueue are both empty queues.
+type FairQueue[K comparable, T any] struct {
+	// queues contains the sub-queue of each key with at least one item.
+	queues *hashMap[K, *Queue[T]]
+
+	// order contains each key of queues, starting with the one whose turn is next.
+	order *Queue[K]
+
+	len int
+}
+
+// Empty returns true if the queue is empty.
+//
+// Complexity: O(1) worst-case
+func (q *FairQueue[K, T]) Empty() bool {
+	return q.Len() == 0
+}
+
+// Len returns the number of items in the queue, across all keys.
+//
+// Complexity: O(1) worst-case
+func (q *FairQueue[K, T]) Len() int {
+	if q == nil {
+		return 0
+	}
+	return q.len
+}
+
+// Keys returns the number of keys which have at least one item in the queue.
+//
+// Complexity: O(1) worst-case
+func (q *FairQueue[K, T]) Keys() int {
+	if q == nil {
+		return 0
+	}
+	return q.queues.Len()
+}
+
+// KeyQueue returns the sub-queue of items with the given key.
+//
+// Complexity: O(log k) expected, where k is the number of keys
+func (q *FairQueue[K, T]) KeyQueue(key K) *Queue[T] {
+	if q == nil {
+		return nil
+	}
+	ret, _ := q.queues.Get(key)
+	return ret
+}
+
+// PushBack pushes an item onto the back of the sub-queue for the given key. If the key had no
+// items, it takes the last turn in the rotation. Like OrderedMap.Set, it panics if the key is NaN.
+//
+// Complexity: O(log k) expected, where k is the number of keys
+func (q *FairQueue[K, T]) PushBack(key K, value T) *FairQueue[K, T] {
+	var ret FairQueue[K, T]
+	if q != nil {
+		ret = *q
+	}
+	sub, ok := ret.queues.Get(key)
+	if !ok {
+		ret.order = ret.order.PushBack(key)
+	}
+	ret.queues = ret.queues.Set(key, sub.PushBack(value))
+	ret.len++
+	return &ret
+}
+
+// Front returns the item which would be popped next, along with its key. It panics if the queue is
+// empty.
+//
+// Complexity: O(log k) expected, where k is the number of keys
+func (q *FairQueue[K, T]) Front() (K, T) {
+	if q.Empty() {
+		panic("immutable: FairQueue is empty")
+	}
+	key := q.order.Front()
+	sub, _ := q.queues.Get(key)
+	return key, sub.Front()
+}
+
+// TryFront returns the item which would be popped next, along with its key, or false if the queue
+// is empty.
+//
+// Complexity: O(log k) expected, where k is the number of keys
+func (q *FairQueue[K, T]) TryFront() (K, T, bool) {
+	if q.Empty() {
+		var zeroKey K
+		var zero T
+		return zeroKey, zero, false
+	}
+	key, value := q.Front()
+	return key, value, true
+}
+
+// PopFront removes the front item of the sub-queue whose key has the current turn, and passes the
+// turn to the next key. It panics if the queue is empty.
+//
+// Complexity: O(log k) expected, where k is the number of keys
+func (q *FairQueue[K, T]) PopFront() *FairQueue[K, T] {
+	if q.Empty() {
+		panic("immutable: FairQueue is empty")
+	}
+	ret := *q
+	key := ret.order.Front()
+	ret.order = ret.order.PopFront()
+	sub, _ := ret.queues.Get(key)
+	if sub = sub.PopFront(); sub.Empty() {
+		ret.queues = ret.queues.Delete(key)
+	} else {
+		ret.queues = ret.queues.Set(key, sub)
+		ret.order = ret.order.PushBack(key)
+	}
+	ret.len--
+	return &ret
+}
+
+// DeleteKey removes all items with the given key.
+//
+// Complexity: O(k + m) expected, where k is the number of keys and m is the number of items with
+// the given key
+func (q *FairQueue[K, T]) DeleteKey(key K) *FairQueue[K, T] {
+	sub := q.KeyQueue(key)
+	if sub.Empty() {
+		return q
+	}
+	ret := *q
+	ret.queues = ret.queues.Delete(key)
+	ret.order = nil
+	for _, k := range q.order.ToSlice() {
+		if k != key {
+			ret.order = ret.order.PushBack(k)
+		}
+	}
+	ret.len -= len(sub.ToSlice())
+	return &ret
+}
+
+// CheckInvariants verifies the structural integrity of the queue, returning an error describing the
+// first problem found. It checks that the rotation contains each key with items exactly once, that
+// no sub-queue is empty, and that the recorded length is correct.
+//
+// Complexity: O(n) expected
+func (q *FairQueue[K, T]) CheckInvariants() error {
+	if q == nil {
+		return nil
+	} else if err := q.queues.checkInvariants(); err != nil {
+		return err
+	} else if err := q.order.CheckInvariants(); err != nil {
+		return err
+	}
+	seen := map[K]bool{}
+	for _, key := range q.order.ToSlice() {
+		if seen[key] {
+			return fmt.Errorf("key %v appears more than once in the rotation", key)
+		} else if _, ok := q.queues.Get(key); !ok {
+			return fmt.Errorf("key %v is in the rotation, but has no sub-queue", key)
+		}
+		seen[key] = true
+	}
+	if len(seen) != q.queues.Len() {
+		return fmt.Errorf("rotation has %v keys, but there are %v sub-queues", len(seen), q.queues.Len())
+	}
+	n := 0
+	var err error
+	q.queues.ForEach(func(key K, sub *Queue[T]) bool {
+		if err = sub.CheckInvariants(); err != nil {
+			return false
+		} else if sub.Empty() {
+			err = fmt.Errorf("sub-queue for key %v is empty", key)
+			return false
+		}
+		n += len(sub.ToSlice())
+		return true
+	})
+	if err != nil {
+		return err
+	} else if n != q.len {
+		return fmt.Errorf("queue has %v items, but %v were recorded", n, q.len)
+	}
+	return nil
+}
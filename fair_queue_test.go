@@ -0,0 +1,115 @@
+package immutable
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFairQueue(t *testing.T) {
+	var q *FairQueue[string, int]
+	assert.True(t, q.Empty())
+	assert.Equal(t, 0, q.Len())
+	assert.Equal(t, 0, q.Keys())
+	assert.Nil(t, q.KeyQueue("a"))
+	assert.Same(t, q, q.DeleteKey("a"))
+	_, _, ok := q.TryFront()
+	assert.False(t, ok)
+	assert.Panics(t, func() {
+		q.Front()
+	})
+	assert.Panics(t, func() {
+		q.PopFront()
+	})
+
+	// A busy key doesn't starve the others.
+	for i := 0; i < 5; i++ {
+		q = q.PushBack("a", i)
+	}
+	q = q.PushBack("b", 10).PushBack("c", 20).PushBack("b", 11)
+	assert.Equal(t, 8, q.Len())
+	assert.Equal(t, 3, q.Keys())
+	assert.Equal(t, []int{10, 11}, q.KeyQueue("b").ToSlice())
+
+	type item struct {
+		key   string
+		value int
+	}
+	var popped []item
+	for p := q; !p.Empty(); p = p.PopFront() {
+		key, value := p.Front()
+		popped = append(popped, item{key, value})
+	}
+	assert.Equal(t, []item{
+		{"a", 0}, {"b", 10}, {"c", 20}, {"a", 1}, {"b", 11}, {"a", 2}, {"a", 3}, {"a", 4},
+	}, popped)
+
+	// Earlier versions are unaffected.
+	key, value, ok := q.TryFront()
+	assert.True(t, ok)
+	assert.Equal(t, "a", key)
+	assert.Equal(t, 0, value)
+
+	q2 := q.DeleteKey("a")
+	assert.Equal(t, 3, q2.Len())
+	assert.Equal(t, 2, q2.Keys())
+	key, value = q2.Front()
+	assert.Equal(t, "b", key)
+	assert.Equal(t, 10, value)
+	assert.Equal(t, 8, q.Len())
+
+	assert.Panics(t, func() {
+		(*FairQueue[float64, int])(nil).PushBack(math.NaN(), 1)
+	})
+}
+
+func TestFairQueue_Comparable(t *testing.T) {
+	type tenant struct {
+		org  string
+		user int
+	}
+	var q *FairQueue[tenant, string]
+	q = q.PushBack(tenant{"a", 1}, "x").PushBack(tenant{"a", 1}, "y").PushBack(tenant{"a", 2}, "z")
+	assert.Equal(t, 2, q.Keys())
+	assert.Equal(t, []string{"x", "y"}, q.KeyQueue(tenant{"a", 1}).ToSlice())
+	key, value := q.PopFront().Front()
+	assert.Equal(t, tenant{"a", 2}, key)
+	assert.Equal(t, "z", value)
+}
+
+func TestFairQueue_Random(t *testing.T) {
+	var q *FairQueue[int, int]
+	ref := map[int][]int{}
+	var order []int
+	for i := 0; i < 10000; i++ {
+		if rand.Intn(2) == 0 && len(order) > 0 {
+			key, value := q.Front()
+			require.Equal(t, order[0], key)
+			require.Equal(t, ref[key][0], value)
+			q = q.PopFront()
+			order = order[1:]
+			if ref[key] = ref[key][1:]; len(ref[key]) == 0 {
+				delete(ref, key)
+			} else {
+				order = append(order, key)
+			}
+		} else {
+			key := rand.Intn(10)
+			if _, ok := ref[key]; !ok {
+				order = append(order, key)
+			}
+			ref[key] = append(ref[key], i)
+			q = q.PushBack(key, i)
+		}
+		require.NoError(t, q.CheckInvariants())
+		n := 0
+		for _, items := range ref {
+			n += len(items)
+		}
+		require.Equal(t, n, q.Len())
+		require.Equal(t, len(ref), q.Keys())
+	}
+}
@@ -0,0 +1,37 @@
+package immutable
+
+// Fingerprint returns a canonical representation of the map's contents. Maps have equal fingerprints
+// if and only if their keys and values have identical binary encodings, regardless of the order in
+// which elements were set. This allows maps to be used as keys in built-in maps or deduplicated.
+//
+// An error is returned if the keys or values aren't supported by MarshalBinary.
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMap[K, V]) Fingerprint() (string, error) {
+	b, err := m.MarshalBinary()
+	return string(b), err
+}
+
+// Fingerprint returns a canonical representation of the stack's contents. Stacks have equal
+// fingerprints if and only if their items have identical binary encodings and are in the same
+// order. This allows stacks to be used as keys in built-in maps or deduplicated.
+//
+// An error is returned if the items aren't supported by MarshalBinary.
+//
+// Complexity: O(n) worst-case
+func (s *Stack[T]) Fingerprint() (string, error) {
+	b, err := s.MarshalBinary()
+	return string(b), err
+}
+
+// Fingerprint returns a canonical representation of the queue's contents. Queues have equal
+// fingerprints if and only if their items have identical binary encodings and are in the same
+// order. This allows queues to be used as keys in built-in maps or deduplicated.
+//
+// An error is returned if the items aren't supported by MarshalBinary.
+//
+// Complexity: O(n) worst-case
+func (q *Queue[T]) Fingerprint() (string, error) {
+	b, err := q.MarshalBinary()
+	return string(b), err
+}
@@ -0,0 +1,58 @@
+package immutable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedMap_Fingerprint(t *testing.T) {
+	var m *OrderedMap[string, int]
+	a, err := m.Set("a", 1).Set("b", 2).Fingerprint()
+	require.NoError(t, err)
+	b, err := m.Set("b", 2).Set("c", 3).Set("a", 1).Delete("c").Fingerprint()
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	c, err := m.Set("a", 1).Set("b", 3).Fingerprint()
+	require.NoError(t, err)
+	assert.NotEqual(t, a, c)
+
+	_, err = (&OrderedMap[int, []int]{}).Set(1, []int{1}).Fingerprint()
+	assert.Error(t, err)
+}
+
+func TestStack_Fingerprint(t *testing.T) {
+	seen := map[string]int{}
+	paths := []*Stack[int]{
+		StackFromSlice([]int{1, 2, 3}),
+		StackFromSlice([]int{2, 3}).Push(1),
+		StackFromSlice([]int{3, 2, 1}),
+		nil,
+		StackFromSlice([]int{1}).Pop(),
+	}
+	for _, path := range paths {
+		fingerprint, err := path.Fingerprint()
+		require.NoError(t, err)
+		seen[fingerprint]++
+	}
+	assert.Len(t, seen, 3)
+}
+
+func TestQueue_Fingerprint(t *testing.T) {
+	a, err := QueueFromSlice([]int{1, 2, 3}).Fingerprint()
+	require.NoError(t, err)
+	b, err := QueueFromSlice([]int{0, 1, 2}).PopFront().PushBack(3).Fingerprint()
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	c, err := QueueFromSlice([]int{3, 2, 1}).Fingerprint()
+	require.NoError(t, err)
+	assert.NotEqual(t, a, c)
+
+	// Stacks and queues with the same items are distinguishable.
+	s, err := StackFromSlice([]int{1, 2, 3}).Fingerprint()
+	require.NoError(t, err)
+	assert.NotEqual(t, a, s)
+}
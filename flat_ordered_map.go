@@ -0,0 +1,234 @@
+package immutable
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+const binaryKindFlatOrderedMap = 'f'
+
+// The flat encoding consists of a version byte, a byte identifying the kind of container, and the
+// number of elements as a uvarint, like the binary encoding. This is followed by a table of n+1
+// offsets, each a little-endian uint64, and then the elements encoded in ascending key order as in
+// the binary encoding. Element i occupies the bytes from offset i to offset i+1, relative to the
+// start of the elements.
+
+// MarshalFlat returns an encoding of the map which can be opened with OpenFlatOrderedMap to perform
+// lookups without decoding the entire map.
+//
+// Keys and values must be booleans, numbers, strings, byte slices, or implement
+// encoding.BinaryMarshaler.
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMap[K, V]) MarshalFlat() ([]byte, error) {
+	return m.AppendFlat(nil)
+}
+
+// AppendFlat appends the flat encoding of the map to b. See MarshalFlat.
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMap[K, V]) AppendFlat(b []byte) ([]byte, error) {
+	n := m.Len()
+	b = appendBinaryHeader(b, binaryKindFlatOrderedMap, n)
+	table := len(b)
+	b = append(b, make([]byte, 8*(n+1))...)
+	start := len(b)
+	i := 0
+	var err error
+	for e := m.Min(); e != nil; e = e.Next() {
+		binary.LittleEndian.PutUint64(b[table+8*i:], uint64(len(b)-start))
+		if b, err = appendBinaryValue(b, e.Key()); err != nil {
+			return nil, err
+		}
+		if b, err = appendBinaryValue(b, e.Value()); err != nil {
+			return nil, err
+		}
+		i++
+	}
+	binary.LittleEndian.PutUint64(b[table+8*n:], uint64(len(b)-start))
+	return b, nil
+}
+
+// FlatOrderedMap is a read-only view of a map encoded by OrderedMap.MarshalFlat. Lookups
+// binary-search the encoded data directly, decoding only the keys they visit, so large static
+// datasets can be opened without allocating any tree nodes. The data may be memory-mapped.
+//
+// Opening a map only verifies the structure of the offset table. If the data comes from an
+// untrusted source, it should be opened with OpenFlatOrderedMapStrict, or CheckInvariants should be
+// used to verify the elements before performing any other operations, which otherwise panic if they
+// encounter undecodable data.
+//
+// Nil and the zero value for FlatOrderedMap are both empty maps.
+type FlatOrderedMap[K constraints.Ordered, V any] struct {
+	n        int
+	offsets  []byte
+	elements []byte
+}
+
+// OpenFlatOrderedMap returns a view of the given flat encoding. The data is not copied and must not
+// be modified while the view is in use.
+//
+// Complexity: O(n) worst-case
+func OpenFlatOrderedMap[K constraints.Ordered, V any](data []byte) (*FlatOrderedMap[K, V], error) {
+	n, data, err := decodeBinaryHeader(data, binaryKindFlatOrderedMap)
+	if err != nil {
+		return nil, err
+	} else if uint64(len(data))/8 < uint64(n)+1 {
+		return nil, errBinaryTruncated
+	}
+	m := &FlatOrderedMap[K, V]{
+		n:        n,
+		offsets:  data[:8*(n+1)],
+		elements: data[8*(n+1):],
+	}
+	// Every element occupies at least one byte, so the offsets must be strictly increasing.
+	prev := uint64(0)
+	for i := 0; i <= n; i++ {
+		offset := binary.LittleEndian.Uint64(m.offsets[8*i:])
+		if (i == 0 && offset != 0) || (i > 0 && offset <= prev) {
+			return nil, fmt.Errorf("immutable: invalid offset %v at index %v", offset, i)
+		}
+		prev = offset
+	}
+	if prev != uint64(len(m.elements)) {
+		return nil, fmt.Errorf("immutable: %v bytes of trailing data", uint64(len(m.elements))-prev)
+	}
+	return m, nil
+}
+
+// OpenFlatOrderedMapStrict is like OpenFlatOrderedMap, but also returns a descriptive error if the
+// data isn't exactly what MarshalFlat would produce for some map. In addition to the checks made by
+// CheckInvariants, each key and value must be encoded exactly as MarshalFlat would encode it, such
+// as without redundant varint bytes, so no two distinct inputs open to the same map.
+//
+// Complexity: O(n) worst-case
+func OpenFlatOrderedMapStrict[K constraints.Ordered, V any](data []byte) (*FlatOrderedMap[K, V], error) {
+	m, err := OpenFlatOrderedMap[K, V](data)
+	if err != nil {
+		return nil, err
+	} else if err := m.checkElements(true); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Len returns the number of elements in the map.
+//
+// Complexity: O(1) worst-case
+func (m *FlatOrderedMap[K, V]) Len() int {
+	if m == nil {
+		return 0
+	}
+	return m.n
+}
+
+// element returns the key at index i along with the encoded value.
+func (m *FlatOrderedMap[K, V]) element(i int) (K, []byte) {
+	start := binary.LittleEndian.Uint64(m.offsets[8*i:])
+	end := binary.LittleEndian.Uint64(m.offsets[8*(i+1):])
+	var key K
+	rest, err := decodeBinaryValue(m.elements[start:end], &key)
+	if err != nil {
+		panic(fmt.Sprintf("immutable: invalid flat map element at index %v: %v", i, err))
+	}
+	return key, rest
+}
+
+func (m *FlatOrderedMap[K, V]) decodeValue(i int, data []byte) V {
+	var value V
+	if _, err := decodeBinaryValue(data, &value); err != nil {
+		panic(fmt.Sprintf("immutable: invalid flat map element at index %v: %v", i, err))
+	}
+	return value
+}
+
+// Get returns the value associated with the given key if set.
+//
+// Complexity: O(log n) worst-case
+func (m *FlatOrderedMap[K, V]) Get(key K) (V, bool) {
+	lo, hi := 0, m.Len()
+	for key == key && lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		k, rest := m.element(mid)
+		if key < k {
+			hi = mid
+		} else if k < key {
+			lo = mid + 1
+		} else {
+			return m.decodeValue(mid, rest), true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// At returns the key and value of the element at the given index in ascending key order. It panics
+// if the index is out of range.
+//
+// Complexity: O(1) worst-case
+func (m *FlatOrderedMap[K, V]) At(i int) (K, V) {
+	if i < 0 || i >= m.Len() {
+		panic("immutable: FlatOrderedMap index out of range")
+	}
+	key, rest := m.element(i)
+	return key, m.decodeValue(i, rest)
+}
+
+// ForEach calls f for each element in ascending key order, stopping early if f returns false.
+//
+// Complexity: O(n) worst-case
+func (m *FlatOrderedMap[K, V]) ForEach(f func(key K, value V) bool) {
+	for i := 0; i < m.Len(); i++ {
+		if !f(m.At(i)) {
+			return
+		}
+	}
+}
+
+// OrderedMap decodes every element, returning them as an OrderedMap.
+//
+// Complexity: O(n) worst-case
+func (m *FlatOrderedMap[K, V]) OrderedMap() *OrderedMap[K, V] {
+	keys := make([]K, m.Len())
+	values := make([]V, m.Len())
+	for i := range keys {
+		keys[i], values[i] = m.At(i)
+	}
+	return orderedMapFromSorted(keys, values, false)
+}
+
+// CheckInvariants verifies that every element can be decoded and that the keys are in strictly
+// ascending order, returning an error describing the first problem found. After it succeeds, no
+// other method will panic due to invalid data.
+//
+// Complexity: O(n) worst-case
+func (m *FlatOrderedMap[K, V]) CheckInvariants() error {
+	return m.checkElements(false)
+}
+
+func (m *FlatOrderedMap[K, V]) checkElements(strict bool) error {
+	var prev K
+	for i := 0; i < m.Len(); i++ {
+		start := binary.LittleEndian.Uint64(m.offsets[8*i:])
+		end := binary.LittleEndian.Uint64(m.offsets[8*(i+1):])
+		var key K
+		var value V
+		rest, err := decodeBinaryElement(m.elements[start:end], &key, strict, "key", i)
+		if err != nil {
+			return err
+		} else if key != key {
+			return fmt.Errorf("immutable: key at index %v is NaN", i)
+		} else if i > 0 && !(prev < key) {
+			return fmt.Errorf("immutable: key %v at index %v is not greater than the preceding key %v", key, i, prev)
+		}
+		if rest, err = decodeBinaryElement(rest, &value, strict, "value", i); err != nil {
+			return err
+		} else if len(rest) > 0 {
+			return fmt.Errorf("immutable: element at index %v has %v bytes of trailing data", i, len(rest))
+		}
+		prev = key
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package immutable
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlatOrderedMap(t *testing.T) {
+	var m *OrderedMap[int, string]
+	for i := 0; i < 1000; i += 2 {
+		m = m.Set(i, fmt.Sprint(i))
+	}
+	data, err := m.MarshalFlat()
+	require.NoError(t, err)
+
+	f, err := OpenFlatOrderedMap[int, string](data)
+	require.NoError(t, err)
+	require.NoError(t, f.CheckInvariants())
+	assert.Equal(t, 500, f.Len())
+	for i := -1; i <= 1000; i++ {
+		v, ok := f.Get(i)
+		assert.Equal(t, i >= 0 && i%2 == 0 && i < 1000, ok)
+		if ok {
+			assert.Equal(t, fmt.Sprint(i), v)
+		}
+	}
+
+	k, v := f.At(3)
+	assert.Equal(t, 6, k)
+	assert.Equal(t, "6", v)
+	assert.Panics(t, func() { f.At(500) })
+
+	var keys []int
+	f.ForEach(func(key int, value string) bool {
+		keys = append(keys, key)
+		return len(keys) < 3
+	})
+	assert.Equal(t, []int{0, 2, 4}, keys)
+
+	decoded := f.OrderedMap()
+	require.NoError(t, decoded.CheckInvariants())
+	assert.Equal(t, m.Len(), decoded.Len())
+	for e := m.Min(); e != nil; e = e.Next() {
+		v, ok := decoded.Get(e.Key())
+		assert.True(t, ok)
+		assert.Equal(t, e.Value(), v)
+	}
+}
+
+func TestFlatOrderedMap_Empty(t *testing.T) {
+	var f *FlatOrderedMap[string, int]
+	assert.Equal(t, 0, f.Len())
+	_, ok := f.Get("foo")
+	assert.False(t, ok)
+	assert.Nil(t, f.OrderedMap())
+
+	data, err := (*OrderedMap[string, int])(nil).MarshalFlat()
+	require.NoError(t, err)
+	f, err = OpenFlatOrderedMap[string, int](data)
+	require.NoError(t, err)
+	assert.Equal(t, 0, f.Len())
+	assert.NoError(t, f.CheckInvariants())
+}
+
+func TestFlatOrderedMap_Invalid(t *testing.T) {
+	m := (*OrderedMap[string, int])(nil).Set("a", 1).Set("b", 2)
+	data, err := m.MarshalFlat()
+	require.NoError(t, err)
+
+	for i := 0; i < len(data); i++ {
+		_, err := OpenFlatOrderedMap[string, int](data[:i])
+		assert.Error(t, err)
+	}
+
+	_, err = OpenFlatOrderedMap[string, int](append(append([]byte(nil), data...), 0))
+	assert.Error(t, err)
+
+	binaryData, err := m.MarshalBinary()
+	require.NoError(t, err)
+	_, err = OpenFlatOrderedMap[string, int](binaryData)
+	assert.Error(t, err)
+
+	// Swapping the elements produces a valid offset table, but unordered keys.
+	swapped := append([]byte(nil), data...)
+	elements := swapped[3+8*3:]
+	copy(elements, append(append([]byte(nil), elements[3:]...), elements[:3]...))
+	f, err := OpenFlatOrderedMap[string, int](swapped)
+	require.NoError(t, err)
+	assert.Error(t, f.CheckInvariants())
+
+	// Corrupting a key's length is detected by CheckInvariants, and causes lookups to panic.
+	corrupted := append([]byte(nil), data...)
+	corrupted[3+8*3] = 100
+	f, err = OpenFlatOrderedMap[string, int](corrupted)
+	require.NoError(t, err)
+	assert.Error(t, f.CheckInvariants())
+	assert.Panics(t, func() { f.At(0) })
+
+	// A value padded with a redundant varint byte is only rejected in strict mode.
+	overlong, err := (*OrderedMap[string, int])(nil).Set("a", 1).MarshalFlat()
+	require.NoError(t, err)
+	overlong = append(overlong[:len(overlong)-1], 0x82, 0x00)
+	binary.LittleEndian.PutUint64(overlong[3+8:], uint64(len(overlong)-3-8*2))
+	f, err = OpenFlatOrderedMap[string, int](overlong)
+	require.NoError(t, err)
+	require.NoError(t, f.CheckInvariants())
+	v, _ := f.Get("a")
+	assert.Equal(t, 1, v)
+	_, err = OpenFlatOrderedMapStrict[string, int](overlong)
+	assert.ErrorContains(t, err, "value 1 at index 0 is not canonically encoded")
+	_, err = OpenFlatOrderedMapStrict[string, int](swapped)
+	assert.ErrorContains(t, err, "is not greater than the preceding key")
+	f, err = OpenFlatOrderedMapStrict[string, int](data)
+	require.NoError(t, err)
+	assert.Equal(t, 2, f.Len())
+
+	// Offsets must be strictly increasing.
+	decreasing := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint64(decreasing[3+8:], 0)
+	_, err = OpenFlatOrderedMap[string, int](decreasing)
+	assert.Error(t, err)
+}
+
+func BenchmarkFlatOrderedMap_Get(b *testing.B) {
+	for _, n := range []int{100, 10000, 1000000} {
+		var m *OrderedMap[int, int]
+		for i := 0; i < n; i++ {
+			m = m.Set(i, i)
+		}
+		data, err := m.MarshalFlat()
+		require.NoError(b, err)
+		f, err := OpenFlatOrderedMap[int, int](data)
+		require.NoError(b, err)
+		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				orderedMapKeyResult, _ = f.Get(i % n)
+			}
+		})
+	}
+}
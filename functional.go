@@ -0,0 +1,129 @@
+package immutable
+
+import "golang.org/x/exp/constraints"
+
+// Fold combines the elements of the map in ascending key order, starting with init.
+//
+// Complexity: O(n) worst-case
+func Fold[K constraints.Ordered, V any, A any](m *OrderedMap[K, V], init A, f func(acc A, key K, value V) A) A {
+	acc := init
+	for e := m.Min(); e != nil; e = e.Next() {
+		acc = f(acc, e.Key(), e.Value())
+	}
+	return acc
+}
+
+// FoldStack combines the items in the stack from top to bottom, starting with init.
+//
+// Complexity: O(n) worst-case
+func FoldStack[T any, A any](s *Stack[T], init A, f func(acc A, value T) A) A {
+	acc := init
+	for ; !s.Empty(); s = s.Pop() {
+		acc = f(acc, s.Peek())
+	}
+	return acc
+}
+
+// FoldQueue combines the items in the queue from front to back, starting with init.
+//
+// Complexity: O(n) worst-case
+func FoldQueue[T any, A any](q *Queue[T], init A, f func(acc A, value T) A) A {
+	acc := init
+	for ; !q.Empty(); q = q.PopFront() {
+		acc = f(acc, q.Front())
+	}
+	return acc
+}
+
+// MapStack returns a stack containing the result of f for each item in the stack, in the same
+// order.
+//
+// Complexity: O(n) worst-case
+func MapStack[T, U any](s *Stack[T], f func(value T) U) *Stack[U] {
+	var items []U
+	for ; !s.Empty(); s = s.Pop() {
+		items = append(items, f(s.Peek()))
+	}
+	return StackFromSlice(items)
+}
+
+// FilterStack returns a stack containing the items in the stack for which pred returns true, in the
+// same order. The part of the stack beneath the last item removed is shared with the result rather
+// than copied, and if no items are removed, the stack itself is returned.
+//
+// Complexity: O(n) worst-case
+func FilterStack[T any](s *Stack[T], pred func(value T) bool) *Stack[T] {
+	var kept []T
+	tail, prefix := s, 0
+	for ; !s.Empty(); s = s.Pop() {
+		if v := s.Peek(); pred(v) {
+			kept = append(kept, v)
+		} else {
+			tail, prefix = s.Pop(), len(kept)
+		}
+	}
+	for i := prefix - 1; i >= 0; i-- {
+		tail = tail.Push(kept[i])
+	}
+	return tail
+}
+
+// PartitionStack returns a stack containing the items in the stack for which pred returns true and
+// a stack containing the rest, both in the same order as the stack.
+//
+// Complexity: O(n) worst-case
+func PartitionStack[T any](s *Stack[T], pred func(value T) bool) (matching, rest *Stack[T]) {
+	var a, b []T
+	for ; !s.Empty(); s = s.Pop() {
+		if v := s.Peek(); pred(v) {
+			a = append(a, v)
+		} else {
+			b = append(b, v)
+		}
+	}
+	return StackFromSlice(a), StackFromSlice(b)
+}
+
+// MapQueue returns a queue containing the result of f for each item in the queue, in the same
+// order.
+//
+// Complexity: O(n) worst-case
+func MapQueue[T, U any](q *Queue[T], f func(value T) U) *Queue[U] {
+	var ret *Queue[U]
+	q.ForEach(func(v T) bool {
+		ret = ret.PushBack(f(v))
+		return true
+	})
+	return ret
+}
+
+// FilterQueue returns a queue containing the items in the queue for which pred returns true, in the
+// same order.
+//
+// Complexity: O(n) worst-case
+func FilterQueue[T any](q *Queue[T], pred func(value T) bool) *Queue[T] {
+	var ret *Queue[T]
+	q.ForEach(func(v T) bool {
+		if pred(v) {
+			ret = ret.PushBack(v)
+		}
+		return true
+	})
+	return ret
+}
+
+// PartitionQueue returns a queue containing the items in the queue for which pred returns true and
+// a queue containing the rest, both in the same order as the queue.
+//
+// Complexity: O(n) worst-case
+func PartitionQueue[T any](q *Queue[T], pred func(value T) bool) (matching, rest *Queue[T]) {
+	q.ForEach(func(v T) bool {
+		if pred(v) {
+			matching = matching.PushBack(v)
+		} else {
+			rest = rest.PushBack(v)
+		}
+		return true
+	})
+	return matching, rest
+}
@@ -0,0 +1,101 @@
+package immutable
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFold(t *testing.T) {
+	var m *OrderedMap[string, int]
+	assert.Equal(t, 0, Fold(m, 0, func(acc int, k string, v int) int {
+		return acc + v
+	}))
+
+	m = m.Set("b", 2).Set("a", 1).Set("c", 3)
+	assert.Equal(t, 6, Fold(m, 0, func(acc int, k string, v int) int {
+		return acc + v
+	}))
+	assert.Equal(t, "abc", Fold(m, "", func(acc string, k string, v int) string {
+		return acc + k
+	}))
+}
+
+func TestFoldStack(t *testing.T) {
+	assert.Equal(t, 0, FoldStack(&Stack[int]{}, 0, func(acc, v int) int {
+		return acc + v
+	}))
+
+	s := StackFromSlice([]int{1, 2, 3})
+	assert.Equal(t, []int{1, 2, 3}, FoldStack(s, []int(nil), func(acc []int, v int) []int {
+		return append(acc, v)
+	}))
+}
+
+func TestFoldQueue(t *testing.T) {
+	assert.Equal(t, 0, FoldQueue(&Queue[int]{}, 0, func(acc, v int) int {
+		return acc + v
+	}))
+
+	q := QueueFromSlice([]int{1, 2, 3})
+	assert.Equal(t, []int{1, 2, 3}, FoldQueue(q, []int(nil), func(acc []int, v int) []int {
+		return append(acc, v)
+	}))
+}
+
+func isEven(v int) bool {
+	return v%2 == 0
+}
+
+func TestMapStack(t *testing.T) {
+	assert.Nil(t, MapStack((*Stack[int])(nil), strconv.Itoa))
+	assert.Equal(t, []string{"1", "2", "3"}, MapStack(StackOf(1, 2, 3), strconv.Itoa).ToSlice())
+}
+
+func TestFilterStack(t *testing.T) {
+	assert.Nil(t, FilterStack((*Stack[int])(nil), isEven))
+	assert.True(t, FilterStack(StackOf(1, 3), isEven).Empty())
+	assert.Equal(t, []int{2, 4, 6}, FilterStack(StackOf(1, 2, 3, 4, 5, 6), isEven).ToSlice())
+
+	// Items beneath the last removed one are shared.
+	base := StackOf(2, 4, 6)
+	s := base.Push(3).Push(8)
+	filtered := FilterStack(s, isEven)
+	assert.Equal(t, []int{8, 2, 4, 6}, filtered.ToSlice())
+	assert.Same(t, base, filtered.Pop())
+
+	assert.Same(t, base, FilterStack(base, isEven))
+}
+
+func TestPartitionStack(t *testing.T) {
+	matching, rest := PartitionStack((*Stack[int])(nil), isEven)
+	assert.Nil(t, matching)
+	assert.Nil(t, rest)
+
+	matching, rest = PartitionStack(StackOf(1, 2, 3, 4, 5), isEven)
+	assert.Equal(t, []int{2, 4}, matching.ToSlice())
+	assert.Equal(t, []int{1, 3, 5}, rest.ToSlice())
+}
+
+func TestMapQueue(t *testing.T) {
+	assert.True(t, MapQueue((*Queue[int])(nil), strconv.Itoa).Empty())
+	q := QueueOf(0, 1, 2, 3).PopFront().PushBack(4)
+	assert.Equal(t, []string{"1", "2", "3", "4"}, MapQueue(q, strconv.Itoa).ToSlice())
+}
+
+func TestFilterQueue(t *testing.T) {
+	assert.True(t, FilterQueue((*Queue[int])(nil), isEven).Empty())
+	q := QueueOf(0, 1, 2, 3).PopFront().PushBack(4)
+	assert.Equal(t, []int{2, 4}, FilterQueue(q, isEven).ToSlice())
+}
+
+func TestPartitionQueue(t *testing.T) {
+	matching, rest := PartitionQueue((*Queue[int])(nil), isEven)
+	assert.True(t, matching.Empty())
+	assert.True(t, rest.Empty())
+
+	matching, rest = PartitionQueue(QueueOf(1, 2, 3, 4, 5), isEven)
+	assert.Equal(t, []int{2, 4}, matching.ToSlice())
+	assert.Equal(t, []int{1, 3, 5}, rest.ToSlice())
+}
@@ -0,0 +1,247 @@
+package immutable
+
+import "fmt"
+
+// Graph implements a directed graph.
+//
+// Nodes can be any comparable type. They're iterated in an order determined by hashes which are
+// seeded randomly when the program starts, so the order is consistent within a run of the program,
+// but differs from run to run. Code that needs a deterministic order, such as to produce
+// reproducible output, should sort the nodes. Undirected graphs can be represented by adding edges
+// in both directions.
+//
+// Nil and the zero value for Graph are both empty graphs.
+type Graph[N comparable] struct {
+	successors   *hashMap[N, *hashMap[N, struct{}]]
+	predecessors *hashMap[N, *hashMap[N, struct{}]]
+	edges        int
+}
+
+// Len returns the number of nodes in the graph.
+//
+// Complexity: O(1) worst-case
+func (g *Graph[N]) Len() int {
+	if g == nil {
+		return 0
+	}
+	return g.successors.Len()
+}
+
+// EdgeCount returns the number of edges in the graph.
+//
+// Complexity: O(1) worst-case
+func (g *Graph[N]) EdgeCount() int {
+	if g == nil {
+		return 0
+	}
+	return g.edges
+}
+
+// HasNode returns true if the graph contains the given node.
+//
+// Complexity: O(log n) expected
+func (g *Graph[N]) HasNode(node N) bool {
+	if g == nil {
+		return false
+	}
+	_, ok := g.successors.Get(node)
+	return ok
+}
+
+// HasEdge returns true if the graph contains an edge from one node to another.
+//
+// Complexity: O(log n) expected
+func (g *Graph[N]) HasEdge(from, to N) bool {
+	_, ok := g.successorSet(from).Get(to)
+	return ok
+}
+
+// AddNode adds a node to the graph. Like OrderedMap.Set, it panics if the node is NaN.
+//
+// Complexity: O(log n) expected
+func (g *Graph[N]) AddNode(node N) *Graph[N] {
+	if g.HasNode(node) {
+		return g
+	}
+	ret := g.clone()
+	ret.successors = ret.successors.Set(node, nil)
+	ret.predecessors = ret.predecessors.Set(node, nil)
+	return ret
+}
+
+// RemoveNode removes a node and all of its edges from the graph.
+//
+// Complexity: O(d log n) expected, where d is the number of edges connected to the node
+func (g *Graph[N]) RemoveNode(node N) *Graph[N] {
+	if !g.HasNode(node) {
+		return g
+	}
+	ret := g
+	g.ForEachNeighbor(node, func(to N) bool {
+		ret = ret.RemoveEdge(node, to)
+		return true
+	})
+	g.ForEachPredecessor(node, func(from N) bool {
+		ret = ret.RemoveEdge(from, node)
+		return true
+	})
+	ret = ret.clone()
+	ret.successors = ret.successors.Delete(node)
+	ret.predecessors = ret.predecessors.Delete(node)
+	return ret
+}
+
+// AddEdge adds an edge from one node to another, adding the nodes as well if necessary. Like
+// OrderedMap.Set, it panics if either node is NaN.
+//
+// Complexity: O(log n) expected
+func (g *Graph[N]) AddEdge(from, to N) *Graph[N] {
+	if g.HasEdge(from, to) {
+		return g
+	}
+	ret := g.clone()
+	ret.successors = ret.successors.Set(from, g.successorSet(from).Set(to, struct{}{}))
+	if _, ok := ret.successors.Get(to); !ok {
+		ret.successors = ret.successors.Set(to, nil)
+	}
+	ret.predecessors = ret.predecessors.Set(to, g.predecessorSet(to).Set(from, struct{}{}))
+	if _, ok := ret.predecessors.Get(from); !ok {
+		ret.predecessors = ret.predecessors.Set(from, nil)
+	}
+	ret.edges++
+	return ret
+}
+
+// RemoveEdge removes the edge from one node to another. The nodes remain in the graph.
+//
+// Complexity: O(log n) expected
+func (g *Graph[N]) RemoveEdge(from, to N) *Graph[N] {
+	if !g.HasEdge(from, to) {
+		return g
+	}
+	ret := g.clone()
+	ret.successors = ret.successors.Set(from, g.successorSet(from).Delete(to))
+	ret.predecessors = ret.predecessors.Set(to, g.predecessorSet(to).Delete(from))
+	ret.edges--
+	return ret
+}
+
+// ForEachNeighbor calls f for each node that the given node has edges to, stopping early if f
+// returns false.
+//
+// Complexity: O(log n + d) expected, where d is the number of nodes the given node has edges to
+func (g *Graph[N]) ForEachNeighbor(node N, f func(neighbor N) bool) {
+	g.successorSet(node).ForEach(func(neighbor N, _ struct{}) bool {
+		return f(neighbor)
+	})
+}
+
+// ForEachPredecessor calls f for each node that has an edge to the given node, stopping early if f
+// returns false.
+//
+// Complexity: O(log n + d) expected, where d is the number of nodes with edges to the given node
+func (g *Graph[N]) ForEachPredecessor(node N, f func(predecessor N) bool) {
+	g.predecessorSet(node).ForEach(func(predecessor N, _ struct{}) bool {
+		return f(predecessor)
+	})
+}
+
+// OutDegree returns the number of nodes that the given node has edges to.
+//
+// Complexity: O(log n) expected
+func (g *Graph[N]) OutDegree(node N) int {
+	return g.successorSet(node).Len()
+}
+
+// InDegree returns the number of nodes that have edges to the given node.
+//
+// Complexity: O(log n) expected
+func (g *Graph[N]) InDegree(node N) int {
+	return g.predecessorSet(node).Len()
+}
+
+// ForEachNode calls f for each node in the graph, stopping early if f returns false.
+//
+// Complexity: O(n) worst-case
+func (g *Graph[N]) ForEachNode(f func(node N) bool) {
+	if g == nil {
+		return
+	}
+	g.successors.ForEach(func(node N, _ *hashMap[N, struct{}]) bool {
+		return f(node)
+	})
+}
+
+func (g *Graph[N]) successorSet(node N) *hashMap[N, struct{}] {
+	if g == nil {
+		return nil
+	}
+	ret, _ := g.successors.Get(node)
+	return ret
+}
+
+func (g *Graph[N]) predecessorSet(node N) *hashMap[N, struct{}] {
+	if g == nil {
+		return nil
+	}
+	ret, _ := g.predecessors.Get(node)
+	return ret
+}
+
+func (g *Graph[N]) clone() *Graph[N] {
+	if g == nil {
+		return &Graph[N]{}
+	}
+	ret := *g
+	return &ret
+}
+
+// CheckInvariants verifies the structural integrity of the graph, returning an error describing the
+// first problem found. It checks that every edge is recorded as both a successor of its source and
+// a predecessor of its destination, that both ends of every edge are nodes, and that the recorded
+// number of edges is correct.
+//
+// Complexity: O(n + e) expected, where e is the number of edges
+func (g *Graph[N]) CheckInvariants() error {
+	if g == nil {
+		return nil
+	} else if err := g.successors.checkInvariants(); err != nil {
+		return err
+	} else if err := g.predecessors.checkInvariants(); err != nil {
+		return err
+	} else if g.successors.Len() != g.predecessors.Len() {
+		return fmt.Errorf("graph has %v nodes with successors, but %v with predecessors", g.successors.Len(), g.predecessors.Len())
+	}
+	var err error
+	check := func(sets, inverse *hashMap[N, *hashMap[N, struct{}]]) int {
+		edges := 0
+		sets.ForEach(func(node N, set *hashMap[N, struct{}]) bool {
+			if err = set.checkInvariants(); err != nil {
+				return false
+			}
+			set.ForEach(func(other N, _ struct{}) bool {
+				inverseSet, ok := inverse.Get(other)
+				if !ok {
+					err = fmt.Errorf("edge between %v and %v refers to a missing node", node, other)
+				} else if _, ok := inverseSet.Get(node); !ok {
+					err = fmt.Errorf("edge between %v and %v is only recorded in one direction", node, other)
+				}
+				edges++
+				return err == nil
+			})
+			return err == nil
+		})
+		return edges
+	}
+	successorEdges := check(g.successors, g.predecessors)
+	if err != nil {
+		return err
+	}
+	predecessorEdges := check(g.predecessors, g.successors)
+	if err != nil {
+		return err
+	} else if successorEdges != g.edges || predecessorEdges != g.edges {
+		return fmt.Errorf("graph has %v edges, but %v were recorded", successorEdges, g.edges)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package immutable
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func graphNeighbors[N comparable](g *Graph[N], node N) []N {
+	var ret []N
+	g.ForEachNeighbor(node, func(neighbor N) bool {
+		ret = append(ret, neighbor)
+		return true
+	})
+	return ret
+}
+
+func graphPredecessors[N comparable](g *Graph[N], node N) []N {
+	var ret []N
+	g.ForEachPredecessor(node, func(predecessor N) bool {
+		ret = append(ret, predecessor)
+		return true
+	})
+	return ret
+}
+
+func graphNodes[N comparable](g *Graph[N]) []N {
+	var ret []N
+	g.ForEachNode(func(node N) bool {
+		ret = append(ret, node)
+		return true
+	})
+	return ret
+}
+
+func TestGraph(t *testing.T) {
+	var g *Graph[string]
+	assert.Equal(t, 0, g.Len())
+	assert.Equal(t, 0, g.EdgeCount())
+	assert.False(t, g.HasNode("a"))
+	assert.False(t, g.HasEdge("a", "b"))
+	assert.Empty(t, graphNeighbors(g, "a"))
+	assert.Empty(t, graphNodes(g))
+
+	g = g.AddNode("a").AddEdge("a", "b").AddEdge("a", "c").AddEdge("c", "a")
+	assert.Equal(t, 3, g.Len())
+	assert.Equal(t, 3, g.EdgeCount())
+	assert.True(t, g.HasEdge("a", "b"))
+	assert.False(t, g.HasEdge("b", "a"))
+	assert.ElementsMatch(t, []string{"b", "c"}, graphNeighbors(g, "a"))
+	assert.Equal(t, []string{"c"}, graphPredecessors(g, "a"))
+	assert.Equal(t, 2, g.OutDegree("a"))
+	assert.Equal(t, 1, g.InDegree("a"))
+	assert.Same(t, g, g.AddEdge("a", "b"))
+	assert.Same(t, g, g.AddNode("b"))
+
+	g2 := g.RemoveEdge("a", "b")
+	assert.Equal(t, 3, g2.Len())
+	assert.Equal(t, 2, g2.EdgeCount())
+	assert.False(t, g2.HasEdge("a", "b"))
+	assert.True(t, g.HasEdge("a", "b"))
+
+	g3 := g.RemoveNode("a")
+	assert.Equal(t, 2, g3.Len())
+	assert.Equal(t, 0, g3.EdgeCount())
+	assert.False(t, g3.HasNode("a"))
+	assert.Empty(t, graphPredecessors(g3, "b"))
+	assert.ElementsMatch(t, []string{"b", "c"}, graphNodes(g3))
+
+	assert.Panics(t, func() {
+		(*Graph[float64])(nil).AddNode(math.NaN())
+	})
+}
+
+func TestGraph_Comparable(t *testing.T) {
+	type task struct {
+		name string
+		id   int
+	}
+	a, b := task{"build", 1}, task{"test", 2}
+	var g *Graph[task]
+	g = g.AddEdge(a, b)
+	assert.True(t, g.HasEdge(a, b))
+	assert.False(t, g.HasEdge(b, a))
+	assert.Equal(t, []task{b}, graphNeighbors(g, a))
+	assert.Equal(t, []task{a}, graphPredecessors(g, b))
+	assert.Equal(t, 0, g.RemoveNode(a).EdgeCount())
+}
+
+func TestGraph_Fuzz(t *testing.T) {
+	ref := map[int]map[int]bool{}
+	var g *Graph[int]
+	for i := 0; i < 5000; i++ {
+		a, b := rand.Intn(20), rand.Intn(20)
+		switch rand.Intn(4) {
+		case 0:
+			g = g.AddEdge(a, b)
+			if ref[a] == nil {
+				ref[a] = map[int]bool{}
+			}
+			if ref[b] == nil {
+				ref[b] = map[int]bool{}
+			}
+			ref[a][b] = true
+		case 1:
+			g = g.RemoveEdge(a, b)
+			delete(ref[a], b)
+		case 2:
+			g = g.RemoveNode(a)
+			delete(ref, a)
+			for _, edges := range ref {
+				delete(edges, a)
+			}
+		default:
+			g = g.AddNode(a)
+			if ref[a] == nil {
+				ref[a] = map[int]bool{}
+			}
+		}
+		require.NoError(t, g.CheckInvariants())
+		require.Equal(t, len(ref), g.Len())
+		edges := 0
+		for a, neighbors := range ref {
+			edges += len(neighbors)
+			require.Equal(t, len(neighbors), g.OutDegree(a))
+			for b := range neighbors {
+				require.True(t, g.HasEdge(a, b))
+				require.Contains(t, graphPredecessors(g, b), a)
+			}
+		}
+		require.Equal(t, edges, g.EdgeCount())
+	}
+}
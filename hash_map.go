@@ -0,0 +1,259 @@
+package immutable
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"reflect"
+)
+
+// hashMapSeed is used to hash the keys of every hashMap. It's chosen randomly when the program
+// starts, so iteration orders differ between runs.
+var hashMapSeed = maphash.MakeSeed()
+
+// hashMap implements a map for keys which are comparable, but not necessarily ordered. It's used by
+// the containers which only need to look up their keys, so that they aren't limited to the key
+// types supported by OrderedMap.
+//
+// Elements are stored in an OrderedMap keyed by the hashes of their keys, with the rare keys whose
+// hashes collide sharing a bucket. As such, iteration order is unspecified.
+//
+// Nil and the zero value for hashMap are both empty maps.
+type hashMap[K comparable, V any] struct {
+	buckets *OrderedMap[uint64, []Pair[K, V]]
+	len     int
+}
+
+// hashMapHash hashes a key such that equal keys have equal hashes. Common key types are hashed
+// directly, and others are hashed by walking their values with reflection.
+func hashMapHash[K comparable](key K) uint64 {
+	var h maphash.Hash
+	h.SetSeed(hashMapSeed)
+	switch k := any(key).(type) {
+	case string:
+		h.WriteString(k)
+	case int:
+		hashMapWriteUint64(&h, uint64(k))
+	case int64:
+		hashMapWriteUint64(&h, uint64(k))
+	case uint64:
+		hashMapWriteUint64(&h, k)
+	default:
+		hashMapWriteValue(&h, reflect.ValueOf(&key).Elem())
+	}
+	return h.Sum64()
+}
+
+func hashMapWriteUint64(h *maphash.Hash, v uint64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	h.Write(buf[:])
+}
+
+func hashMapWriteFloat(h *maphash.Hash, f float64) {
+	if f == 0 {
+		// Positive and negative zero are equal, so they must hash equally.
+		f = 0
+	}
+	hashMapWriteUint64(h, math.Float64bits(f))
+}
+
+// hashMapWriteValue writes a value of a comparable type to h. Like the == operator, it compares
+// pointers, channels, and the like by identity and panics if it encounters an interface holding a
+// value of an incomparable type.
+func hashMapWriteValue(h *maphash.Hash, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			h.WriteByte(1)
+		} else {
+			h.WriteByte(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		hashMapWriteUint64(h, uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		hashMapWriteUint64(h, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		hashMapWriteFloat(h, v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		hashMapWriteFloat(h, real(c))
+		hashMapWriteFloat(h, imag(c))
+	case reflect.String:
+		hashMapWriteUint64(h, uint64(v.Len()))
+		h.WriteString(v.String())
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		hashMapWriteUint64(h, uint64(v.Pointer()))
+	case reflect.Interface:
+		if v.IsNil() {
+			h.WriteByte(0)
+			return
+		}
+		// Values of different dynamic types are never equal, so it's enough to hash the value.
+		h.WriteByte(1)
+		hashMapWriteValue(h, v.Elem())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			hashMapWriteValue(h, v.Index(i))
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			// Blank fields are ignored by ==.
+			if t.Field(i).Name != "_" {
+				hashMapWriteValue(h, v.Field(i))
+			}
+		}
+	default:
+		panic("immutable: hash of unhashable type " + v.Type().String())
+	}
+}
+
+// Len returns the number of elements in the map.
+//
+// Complexity: O(1) worst-case
+func (m *hashMap[K, V]) Len() int {
+	if m == nil {
+		return 0
+	}
+	return m.len
+}
+
+// Get returns the value associated with the given key if set.
+//
+// Complexity: O(log n) expected
+func (m *hashMap[K, V]) Get(key K) (V, bool) {
+	return m.get(hashMapHash(key), key)
+}
+
+func (m *hashMap[K, V]) get(hash uint64, key K) (V, bool) {
+	if m != nil {
+		bucket, _ := m.buckets.Get(hash)
+		if i := hashMapIndex(bucket, key); i >= 0 {
+			return bucket[i].Value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// hashMapIndex returns the index of the given key within a bucket, or -1 if it isn't present.
+func hashMapIndex[K comparable, V any](bucket []Pair[K, V], key K) int {
+	for i, p := range bucket {
+		if p.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Set associates a value with the given key. Like OrderedMap.Set, it panics if the key is NaN.
+//
+// Complexity: O(log n) expected
+func (m *hashMap[K, V]) Set(key K, value V) *hashMap[K, V] {
+	if key != key {
+		panic("immutable: NaN keys are not supported")
+	}
+	return m.set(hashMapHash(key), key, value)
+}
+
+func (m *hashMap[K, V]) set(hash uint64, key K, value V) *hashMap[K, V] {
+	var ret hashMap[K, V]
+	if m != nil {
+		ret = *m
+	}
+	bucket, _ := ret.buckets.Get(hash)
+	updated := make([]Pair[K, V], len(bucket), len(bucket)+1)
+	copy(updated, bucket)
+	if i := hashMapIndex(bucket, key); i >= 0 {
+		updated[i].Value = value
+	} else {
+		updated = append(updated, MakePair(key, value))
+		ret.len++
+	}
+	ret.buckets = ret.buckets.Set(hash, updated)
+	return &ret
+}
+
+// Delete removes a key from the map.
+//
+// Complexity: O(log n) expected
+func (m *hashMap[K, V]) Delete(key K) *hashMap[K, V] {
+	return m.delete(hashMapHash(key), key)
+}
+
+func (m *hashMap[K, V]) delete(hash uint64, key K) *hashMap[K, V] {
+	if m == nil {
+		return m
+	}
+	bucket, _ := m.buckets.Get(hash)
+	i := hashMapIndex(bucket, key)
+	if i < 0 {
+		return m
+	}
+	ret := *m
+	ret.len--
+	if len(bucket) == 1 {
+		ret.buckets = ret.buckets.Delete(hash)
+	} else {
+		updated := make([]Pair[K, V], 0, len(bucket)-1)
+		updated = append(append(updated, bucket[:i]...), bucket[i+1:]...)
+		ret.buckets = ret.buckets.Set(hash, updated)
+	}
+	return &ret
+}
+
+// ForEach calls f for each element in the map in an unspecified order, stopping early if f returns
+// false.
+//
+// Complexity: O(n) worst-case
+func (m *hashMap[K, V]) ForEach(f func(key K, value V) bool) {
+	if m == nil {
+		return
+	}
+	m.buckets.ForEach(func(_ uint64, bucket []Pair[K, V]) bool {
+		for _, p := range bucket {
+			if !f(p.Key, p.Value) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// checkInvariants verifies the structural integrity of the map, returning an error describing the
+// first problem found. It checks that each key is in the bucket for its hash, that no bucket is
+// empty or contains a key twice, and that the recorded length is correct.
+func (m *hashMap[K, V]) checkInvariants() error {
+	if m == nil {
+		return nil
+	} else if err := m.buckets.CheckInvariants(); err != nil {
+		return err
+	}
+	n := 0
+	var err error
+	m.buckets.ForEach(func(hash uint64, bucket []Pair[K, V]) bool {
+		if len(bucket) == 0 {
+			err = fmt.Errorf("bucket %v is empty", hash)
+			return false
+		}
+		for i, p := range bucket {
+			if hashMapHash(p.Key) != hash {
+				err = fmt.Errorf("key %v is in bucket %v, but hashes to %v", p.Key, hash, hashMapHash(p.Key))
+				return false
+			} else if hashMapIndex(bucket, p.Key) != i {
+				err = fmt.Errorf("key %v appears more than once", p.Key)
+				return false
+			}
+		}
+		n += len(bucket)
+		return true
+	})
+	if err != nil {
+		return err
+	} else if n != m.len {
+		return fmt.Errorf("map has %v elements, but %v were recorded", n, m.len)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package immutable
+
+import (
+	"hash/maphash"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hashMapToMap[K comparable, V any](m *hashMap[K, V]) map[K]V {
+	ret := map[K]V{}
+	m.ForEach(func(key K, value V) bool {
+		ret[key] = value
+		return true
+	})
+	return ret
+}
+
+// hashMapTestHash hashes any value the way hashMapHash hashes keys of non-basic types. Unlike
+// hashMapHash, it can be used with interfaces.
+func hashMapTestHash(v any) uint64 {
+	var h maphash.Hash
+	h.SetSeed(hashMapSeed)
+	hashMapWriteValue(&h, reflect.ValueOf(&v).Elem())
+	return h.Sum64()
+}
+
+func TestHashMap(t *testing.T) {
+	type key struct {
+		a string
+		b int
+	}
+	var m *hashMap[key, int]
+	assert.Equal(t, 0, m.Len())
+	_, ok := m.Get(key{"a", 1})
+	assert.False(t, ok)
+	assert.Nil(t, m.Delete(key{"a", 1}))
+	assert.Empty(t, hashMapToMap(m))
+
+	m = m.Set(key{"a", 1}, 1).Set(key{"b", 2}, 2).Set(key{"a", 1}, 3)
+	assert.Equal(t, 2, m.Len())
+	v, ok := m.Get(key{"a", 1})
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, map[key]int{{"a", 1}: 3, {"b", 2}: 2}, hashMapToMap(m))
+	assert.Same(t, m, m.Delete(key{"c", 3}))
+	assert.Equal(t, map[key]int{{"b", 2}: 2}, hashMapToMap(m.Delete(key{"a", 1})))
+
+	assert.Panics(t, func() {
+		(*hashMap[float64, int])(nil).Set(math.NaN(), 1)
+	})
+}
+
+func TestHashMapHash(t *testing.T) {
+	type key struct {
+		a [2]float64
+		b any
+		p *int
+		_ int
+	}
+	x, y := new(int), new(int)
+	for _, pair := range [][2]key{
+		{{a: [2]float64{0, 1}}, {a: [2]float64{math.Copysign(0, -1), 1}}},
+		{{b: "foo", p: x}, {b: "foo", p: x}},
+		{{b: key{p: y}}, {b: key{p: y}}},
+	} {
+		assert.Equal(t, pair[0], pair[1])
+		assert.Equal(t, hashMapTestHash(pair[0]), hashMapTestHash(pair[1]))
+	}
+	assert.NotEqual(t, hashMapTestHash(key{p: x}), hashMapTestHash(key{p: y}))
+	assert.Equal(t, hashMapTestHash(1.5), hashMapTestHash(1.5))
+	assert.Equal(t, hashMapTestHash(nil), hashMapTestHash(nil))
+
+	type pointerKey struct {
+		a [2]float64
+		p *int
+	}
+	assert.Equal(t, hashMapHash(pointerKey{a: [2]float64{0, 1}}), hashMapHash(pointerKey{a: [2]float64{math.Copysign(0, -1), 1}}))
+	var m *hashMap[pointerKey, int]
+	m = m.Set(pointerKey{p: x}, 1).Set(pointerKey{p: y}, 2)
+	v, _ := m.Get(pointerKey{p: x})
+	assert.Equal(t, 1, v)
+	assert.Equal(t, 2, m.Len())
+
+	// Like map keys, interfaces holding incomparable values can't be hashed.
+	assert.Panics(t, func() {
+		hashMapTestHash([]int{1})
+	})
+}
+
+func TestHashMap_Collisions(t *testing.T) {
+	var m *hashMap[int, int]
+	for i := 0; i < 3; i++ {
+		m = m.set(0, i, i)
+	}
+	m2 := m.set(0, 1, 10)
+	assert.Equal(t, 3, m2.Len())
+	v, ok := m2.get(0, 1)
+	assert.True(t, ok)
+	assert.Equal(t, 10, v)
+	v, _ = m.get(0, 1)
+	assert.Equal(t, 1, v)
+
+	m3 := m.delete(0, 1)
+	assert.Equal(t, 2, m3.Len())
+	assert.Equal(t, map[int]int{0: 0, 2: 2}, hashMapToMap(m3))
+	assert.Same(t, m3, m3.delete(0, 1))
+	assert.Equal(t, 0, m3.delete(0, 0).delete(0, 2).Len())
+	assert.Equal(t, 3, m.Len())
+}
+
+func TestHashMap_Fuzz(t *testing.T) {
+	ref := map[int]int{}
+	var m *hashMap[int, int]
+	for i := 0; i < 5000; i++ {
+		k := rand.Intn(200)
+		if rand.Intn(3) == 0 {
+			m = m.Delete(k)
+			delete(ref, k)
+		} else {
+			m = m.Set(k, i)
+			ref[k] = i
+		}
+		require.Equal(t, len(ref), m.Len())
+	}
+	assert.Equal(t, ref, hashMapToMap(m))
+}
@@ -0,0 +1,187 @@
+package immutable
+
+import (
+	"fmt"
+	"hash/maphash"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Hash returns a hash of the map's contents. Maps with equal contents produce equal hashes for the
+// same seed, regardless of the order in which their elements were set.
+//
+// Keys are hashed using their binary encoding. Values are hashed by hashValue, which should write
+// the value to the given hash. If hashValue is nil, values are hashed using their binary encoding,
+// and Hash panics if the value type isn't supported by MarshalBinary.
+//
+// Nothing is cached: OrderedMap nodes are kept as small as possible, so there's no room in them for
+// a hash, and every call hashes every element. To avoid rehashing the entire map after each
+// modification, use a HashedOrderedMap, which maintains the hash as the map is modified.
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMap[K, V]) Hash(seed maphash.Seed, hashValue func(h *maphash.Hash, value V)) uint64 {
+	var sum uint64
+	for e := m.Min(); e != nil; e = e.Next() {
+		sum += orderedMapEntryHash(seed, hashValue, e.Key(), e.Value())
+	}
+	return sum
+}
+
+// orderedMapEntryHash hashes a single element. The hash of a map is the sum of the hashes of its
+// elements, which allows it to be maintained incrementally.
+func orderedMapEntryHash[K constraints.Ordered, V any](seed maphash.Seed, hashValue func(h *maphash.Hash, value V), key K, value V) uint64 {
+	var h maphash.Hash
+	h.SetSeed(seed)
+	buf, err := appendBinaryValue(nil, key)
+	if err != nil {
+		panic(err)
+	}
+	h.Write(buf)
+	if hashValue != nil {
+		hashValue(&h, value)
+	} else {
+		buf, err = appendBinaryValue(buf[:0], value)
+		if err != nil {
+			panic(err)
+		}
+		h.Write(buf)
+	}
+	return h.Sum64()
+}
+
+// HashedOrderedMap implements an ordered map which maintains a hash of its contents as it is
+// modified, making it cheap to use as a content-addressed identifier for snapshots of the map.
+//
+// The hash is identical to the one returned by OrderedMap.Hash with the same seed and hash function.
+// Since the hash is the sum of the hashes of the elements, each modification only needs to rehash
+// the elements it adds or removes. The hash is kept by the HashedOrderedMap rather than in the
+// underlying map's nodes, so it isn't retained by the map returned by OrderedMap.
+//
+// Nil and the zero value for HashedOrderedMap are both empty maps which hash values using their
+// binary encoding and a seed chosen randomly when the program starts. To hash with a different seed
+// or hash function, such as to compare hashes between processes, use NewHashedOrderedMap.
+type HashedOrderedMap[K constraints.Ordered, V any] struct {
+	m         *OrderedMap[K, V]
+	seed      maphash.Seed
+	hashValue func(h *maphash.Hash, value V)
+	hash      uint64
+}
+
+// hashedOrderedMapDefaultSeed is the seed used by maps which weren't given one.
+var hashedOrderedMapDefaultSeed = maphash.MakeSeed()
+
+// NewHashedOrderedMap returns a HashedOrderedMap with the same contents as the given map. See
+// OrderedMap.Hash for a description of the seed and hashValue arguments. If the seed is the zero
+// value, the same seed as the zero value of HashedOrderedMap is used.
+//
+// Complexity: O(n) worst-case
+func NewHashedOrderedMap[K constraints.Ordered, V any](m *OrderedMap[K, V], seed maphash.Seed, hashValue func(h *maphash.Hash, value V)) *HashedOrderedMap[K, V] {
+	if seed == (maphash.Seed{}) {
+		seed = hashedOrderedMapDefaultSeed
+	}
+	return &HashedOrderedMap[K, V]{
+		m:         m,
+		seed:      seed,
+		hashValue: hashValue,
+		hash:      m.Hash(seed, hashValue),
+	}
+}
+
+// OrderedMap returns an OrderedMap with the same contents as the map.
+//
+// Complexity: O(1) worst-case
+func (m *HashedOrderedMap[K, V]) OrderedMap() *OrderedMap[K, V] {
+	if m == nil {
+		return nil
+	}
+	return m.m
+}
+
+// Hash returns the hash of the map's contents.
+//
+// Complexity: O(1) worst-case
+func (m *HashedOrderedMap[K, V]) Hash() uint64 {
+	if m == nil {
+		return 0
+	}
+	return m.hash
+}
+
+// Empty returns true if the map is empty.
+//
+// Complexity: O(1) worst-case
+func (m *HashedOrderedMap[K, V]) Empty() bool {
+	return m.OrderedMap().Empty()
+}
+
+// Len returns the number of elements in the map.
+//
+// Complexity: O(1) worst-case
+func (m *HashedOrderedMap[K, V]) Len() int {
+	return m.OrderedMap().Len()
+}
+
+// Get returns the value associated with the given key if set.
+//
+// Complexity: O(log n) worst-case
+func (m *HashedOrderedMap[K, V]) Get(key K) (V, bool) {
+	return m.OrderedMap().Get(key)
+}
+
+// Set associates a value with the given key.
+//
+// Complexity: O(log n) worst-case
+func (m *HashedOrderedMap[K, V]) Set(key K, value V) *HashedOrderedMap[K, V] {
+	var ret HashedOrderedMap[K, V]
+	if m != nil {
+		ret = *m
+	}
+	if ret.seed == (maphash.Seed{}) {
+		ret.seed = hashedOrderedMapDefaultSeed
+	}
+	if old, ok := ret.m.Get(key); ok {
+		ret.hash -= orderedMapEntryHash(ret.seed, ret.hashValue, key, old)
+	}
+	ret.m = ret.m.Set(key, value)
+	ret.hash += orderedMapEntryHash(ret.seed, ret.hashValue, key, value)
+	return &ret
+}
+
+// Delete removes a key from the map.
+//
+// Complexity: O(log n) worst-case
+func (m *HashedOrderedMap[K, V]) Delete(key K) *HashedOrderedMap[K, V] {
+	old, ok := m.Get(key)
+	if !ok {
+		return m
+	}
+	// The map isn't empty, so it was either created by NewHashedOrderedMap or has been given the
+	// default seed by Set.
+	return &HashedOrderedMap[K, V]{
+		m:         m.m.Delete(key),
+		seed:      m.seed,
+		hashValue: m.hashValue,
+		hash:      m.hash - orderedMapEntryHash(m.seed, m.hashValue, key, old),
+	}
+}
+
+// CheckInvariants verifies the structural integrity of the map, returning an error describing the
+// first problem found. It checks the underlying map and that the maintained hash matches a hash of
+// the map's contents computed from scratch.
+//
+// Complexity: O(n) worst-case
+func (m *HashedOrderedMap[K, V]) CheckInvariants() error {
+	if m == nil {
+		return nil
+	} else if err := m.m.CheckInvariants(); err != nil {
+		return err
+	}
+	seed := m.seed
+	if seed == (maphash.Seed{}) {
+		seed = hashedOrderedMapDefaultSeed
+	}
+	if expected := m.m.Hash(seed, m.hashValue); m.hash != expected {
+		return fmt.Errorf("map has hash %v, but its contents hash to %v", m.hash, expected)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package immutable
+
+import (
+	"hash/maphash"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedMap_Hash(t *testing.T) {
+	seed := maphash.MakeSeed()
+
+	var m *OrderedMap[int, string]
+	assert.Equal(t, uint64(0), m.Hash(seed, nil))
+
+	a := m.Set(1, "a").Set(2, "b").Set(3, "c")
+	b := m.Set(3, "c").Set(1, "a").Set(2, "b")
+	assert.Equal(t, a.Hash(seed, nil), b.Hash(seed, nil))
+	assert.NotEqual(t, a.Hash(seed, nil), a.Set(2, "x").Hash(seed, nil))
+	assert.NotEqual(t, a.Hash(seed, nil), a.Delete(2).Hash(seed, nil))
+	assert.Equal(t, a.Hash(seed, nil), a.Set(4, "d").Delete(4).Hash(seed, nil))
+
+	hashLen := func(h *maphash.Hash, value string) {
+		h.WriteByte(byte(len(value)))
+	}
+	assert.Equal(t, a.Hash(seed, hashLen), a.Set(2, "x").Hash(seed, hashLen))
+	assert.NotEqual(t, a.Hash(seed, hashLen), a.Set(2, "xx").Hash(seed, hashLen))
+
+	assert.Panics(t, func() {
+		(&OrderedMap[int, []int]{}).Set(1, []int{1}).Hash(seed, nil)
+	})
+}
+
+func TestHashedOrderedMap(t *testing.T) {
+	seed := maphash.MakeSeed()
+
+	m := NewHashedOrderedMap[int, int](nil, seed, nil)
+	assert.True(t, m.Empty())
+	assert.Equal(t, uint64(0), m.Hash())
+
+	ref := map[int]int{}
+	for i := 0; i < 1000; i++ {
+		k := rand.Intn(50)
+		if rand.Intn(3) == 0 {
+			delete(ref, k)
+			m = m.Delete(k)
+		} else {
+			v := rand.Intn(5)
+			ref[k] = v
+			m = m.Set(k, v)
+		}
+		require.Equal(t, len(ref), m.Len())
+		require.Equal(t, m.OrderedMap().Hash(seed, nil), m.Hash())
+		require.NoError(t, m.CheckInvariants())
+	}
+	assert.Equal(t, OrderedMapFromMap(ref).Hash(seed, nil), m.Hash())
+	assert.Equal(t, m.Hash(), NewHashedOrderedMap(m.OrderedMap(), seed, nil).Hash())
+
+	// A cached hash which doesn't match the elements is detected.
+	corrupted := *m
+	corrupted.hash++
+	assert.Error(t, corrupted.CheckInvariants())
+}
+
+func TestHashedOrderedMap_Zero(t *testing.T) {
+	var m *HashedOrderedMap[int, string]
+	assert.True(t, m.Empty())
+	assert.Equal(t, 0, m.Len())
+	assert.Equal(t, uint64(0), m.Hash())
+	assert.Nil(t, m.OrderedMap())
+	assert.Nil(t, m.Delete(1))
+	_, ok := m.Get(1)
+	assert.False(t, ok)
+
+	a := m.Set(1, "a").Set(2, "b")
+	b := (&HashedOrderedMap[int, string]{}).Set(2, "b").Set(1, "a")
+	assert.Equal(t, 2, a.Len())
+	assert.NotEqual(t, uint64(0), a.Hash())
+	assert.Equal(t, a.Hash(), b.Hash())
+	assert.Equal(t, a.Hash(), NewHashedOrderedMap(a.OrderedMap(), maphash.Seed{}, nil).Hash())
+	assert.Equal(t, uint64(0), a.Delete(1).Delete(2).Hash())
+}
@@ -0,0 +1,169 @@
+package immutable
+
+import (
+	"time"
+	"unsafe"
+)
+
+// History records successive versions of a value, such as a persistent container, along with when
+// and why they were recorded. Because persistent containers share structure between versions,
+// retaining many versions of a large container is typically cheap. This makes History useful for
+// implementing undo stacks and audit trails.
+//
+// Changes between recorded versions of an OrderedMap can be efficiently computed with
+// OrderedMap.Diff.
+//
+// Nil and the zero value for History are both empty histories with unbounded retention.
+type History[T any] struct {
+	limit   int
+	next    int
+	entries *OrderedMap[int, HistoryEntry[T]]
+}
+
+// HistoryEntry is a version recorded by a History.
+type HistoryEntry[T any] struct {
+	// Sequence is the number of versions that were recorded before this one, including versions that
+	// are no longer retained.
+	Sequence int
+
+	Time    time.Time
+	Label   string
+	Version T
+}
+
+// NewHistory returns an empty history which retains at most limit versions. Once the limit is
+// reached, recording a new version discards the oldest. If limit is less than or equal to zero,
+// retention is unbounded.
+//
+// Complexity: O(1) worst-case
+func NewHistory[T any](limit int) *History[T] {
+	return &History[T]{
+		limit: limit,
+	}
+}
+
+// Len returns the number of retained versions.
+//
+// Complexity: O(1) worst-case
+func (h *History[T]) Len() int {
+	if h == nil {
+		return 0
+	}
+	return h.entries.Len()
+}
+
+// Limit returns the maximum number of versions that will be retained, or zero if there is no limit.
+//
+// Complexity: O(1) worst-case
+func (h *History[T]) Limit() int {
+	if h == nil || h.limit <= 0 {
+		return 0
+	}
+	return h.limit
+}
+
+// Record adds a new version to the history.
+//
+// Complexity: O(log n) worst-case
+func (h *History[T]) Record(version T, t time.Time, label string) *History[T] {
+	var ret History[T]
+	if h != nil {
+		ret = *h
+	}
+	ret.entries = ret.entries.Set(ret.next, HistoryEntry[T]{
+		Sequence: ret.next,
+		Time:     t,
+		Label:    label,
+		Version:  version,
+	})
+	ret.next++
+	if ret.limit > 0 && ret.entries.Len() > ret.limit {
+		ret.entries = ret.entries.Delete(ret.entries.Min().Key())
+	}
+	return &ret
+}
+
+// At returns the retained version at the given index, where 0 is the oldest. It panics if the index
+// is out of range.
+//
+// Complexity: O(log n) worst-case
+func (h *History[T]) At(i int) HistoryEntry[T] {
+	if i < 0 || i >= h.Len() {
+		panic("immutable: History index out of range")
+	}
+	return h.entries.at(i).value
+}
+
+// Latest returns the most recently recorded version, if any.
+//
+// Complexity: O(log n) worst-case
+func (h *History[T]) Latest() (HistoryEntry[T], bool) {
+	if h.Len() == 0 {
+		return HistoryEntry[T]{}, false
+	}
+	return h.entries.Max().Value(), true
+}
+
+// Truncate discards all but the oldest n retained versions. This can be used to discard the
+// versions that could be restored with "redo" once a new change is made after an "undo".
+//
+// Complexity: O(log n) worst-case
+func (h *History[T]) Truncate(n int) *History[T] {
+	if n < 0 {
+		n = 0
+	}
+	if n >= h.Len() {
+		return h
+	}
+	ret := *h
+	if n == 0 {
+		ret.entries = nil
+	} else {
+		ret.entries = ret.entries.DeleteRange(ret.entries.at(n).key, ret.next)
+	}
+	return &ret
+}
+
+// RetainedBytes estimates the memory retained by the history, including its retained versions.
+// Each version is added to a shared MemoryCounter by count, typically by calling CountMemory on the
+// containers within it, so structure shared between versions is only counted once.
+//
+// Complexity: O(n) worst-case, plus the cost of count for each version
+func (h *History[T]) RetainedBytes(count func(version T, c *MemoryCounter)) uintptr {
+	if h == nil {
+		return 0
+	}
+	var c MemoryCounter
+	h.entries.CountMemory(&c)
+	for e := h.entries.Max(); e != nil; e = e.Prev() {
+		count(e.Value().Version, &c)
+	}
+	return c.Bytes()
+}
+
+// TrimToBytes discards the oldest versions until the memory retained by the rest, as estimated by
+// RetainedBytes, is at most budget. The latest version is always retained, even if it alone exceeds
+// the budget. Calling this after recording each version enforces a memory budget on the history.
+//
+// Complexity: O(n) worst-case, plus the cost of count for each version
+func (h *History[T]) TrimToBytes(budget uintptr, count func(version T, c *MemoryCounter)) *History[T] {
+	if h.Len() == 0 {
+		return h
+	}
+	// Count the versions newest first. Since shared structure is attributed to the newest version
+	// that uses it, the running total at each version is exactly what retaining it and every newer
+	// version costs, along with a node of the entries map for each of them.
+	entrySize := unsafe.Sizeof(OrderedMap[int, HistoryEntry[T]]{})
+	var c MemoryCounter
+	keep := 0
+	for e := h.entries.Max(); e != nil; e = e.Prev() {
+		count(e.Value().Version, &c)
+		if keep > 0 && c.Bytes()+uintptr(keep+1)*entrySize > budget {
+			ret := *h
+			ret.entries = ret.entries.DeleteRange(ret.entries.Min().Key(), e.Key()+1)
+			return &ret
+		}
+		keep++
+	}
+	return h
+}
@@ -0,0 +1,119 @@
+package immutable
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	var h *History[*OrderedMap[string, int]]
+	assert.Equal(t, 0, h.Len())
+	assert.Equal(t, 0, h.Limit())
+	_, ok := h.Latest()
+	assert.False(t, ok)
+	assert.Panics(t, func() { h.At(0) })
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var m *OrderedMap[string, int]
+	for i := 0; i < 5; i++ {
+		m = m.Set(string(rune('a'+i)), i)
+		h = h.Record(m, start.Add(time.Duration(i)*time.Second), "set")
+	}
+	assert.Equal(t, 5, h.Len())
+	latest, ok := h.Latest()
+	assert.True(t, ok)
+	assert.Equal(t, 4, latest.Sequence)
+	assert.Equal(t, start.Add(4*time.Second), latest.Time)
+	assert.Equal(t, "set", latest.Label)
+	assert.Equal(t, 5, latest.Version.Len())
+	assert.Equal(t, 2, h.At(1).Version.Len())
+
+	var changed []string
+	h.At(1).Version.Diff(h.At(3).Version, nil, func(key string, old int, oldExists bool, new int, newExists bool) {
+		assert.False(t, oldExists)
+		assert.True(t, newExists)
+		changed = append(changed, key)
+	})
+	assert.Equal(t, []string{"c", "d"}, changed)
+
+	undone := h.Truncate(3)
+	assert.Equal(t, 3, undone.Len())
+	latest, _ = undone.Latest()
+	assert.Equal(t, 2, latest.Sequence)
+	redone := undone.Record(latest.Version.Set("z", 0), start, "redo")
+	latest, _ = redone.Latest()
+	assert.Equal(t, 5, latest.Sequence)
+	assert.Equal(t, 4, redone.Len())
+	assert.Equal(t, 0, h.Truncate(-1).Len())
+	assert.Equal(t, h, h.Truncate(10))
+
+	var empty *History[int]
+	assert.Nil(t, empty.Truncate(-1))
+	assert.Nil(t, empty.Truncate(0))
+	assert.Nil(t, empty.Truncate(1))
+}
+
+func TestHistory_Limit(t *testing.T) {
+	h := NewHistory[int](3)
+	assert.Equal(t, 3, h.Limit())
+	for i := 0; i < 10; i++ {
+		h = h.Record(i, time.Time{}, "")
+	}
+	assert.Equal(t, 3, h.Len())
+	assert.Equal(t, 7, h.At(0).Version)
+	assert.Equal(t, 7, h.At(0).Sequence)
+	assert.Equal(t, 9, h.At(2).Version)
+}
+
+func historyTestCount(m *OrderedMap[int, int], c *MemoryCounter) {
+	m.CountMemory(c)
+}
+
+func TestHistory_RetainedBytes(t *testing.T) {
+	var h *History[*OrderedMap[int, int]]
+	assert.Equal(t, uintptr(0), h.RetainedBytes(historyTestCount))
+	assert.Nil(t, h.TrimToBytes(0, historyTestCount))
+
+	var m *OrderedMap[int, int]
+	for i := 0; i < 1000; i++ {
+		m = m.Set(i, i)
+	}
+	for i := 0; i < 10; i++ {
+		m = m.Set(i, -i)
+		h = h.Record(m, time.Time{}, "")
+	}
+
+	// Versions share most of their nodes, so retaining all of them costs far less than retaining
+	// independent copies.
+	var c MemoryCounter
+	m.CountMemory(&c)
+	latest := c.Bytes()
+	total := h.RetainedBytes(historyTestCount)
+	assert.Greater(t, int(total), int(latest))
+	assert.Less(t, int(total), int(2*latest))
+
+	assert.Same(t, h, h.TrimToBytes(total, historyTestCount))
+
+	trimmed := h.TrimToBytes(total-1, historyTestCount)
+	assert.Equal(t, 9, trimmed.Len())
+	assert.LessOrEqual(t, int(trimmed.RetainedBytes(historyTestCount)), int(total-1))
+	assert.Equal(t, 1, trimmed.At(0).Sequence)
+	assert.Equal(t, 10, h.Len())
+
+	// The latest version is always retained.
+	trimmed = h.TrimToBytes(0, historyTestCount)
+	assert.Equal(t, 1, trimmed.Len())
+	latestEntry, _ := trimmed.Latest()
+	assert.Equal(t, 9, latestEntry.Sequence)
+
+	// Trimming keeps as many versions as fit within the budget.
+	for budget := latest + 100; budget < total; budget += 100 {
+		trimmed := h.TrimToBytes(budget, historyTestCount)
+		assert.LessOrEqual(t, int(trimmed.RetainedBytes(historyTestCount)), int(budget))
+		more := *h
+		more.entries = more.entries.TakeLast(trimmed.Len() + 1)
+		assert.Greater(t, int(more.RetainedBytes(historyTestCount)), int(budget))
+	}
+}
@@ -0,0 +1,261 @@
+// Package immutabletest provides randomized, model-based tests for persistent containers.
+//
+// Each runner applies a long sequence of random operations to a container and to a simple mutable
+// model of it, such as a built-in map or a slice, and fails the test as soon as they disagree. Every
+// version of the container produced along the way is retained and checked again at the end, which
+// catches operations that modify versions they should have left untouched.
+//
+// The runners are used to test the containers of package immutable, and can be used to test
+// wrappers around them or new containers with the same shape.
+package immutabletest
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Config controls the operations performed by a runner. The zero value is a reasonable default.
+type Config struct {
+	// Ops is the number of random operations to perform. If zero, 10000 operations are performed.
+	Ops int
+
+	// Seed seeds the random number generator, so that failures can be reproduced.
+	Seed int64
+
+	// KeySpace is the number of distinct keys used by map runners. Smaller key spaces result in more
+	// overwrites and deletions of existing keys. If zero, 100 keys are used.
+	KeySpace int
+}
+
+func (c Config) ops() int {
+	if c.Ops == 0 {
+		return 10000
+	}
+	return c.Ops
+}
+
+func (c Config) keySpace() int {
+	if c.KeySpace == 0 {
+		return 100
+	}
+	return c.KeySpace
+}
+
+// invariantChecker is implemented by containers which can verify their own structure.
+type invariantChecker interface {
+	CheckInvariants() error
+}
+
+func checkInvariants(t testing.TB, op int, container any) {
+	t.Helper()
+	if c, ok := container.(invariantChecker); ok {
+		if err := c.CheckInvariants(); err != nil {
+			t.Fatalf("op %v: invariants violated: %v", op, err)
+		}
+	}
+}
+
+// Map is the interface of a persistent map under test. M is the type of the map itself.
+type Map[K, V, M any] interface {
+	Len() int
+	Get(key K) (V, bool)
+	Set(key K, value V) M
+	Delete(key K) M
+}
+
+// orderedIterable is implemented by maps which can iterate over their elements in order of keys.
+type orderedIterable[K, V any] interface {
+	ForEach(f func(key K, value V) bool)
+}
+
+// RunMap tests a persistent map against a built-in map, starting with the given empty map. Keys
+// are integers from the configured key space converted by key, and values are produced by value.
+//
+// If the map implements CheckInvariants, it's checked after every operation. If it implements
+// ForEach, its elements are checked to be in ascending order of keys.
+func RunMap[K constraints.Ordered, V any, M Map[K, V, M]](t testing.TB, config Config, empty M, key func(i int) K, value func(r *rand.Rand) V) {
+	t.Helper()
+	r := rand.New(rand.NewSource(config.Seed))
+
+	type version struct {
+		m   M
+		ref map[K]V
+	}
+	versions := []version{{m: empty, ref: map[K]V{}}}
+	for op := 0; op < config.ops(); op++ {
+		// Usually build on the latest version, but sometimes branch from an older one.
+		base := versions[len(versions)-1]
+		if r.Intn(10) == 0 {
+			base = versions[r.Intn(len(versions))]
+		}
+		ref := make(map[K]V, len(base.ref)+1)
+		for k, v := range base.ref {
+			ref[k] = v
+		}
+		k := key(r.Intn(config.keySpace()))
+		var m M
+		if r.Intn(3) == 0 {
+			m = base.m.Delete(k)
+			delete(ref, k)
+		} else {
+			v := value(r)
+			m = base.m.Set(k, v)
+			ref[k] = v
+		}
+		checkInvariants(t, op, m)
+		checkMap(t, op, m, ref)
+		versions = append(versions, version{m: m, ref: ref})
+	}
+	for _, v := range versions {
+		checkMap(t, -1, v.m, v.ref)
+	}
+}
+
+func checkMap[K constraints.Ordered, V any, M Map[K, V, M]](t testing.TB, op int, m M, ref map[K]V) {
+	t.Helper()
+	if m.Len() != len(ref) {
+		t.Fatalf("op %v: map has length %v, expected %v", op, m.Len(), len(ref))
+	}
+	for k, expected := range ref {
+		if v, ok := m.Get(k); !ok {
+			t.Fatalf("op %v: map is missing key %v", op, k)
+		} else if !reflect.DeepEqual(v, expected) {
+			t.Fatalf("op %v: map has value %v for key %v, expected %v", op, v, k, expected)
+		}
+	}
+	if it, ok := any(m).(orderedIterable[K, V]); ok {
+		expected := make([]K, 0, len(ref))
+		for k := range ref {
+			expected = append(expected, k)
+		}
+		sort.Slice(expected, func(i, j int) bool {
+			return expected[i] < expected[j]
+		})
+		i := 0
+		it.ForEach(func(k K, _ V) bool {
+			if i >= len(expected) || k != expected[i] {
+				t.Fatalf("op %v: map iteration yielded key %v at index %v", op, k, i)
+			}
+			i++
+			return true
+		})
+		if i != len(expected) {
+			t.Fatalf("op %v: map iteration yielded %v keys, expected %v", op, i, len(expected))
+		}
+	}
+}
+
+// Queue is the interface of a persistent first in, first out container under test. Q is the type
+// of the queue itself.
+type Queue[T, Q any] interface {
+	Empty() bool
+	Front() T
+	PopFront() Q
+	PushBack(value T) Q
+}
+
+// RunQueue tests a persistent queue against a slice, starting with the given empty queue. Items are
+// produced by value.
+//
+// If the queue implements CheckInvariants, it's checked after every operation.
+func RunQueue[T any, Q Queue[T, Q]](t testing.TB, config Config, empty Q, value func(r *rand.Rand) T) {
+	t.Helper()
+	runSequence(t, config, empty, value, func(q Q) Q {
+		return q.PopFront()
+	}, func(q Q, v T) Q {
+		return q.PushBack(v)
+	}, func(q Q) (T, bool) {
+		if q.Empty() {
+			var zero T
+			return zero, false
+		}
+		return q.Front(), true
+	}, func(ref []T, v T) []T {
+		return append(ref, v)
+	})
+}
+
+// Stack is the interface of a persistent last in, first out container under test. S is the type
+// of the stack itself.
+type Stack[T, S any] interface {
+	Empty() bool
+	Peek() T
+	Pop() S
+	Push(value T) S
+}
+
+// RunStack tests a persistent stack against a slice, starting with the given empty stack. Items are
+// produced by value.
+//
+// If the stack implements CheckInvariants, it's checked after every operation.
+func RunStack[T any, S Stack[T, S]](t testing.TB, config Config, empty S, value func(r *rand.Rand) T) {
+	t.Helper()
+	runSequence(t, config, empty, value, func(s S) S {
+		return s.Pop()
+	}, func(s S, v T) S {
+		return s.Push(v)
+	}, func(s S) (T, bool) {
+		if s.Empty() {
+			var zero T
+			return zero, false
+		}
+		return s.Peek(), true
+	}, func(ref []T, v T) []T {
+		return append([]T{v}, ref...)
+	})
+}
+
+// runSequence tests a sequence container whose items are removed from the front of the model.
+// Items are added to the model by add.
+func runSequence[T, C any](t testing.TB, config Config, empty C, value func(r *rand.Rand) T, remove func(C) C, push func(C, T) C, front func(C) (T, bool), add func([]T, T) []T) {
+	t.Helper()
+	r := rand.New(rand.NewSource(config.Seed))
+
+	type version struct {
+		c   C
+		ref []T
+	}
+	versions := []version{{c: empty}}
+	for op := 0; op < config.ops(); op++ {
+		base := versions[len(versions)-1]
+		if r.Intn(10) == 0 {
+			base = versions[r.Intn(len(versions))]
+		}
+		var c C
+		var ref []T
+		if len(base.ref) > 0 && r.Intn(2) == 0 {
+			c = remove(base.c)
+			ref = append([]T(nil), base.ref[1:]...)
+		} else {
+			v := value(r)
+			c = push(base.c, v)
+			ref = add(append([]T(nil), base.ref...), v)
+		}
+		checkInvariants(t, op, c)
+		checkSequence(t, op, c, ref, remove, front)
+		versions = append(versions, version{c: c, ref: ref})
+	}
+	for _, v := range versions {
+		checkSequence(t, -1, v.c, v.ref, remove, front)
+	}
+}
+
+func checkSequence[T, C any](t testing.TB, op int, c C, ref []T, remove func(C) C, front func(C) (T, bool)) {
+	t.Helper()
+	for i, expected := range ref {
+		v, ok := front(c)
+		if !ok {
+			t.Fatalf("op %v: container has %v items, expected %v", op, i, len(ref))
+		} else if !reflect.DeepEqual(v, expected) {
+			t.Fatalf("op %v: container has item %v at index %v, expected %v", op, v, i, expected)
+		}
+		c = remove(c)
+	}
+	if _, ok := front(c); ok {
+		t.Fatalf("op %v: container has more than %v items", op, len(ref))
+	}
+}
@@ -0,0 +1,158 @@
+package immutabletest
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ccbrown/go-immutable"
+)
+
+func intKey(i int) int {
+	return i
+}
+
+func stringKey(i int) string {
+	return fmt.Sprint(i)
+}
+
+func randomInt(r *rand.Rand) int {
+	return r.Int()
+}
+
+func TestRunMap(t *testing.T) {
+	t.Run("OrderedMap", func(t *testing.T) {
+		RunMap(t, Config{}, (*immutable.OrderedMap[int, int])(nil), intKey, randomInt)
+	})
+	t.Run("OrderedMapStringKeys", func(t *testing.T) {
+		RunMap(t, Config{KeySpace: 1000}, (*immutable.OrderedMap[string, int])(nil), stringKey, randomInt)
+	})
+	t.Run("CompactOrderedMap", func(t *testing.T) {
+		RunMap(t, Config{KeySpace: 30}, (*immutable.CompactOrderedMap[int, int])(nil), intKey, randomInt)
+	})
+	t.Run("Treap", func(t *testing.T) {
+		RunMap(t, Config{}, (*immutable.Treap[int, int])(nil), intKey, randomInt)
+	})
+	t.Run("MinMaxOrderedMap", func(t *testing.T) {
+		RunMap(t, Config{Ops: 2000}, (*immutable.MinMaxOrderedMap[int, int])(nil), intKey, randomInt)
+	})
+	t.Run("HashedOrderedMap", func(t *testing.T) {
+		RunMap(t, Config{Ops: 2000}, (*immutable.HashedOrderedMap[int, int])(nil), intKey, randomInt)
+	})
+}
+
+func TestRunQueue(t *testing.T) {
+	t.Run("Queue", func(t *testing.T) {
+		RunQueue(t, Config{}, (*immutable.Queue[int])(nil), randomInt)
+	})
+	t.Run("ChunkedQueue", func(t *testing.T) {
+		RunQueue(t, Config{}, (*immutable.ChunkedQueue[int])(nil), randomInt)
+	})
+}
+
+func TestRunStack(t *testing.T) {
+	RunStack(t, Config{}, (*immutable.Stack[int])(nil), randomInt)
+}
+
+// Every container has its invariants checked by the runners rather than being skipped.
+var (
+	_ invariantChecker = (*immutable.OrderedMap[int, int])(nil)
+	_ invariantChecker = (*immutable.CompactOrderedMap[int, int])(nil)
+	_ invariantChecker = (*immutable.Treap[int, int])(nil)
+	_ invariantChecker = (*immutable.MinMaxOrderedMap[int, int])(nil)
+	_ invariantChecker = (*immutable.HashedOrderedMap[int, int])(nil)
+	_ invariantChecker = (*immutable.Queue[int])(nil)
+	_ invariantChecker = (*immutable.ChunkedQueue[int])(nil)
+)
+
+// fatalTB records the first failure and stops the runner by panicking.
+type fatalTB struct {
+	testing.TB
+	message string
+}
+
+type fatalTBFailure struct{}
+
+func (tb *fatalTB) Helper() {}
+
+func (tb *fatalTB) Fatalf(format string, args ...any) {
+	tb.message = fmt.Sprintf(format, args...)
+	panic(fatalTBFailure{})
+}
+
+func (tb *fatalTB) run(f func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(fatalTBFailure); !ok {
+				panic(r)
+			}
+		}
+	}()
+	f()
+}
+
+// mutableMap is a broken persistent map which modifies itself rather than returning new versions.
+type mutableMap struct {
+	m map[int]int
+}
+
+func (m *mutableMap) Len() int {
+	return len(m.m)
+}
+
+func (m *mutableMap) Get(key int) (int, bool) {
+	v, ok := m.m[key]
+	return v, ok
+}
+
+func (m *mutableMap) Set(key int, value int) *mutableMap {
+	m.m[key] = value
+	return m
+}
+
+func (m *mutableMap) Delete(key int) *mutableMap {
+	delete(m.m, key)
+	return m
+}
+
+func TestRunMap_Failure(t *testing.T) {
+	tb := &fatalTB{}
+	tb.run(func() {
+		RunMap(tb, Config{}, &mutableMap{m: map[int]int{}}, intKey, randomInt)
+	})
+	assert.NotEmpty(t, tb.message)
+}
+
+// leakyStack is a broken stack which never forgets its first item.
+type leakyStack struct {
+	items []int
+}
+
+func (s leakyStack) Empty() bool {
+	return len(s.items) == 0
+}
+
+func (s leakyStack) Peek() int {
+	return s.items[len(s.items)-1]
+}
+
+func (s leakyStack) Pop() leakyStack {
+	if len(s.items) == 1 {
+		return s
+	}
+	return leakyStack{s.items[:len(s.items)-1]}
+}
+
+func (s leakyStack) Push(value int) leakyStack {
+	return leakyStack{append(s.items[:len(s.items):len(s.items)], value)}
+}
+
+func TestRunStack_Failure(t *testing.T) {
+	tb := &fatalTB{}
+	tb.run(func() {
+		RunStack(tb, Config{}, leakyStack{}, randomInt)
+	})
+	assert.Contains(t, tb.message, "more than")
+}
@@ -0,0 +1,22 @@
+//go:build go1.23
+
+package immutable
+
+import "unique"
+
+// Intern returns a canonical copy of the given key, so that equal keys which are interned share the
+// same memory. When many versions of a string-keyed map are retained and keys are constructed
+// repeatedly, such as by decoding them from requests, interning them before they're set avoids
+// retaining a separate copy of each key for each version. Keys can be interned individually:
+//
+//	m = m.Set(immutable.Intern(key), value)
+//
+// or as they're added to an OrderedMapBuilder by setting its Intern field.
+//
+// Interning uses the unique package, so it requires Go 1.23, and canonical copies which are no
+// longer referenced are garbage collected.
+//
+// Complexity: O(n) expected, where n is the length of the key
+func Intern[K ~string](key K) K {
+	return K(unique.Make(string(key)).Value())
+}
@@ -0,0 +1,41 @@
+//go:build go1.23
+
+package immutable
+
+import (
+	"strconv"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntern(t *testing.T) {
+	a := Intern(strconv.Itoa(12345))
+	b := Intern(strconv.Itoa(12345))
+	assert.Equal(t, "12345", a)
+	assert.Equal(t, unsafe.StringData(a), unsafe.StringData(b))
+
+	k := Intern(BytesKey("foo"))
+	assert.Equal(t, BytesKey("foo"), k)
+	assert.Equal(t, unsafe.StringData(string(k)), unsafe.StringData(string(Intern(BytesKey([]byte("foo"))))))
+}
+
+func TestOrderedMapBuilder_Intern(t *testing.T) {
+	b := &OrderedMapBuilder[string, int]{
+		Intern: Intern[string],
+	}
+	for i := 0; i < 10; i++ {
+		b.Set(strconv.Itoa(i+1000), i)
+	}
+	m1 := b.Build()
+	for i := 0; i < 10; i++ {
+		b.Set(strconv.Itoa(i+1000), -i)
+	}
+	m2 := b.Build()
+
+	k1, _ := m1.MinKey()
+	k2, _ := m2.MinKey()
+	assert.Equal(t, "1000", k1)
+	assert.Equal(t, unsafe.StringData(k1), unsafe.StringData(k2))
+}
@@ -0,0 +1,190 @@
+//go:build go1.23
+
+package immutable
+
+import (
+	"iter"
+
+	"golang.org/x/exp/constraints"
+)
+
+// This file contains the functions which produce or consume iterators from package iter, which
+// require Go 1.23. Each container also has a ForEach method, or similar, for older versions of Go.
+
+// CollectOrderedMap returns a new OrderedMap containing the elements of the given sequence, like
+// maps.Collect. If a key is repeated, its last value is used. Like Set, it panics if a key is NaN.
+//
+// The elements are buffered and built into a balanced tree all at once, which is much faster than
+// setting them one at a time, especially if they're already in ascending order.
+//
+// Complexity: O(n) worst-case if the keys are in ascending order, O(n log n) otherwise
+func CollectOrderedMap[K constraints.Ordered, V any](seq iter.Seq2[K, V]) *OrderedMap[K, V] {
+	var b OrderedMapBuilder[K, V]
+	for key, value := range seq {
+		b.Set(key, value)
+	}
+	return b.Build()
+}
+
+// All returns an iterator over the elements of the map in ascending order of keys.
+//
+// Complexity: O(n) worst-case to iterate over every element
+func (m *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return m.ForEach
+}
+
+// KeysSeq returns an iterator over the keys of the map in ascending order.
+//
+// Complexity: O(n) worst-case to iterate over every key
+func (m *OrderedMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m.ForEach(func(key K, _ V) bool {
+			return yield(key)
+		})
+	}
+}
+
+// ValuesSeq returns an iterator over the values of the map in ascending order of keys.
+//
+// Complexity: O(n) worst-case to iterate over every value
+func (m *OrderedMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		m.ForEach(func(_ K, value V) bool {
+			return yield(value)
+		})
+	}
+}
+
+// All returns an iterator over the keys and values of the map in the same order as ForEach.
+//
+// Complexity: O(n) worst-case to iterate over every element
+func (m *OrderedMultimap[K, V]) All() iter.Seq2[K, V] {
+	return m.ForEach
+}
+
+// All returns an iterator over the elements of the map in ascending order of keys, with each key's
+// value taken from the topmost layer that contains it.
+//
+// Complexity: O(log k) worst-case per element of each layer, where k is the number of layers
+func (m *LayeredMap[K, V]) All() iter.Seq2[K, V] {
+	return m.ForEach
+}
+
+// MergeSorted returns an iterator which merges sequences that are each in ascending order of keys,
+// such as those returned by OrderedMap.All, into a single sequence in ascending order of keys. This
+// is useful for combining layered snapshots of a map.
+//
+// Elements with equal keys are all yielded, in the order of the sequences they come from, so that
+// when the sequences are given from newest to oldest, the newest value for each key comes first.
+//
+// Complexity: O(log k) worst-case per element, where k is the number of sequences
+func MergeSorted[K constraints.Ordered, V any](seqs ...iter.Seq2[K, V]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		nexts := make([]func() (K, V, bool), len(seqs))
+		for i, seq := range seqs {
+			next, stop := iter.Pull2(seq)
+			defer stop()
+			nexts[i] = next
+		}
+		mergeSorted(nexts, yield)
+	}
+}
+
+// CollectPairs returns the elements of the given sequence as a slice of pairs, in order.
+//
+// Complexity: O(n) worst-case
+func CollectPairs[K, V any](seq iter.Seq2[K, V]) []Pair[K, V] {
+	var ret []Pair[K, V]
+	for key, value := range seq {
+		ret = append(ret, MakePair(key, value))
+	}
+	return ret
+}
+
+// All returns an iterator over the items in the queue, starting with the front item. The order is
+// always the order in which the items would be popped.
+//
+// Complexity: O(n) worst-case to iterate over every item
+func (q *Queue[T]) All() iter.Seq[T] {
+	return q.ForEach
+}
+
+// Backward returns an iterator over the items in the queue, starting with the back item, i.e. the
+// most recently pushed one.
+//
+// Complexity: O(n) worst-case to iterate over every item
+func (q *Queue[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if q.Empty() {
+			return
+		}
+		// The rear is already stored back to front.
+		for s := q.r; !s.Empty(); s = s.Pop() {
+			if !yield(s.Peek()) {
+				return
+			}
+		}
+		var front []T
+		for l := q.f; l != nil; l = l.PopFront() {
+			front = append(front, l.Front())
+		}
+		for i := len(front) - 1; i >= 0; i-- {
+			if !yield(front[i]) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the items in the ring from oldest to newest.
+//
+// Complexity: O(n) worst-case to iterate over every item
+func (r *Ring[T]) All() iter.Seq[T] {
+	return r.ForEach
+}
+
+// Nodes returns an iterator over every node in the graph, in the same order as ForEachNode.
+//
+// Complexity: O(n) worst-case to iterate over every node
+func (g *Graph[N]) Nodes() iter.Seq[N] {
+	return g.ForEachNode
+}
+
+// Neighbors returns an iterator over the nodes that the given node has edges to.
+//
+// Complexity: O(log n + d) expected to iterate over every node, where d is the number of nodes the
+// given node has edges to
+func (g *Graph[N]) Neighbors(node N) iter.Seq[N] {
+	return func(yield func(N) bool) {
+		g.ForEachNeighbor(node, yield)
+	}
+}
+
+// Predecessors returns an iterator over the nodes that have edges to the given node.
+//
+// Complexity: O(log n + d) expected to iterate over every node, where d is the number of nodes with
+// edges to the given node
+func (g *Graph[N]) Predecessors(node N) iter.Seq[N] {
+	return func(yield func(N) bool) {
+		g.ForEachPredecessor(node, yield)
+	}
+}
+
+// IsSortedFunc reports whether the items of seq are in ascending order according to cmp, like
+// slices.IsSortedFunc, but without collecting them into a slice. It stops at the first item out of
+// order. This is useful for validating input before building a map from it, since OrderedMapBuilder
+// only needs to sort elements which aren't already in order.
+//
+// Complexity: O(n) worst-case
+func IsSortedFunc[T any](seq iter.Seq[T], cmp func(a, b T) int) bool {
+	first := true
+	var prev T
+	for v := range seq {
+		if !first && cmp(prev, v) > 0 {
+			return false
+		}
+		first = false
+		prev = v
+	}
+	return true
+}
@@ -0,0 +1,208 @@
+//go:build go1.23
+
+package immutable
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedMap_All(t *testing.T) {
+	var m *OrderedMap[int, int]
+	for range m.All() {
+		t.Fatal("empty maps should have no elements")
+	}
+
+	for i := 0; i < 100; i++ {
+		m = m.Set(i, i*2)
+	}
+	i := 0
+	for k, v := range m.All() {
+		assert.Equal(t, i, k)
+		assert.Equal(t, i*2, v)
+		if i++; i == 50 {
+			break
+		}
+	}
+	assert.Equal(t, 50, i)
+}
+
+func TestOrderedMap_KeysSeq(t *testing.T) {
+	var m *OrderedMap[string, int]
+	assert.Empty(t, slices.Collect(m.KeysSeq()))
+	assert.Empty(t, slices.Collect(m.ValuesSeq()))
+
+	m = m.Set("b", 2).Set("c", 3).Set("a", 1)
+	assert.Equal(t, []string{"a", "b", "c"}, slices.Collect(m.KeysSeq()))
+	assert.Equal(t, []int{1, 2, 3}, slices.Collect(m.ValuesSeq()))
+
+	var keys []string
+	for k := range m.KeysSeq() {
+		keys = append(keys, k)
+		break
+	}
+	assert.Equal(t, []string{"a"}, keys)
+
+	var values []int
+	for v := range m.ValuesSeq() {
+		values = append(values, v)
+		if v == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []int{1, 2}, values)
+}
+
+func TestCollectOrderedMap(t *testing.T) {
+	assert.Nil(t, CollectOrderedMap((*OrderedMap[int, int])(nil).All()))
+
+	ref := map[int]string{}
+	for i := 0; i < 100; i++ {
+		ref[i*7%100] = fmt.Sprint(i)
+	}
+	m := CollectOrderedMap(maps.All(ref))
+	require.NoError(t, m.CheckInvariants())
+	assert.Equal(t, ref, m.ToMap())
+
+	m2 := CollectOrderedMap(m.All())
+	require.NoError(t, m2.CheckInvariants())
+	assert.Equal(t, ref, m2.ToMap())
+
+	assert.Panics(t, func() {
+		CollectOrderedMap(maps.All(map[float64]int{math.NaN(): 1}))
+	})
+}
+
+func TestOrderedMultimap_All(t *testing.T) {
+	var m *OrderedMultimap[string, int]
+	assert.Empty(t, CollectPairs(m.All()))
+
+	m = m.Set("b", 1).Set("a", 2).Set("b", 3)
+	assert.Equal(t, []Pair[string, int]{{"a", 2}, {"b", 1}, {"b", 3}}, CollectPairs(m.All()))
+}
+
+func TestLayeredMap_All(t *testing.T) {
+	m := (*LayeredMap[string, int])(nil).Set("a", 1).Set("b", 2).Set("c", 3)
+	m = m.PushLayer().Set("b", 20).Delete("c").Set("d", 4)
+
+	var keys []string
+	var values []int
+	for k, v := range m.All() {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	assert.Equal(t, []string{"a", "b", "d"}, keys)
+	assert.Equal(t, []int{1, 20, 4}, values)
+
+	for range m.All() {
+		break
+	}
+}
+
+func TestCollectPairs(t *testing.T) {
+	var m *OrderedMap[string, int]
+	assert.Nil(t, CollectPairs(m.All()))
+
+	m = OrderedMapOf(MakePair("b", 2), MakePair("a", 1))
+	assert.Equal(t, []Pair[string, int]{{"a", 1}, {"b", 2}}, CollectPairs(m.All()))
+}
+
+func TestQueue_All(t *testing.T) {
+	var q *Queue[int]
+	assert.Empty(t, slices.Collect(q.All()))
+	assert.Empty(t, slices.Collect(q.Backward()))
+
+	q = &Queue[int]{}
+	var ref []int
+	for i := 0; i < 50; i++ {
+		require.Equal(t, ref, slices.Collect(q.All()))
+		backward := slices.Clone(ref)
+		slices.Reverse(backward)
+		require.Equal(t, backward, slices.Collect(q.Backward()))
+		if i%3 == 2 {
+			q = q.PopFront()
+			ref = ref[1:]
+		}
+		q = q.PushBack(i)
+		ref = append(ref, i)
+	}
+
+	for range q.All() {
+		break
+	}
+	n := 0
+	for range q.Backward() {
+		n++
+		if n == 3 {
+			break
+		}
+	}
+	assert.Equal(t, 3, n)
+}
+
+func TestRing_All(t *testing.T) {
+	r := NewRing[int](5)
+	assert.Empty(t, slices.Collect(r.All()))
+
+	// Iteration can stop early on either side of the wraparound.
+	r = r.Push(0).Push(1).Push(2).Push(3).Push(4).Push(5).Push(6)
+	assert.Equal(t, r.ToSlice(), slices.Collect(r.All()))
+	for n := 1; n <= 5; n++ {
+		var items []int
+		for v := range r.All() {
+			items = append(items, v)
+			if len(items) == n {
+				break
+			}
+		}
+		assert.Equal(t, []int{2, 3, 4, 5, 6}[:n], items)
+	}
+}
+
+func TestGraph_Iterators(t *testing.T) {
+	var g *Graph[string]
+	assert.Empty(t, slices.Collect(g.Neighbors("a")))
+	assert.Empty(t, slices.Collect(g.Predecessors("a")))
+	assert.Empty(t, slices.Collect(g.Nodes()))
+
+	g = g.AddEdge("a", "b").AddEdge("a", "c").AddEdge("c", "a")
+	assert.ElementsMatch(t, []string{"b", "c"}, slices.Collect(g.Neighbors("a")))
+	assert.Equal(t, []string{"c"}, slices.Collect(g.Predecessors("a")))
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, slices.Collect(g.Nodes()))
+
+	for range g.Nodes() {
+		break
+	}
+	for range g.Neighbors("a") {
+		break
+	}
+}
+
+func TestIsSortedFunc(t *testing.T) {
+	assert.True(t, IsSortedFunc(slices.Values([]int{}), cmp.Compare[int]))
+	assert.True(t, IsSortedFunc(slices.Values([]int{1}), cmp.Compare[int]))
+	assert.True(t, IsSortedFunc(slices.Values([]int{1, 2, 2, 3}), cmp.Compare[int]))
+	assert.False(t, IsSortedFunc(slices.Values([]int{1, 3, 2}), cmp.Compare[int]))
+
+	m := OrderedMapOf(MakePair(1, "a"), MakePair(2, "b"))
+	assert.True(t, IsSortedFunc(m.KeysSeq(), cmp.Compare[int]))
+
+	// It stops at the first item out of order.
+	n := 0
+	IsSortedFunc(func(yield func(int) bool) {
+		for _, v := range []int{2, 1, 0, -1} {
+			n++
+			if !yield(v) {
+				return
+			}
+		}
+	}, cmp.Compare[int])
+	assert.Equal(t, 2, n)
+}
@@ -0,0 +1,68 @@
+package immutable
+
+import "golang.org/x/exp/constraints"
+
+// Join returns a map containing the result of f for each key present in both a and b.
+//
+// The maps are iterated over in lockstep, and whenever one is behind the other, it seeks ahead
+// rather than visiting each intervening element. This makes joining a small map with a large one
+// efficient.
+//
+// Complexity: O(m log n) worst-case, where m is the size of the smaller map
+func Join[K constraints.Ordered, A, B, C any](a *OrderedMap[K, A], b *OrderedMap[K, B], f func(key K, a A, b B) C) *OrderedMap[K, C] {
+	var builder OrderedMapBuilder[K, C]
+	ia, ib := a.Iterator(), b.Iterator()
+	ok := ia.Next() && ib.Next()
+	for ok {
+		if ka, kb := ia.Key(), ib.Key(); ka < kb {
+			ia.Seek(kb)
+			ok = ia.Next()
+		} else if kb < ka {
+			ib.Seek(ka)
+			ok = ib.Next()
+		} else {
+			builder.Set(ka, f(ka, ia.Value(), ib.Value()))
+			ok = ia.Next() && ib.Next()
+		}
+	}
+	return builder.Build()
+}
+
+// LeftJoin returns a map containing the result of f for each key present in a. If the key is also
+// present in b, its value there is given to f.
+//
+// Complexity: O(n log m) worst-case, where m is the size of b
+func LeftJoin[K constraints.Ordered, A, B, C any](a *OrderedMap[K, A], b *OrderedMap[K, B], f func(key K, a A, b B, bExists bool) C) *OrderedMap[K, C] {
+	var builder OrderedMapBuilder[K, C]
+	a.ForEach(func(key K, value A) bool {
+		bValue, ok := b.Get(key)
+		builder.Set(key, f(key, value, bValue, ok))
+		return true
+	})
+	return builder.Build()
+}
+
+// OuterJoin returns a map containing the result of f for each key present in either a or b. The
+// values from both maps are given to f along with whether they exist.
+//
+// Complexity: O(n + m) worst-case
+func OuterJoin[K constraints.Ordered, A, B, C any](a *OrderedMap[K, A], b *OrderedMap[K, B], f func(key K, a A, aExists bool, b B, bExists bool) C) *OrderedMap[K, C] {
+	var builder OrderedMapBuilder[K, C]
+	var zeroA A
+	var zeroB B
+	ia, ib := a.Iterator(), b.Iterator()
+	okA, okB := ia.Next(), ib.Next()
+	for okA || okB {
+		if !okB || (okA && ia.Key() < ib.Key()) {
+			builder.Set(ia.Key(), f(ia.Key(), ia.Value(), true, zeroB, false))
+			okA = ia.Next()
+		} else if !okA || ib.Key() < ia.Key() {
+			builder.Set(ib.Key(), f(ib.Key(), zeroA, false, ib.Value(), true))
+			okB = ib.Next()
+		} else {
+			builder.Set(ia.Key(), f(ia.Key(), ia.Value(), true, ib.Value(), true))
+			okA, okB = ia.Next(), ib.Next()
+		}
+	}
+	return builder.Build()
+}
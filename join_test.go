@@ -0,0 +1,74 @@
+package immutable
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoin(t *testing.T) {
+	a := (*OrderedMap[int, string])(nil).Set(1, "a").Set(2, "b").Set(4, "d")
+	b := (*OrderedMap[int, int])(nil).Set(2, 20).Set(3, 30).Set(4, 40)
+
+	joined := Join(a, b, func(key int, a string, b int) string {
+		return fmt.Sprintf("%v%v", a, b)
+	})
+	assert.Equal(t, map[int]string{2: "b20", 4: "d40"}, joined.ToMap())
+	assert.Nil(t, Join(a, nil, func(key int, a string, b int) string { return a }))
+
+	left := LeftJoin(a, b, func(key int, a string, b int, bExists bool) string {
+		return fmt.Sprintf("%v%v%v", a, b, bExists)
+	})
+	assert.Equal(t, map[int]string{1: "a0false", 2: "b20true", 4: "d40true"}, left.ToMap())
+
+	outer := OuterJoin(a, b, func(key int, a string, aExists bool, b int, bExists bool) string {
+		return fmt.Sprintf("%v%v%v%v", a, aExists, b, bExists)
+	})
+	assert.Equal(t, map[int]string{
+		1: "atrue0false",
+		2: "btrue20true",
+		3: "false30true",
+		4: "dtrue40true",
+	}, outer.ToMap())
+	assert.Nil(t, OuterJoin[int, string, int](nil, nil, func(key int, a string, aExists bool, b int, bExists bool) string { return a }))
+}
+
+func TestJoin_Random(t *testing.T) {
+	var a, b *OrderedMap[int, int]
+	for i := 0; i < 1000; i++ {
+		a = a.Set(rand.Intn(2000), i)
+		if i%10 == 0 {
+			b = b.Set(rand.Intn(2000), i)
+		}
+	}
+
+	for _, pair := range [][2]*OrderedMap[int, int]{{a, b}, {b, a}} {
+		joined := Join(pair[0], pair[1], func(key, a, b int) [2]int {
+			return [2]int{a, b}
+		})
+		require.NoError(t, joined.CheckInvariants())
+		expected := map[int][2]int{}
+		pair[0].ForEach(func(key, value int) bool {
+			if other, ok := pair[1].Get(key); ok {
+				expected[key] = [2]int{value, other}
+			}
+			return true
+		})
+		assert.Equal(t, expected, joined.ToMap())
+
+		outer := OuterJoin(pair[0], pair[1], func(key, a int, aExists bool, b int, bExists bool) bool {
+			return aExists && bExists
+		})
+		require.NoError(t, outer.CheckInvariants())
+		assert.Equal(t, len(expected), Fold(outer, 0, func(acc, key int, both bool) int {
+			if both {
+				acc++
+			}
+			return acc
+		}))
+		assert.Equal(t, pair[0].Len()+pair[1].Len()-len(expected), outer.Len())
+	}
+}
@@ -0,0 +1,68 @@
+package immutable
+
+import "encoding/json"
+
+// MarshalJSON implements json.Marshaler. The stack is encoded as an array of its items, starting
+// with the top item.
+//
+// Complexity: O(n) worst-case
+func (s *Stack[T]) MarshalJSON() ([]byte, error) {
+	return marshalJSONItems(s.ToSlice())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The first item of the array will be at the top of the
+// stack.
+//
+// Because stacks are immutable, this must only be used to initialize a new stack that has not been
+// shared, such as a zero value.
+//
+// Unlike the binary encoding, there's no strict variant. The encoding is a plain array, with no
+// ordering or length fields that could be inconsistent, and malformed arrays are already rejected.
+//
+// Complexity: O(n) worst-case
+func (s *Stack[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	*s = Stack[T]{}
+	if decoded := StackFromSlice(items); decoded != nil {
+		*s = *decoded
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler. The queue is encoded as an array of its items, starting
+// with the front item.
+//
+// Complexity: O(n) worst-case
+func (q *Queue[T]) MarshalJSON() ([]byte, error) {
+	return marshalJSONItems(q.ToSlice())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The first item of the array will be at the front of
+// the queue.
+//
+// Because queues are immutable, this must only be used to initialize a new queue that has not been
+// shared, such as a zero value.
+//
+// Unlike the binary encoding, there's no strict variant. The encoding is a plain array, with no
+// ordering or length fields that could be inconsistent, and malformed arrays are already rejected.
+//
+// Complexity: O(n) worst-case
+func (q *Queue[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	*q = *QueueFromSlice(items)
+	return nil
+}
+
+func marshalJSONItems[T any](items []T) ([]byte, error) {
+	if items == nil {
+		// Empty containers are encoded as empty arrays rather than null.
+		items = []T{}
+	}
+	return json.Marshal(items)
+}
@@ -0,0 +1,54 @@
+package immutable
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStack_JSON(t *testing.T) {
+	var empty *Stack[int]
+	buf, err := json.Marshal(empty.Push(1).Pop())
+	require.NoError(t, err)
+	assert.JSONEq(t, `[]`, string(buf))
+
+	s := StackFromSlice([]string{"a", "b", "c"})
+	buf, err = json.Marshal(s)
+	require.NoError(t, err)
+	assert.JSONEq(t, `["a", "b", "c"]`, string(buf))
+
+	var decoded Stack[string]
+	require.NoError(t, json.Unmarshal(buf, &decoded))
+	assert.Equal(t, "a", decoded.Peek())
+	assert.Equal(t, s.ToSlice(), decoded.ToSlice())
+
+	var decodedEmpty Stack[string]
+	require.NoError(t, json.Unmarshal([]byte(`null`), &decodedEmpty))
+	assert.True(t, decodedEmpty.Empty())
+	assert.Error(t, json.Unmarshal([]byte(`[1]`), &decodedEmpty))
+}
+
+func TestQueue_JSON(t *testing.T) {
+	buf, err := json.Marshal(&Queue[int]{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `[]`, string(buf))
+
+	q := QueueFromSlice([]int{1, 2, 3})
+	buf, err = json.Marshal(q)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[1, 2, 3]`, string(buf))
+
+	var decoded Queue[int]
+	require.NoError(t, json.Unmarshal(buf, &decoded))
+	assert.Equal(t, 1, decoded.Front())
+	assert.Equal(t, []int{1, 2, 3, 4}, decoded.PushBack(4).ToSlice())
+
+	var document struct {
+		Pending *Queue[string] `json:"pending"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"pending": ["x", "y"]}`), &document))
+	assert.Equal(t, []string{"x", "y"}, document.Pending.ToSlice())
+	assert.Error(t, json.Unmarshal([]byte(`{"pending": "x"}`), &document))
+}
@@ -0,0 +1,174 @@
+package immutable
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+// KeyedHeap implements a priority queue in which each item is identified by a unique key. Items can
+// be looked up, reprioritized, or removed by key, and the item with the lowest priority can be
+// retrieved efficiently. This is useful for structures like timer wheels and rate limiters.
+//
+// Keys can be any comparable type. Items with equal priorities are ordered by when their priorities
+// were set, first come first served.
+//
+// Nil and the zero value for KeyedHeap are both empty heaps.
+type KeyedHeap[K comparable, P constraints.Ordered] struct {
+	items *hashMap[K, keyedHeapItem[P]]
+
+	// The keys with each priority, keyed by the sequence numbers of the items.
+	byPriority *OrderedMap[P, *OrderedMap[uint64, K]]
+
+	// The sequence number to assign to the next item whose priority is set.
+	seq uint64
+}
+
+type keyedHeapItem[P constraints.Ordered] struct {
+	priority P
+	seq      uint64
+}
+
+// Empty returns true if the heap is empty.
+//
+// Complexity: O(1) worst-case
+func (h *KeyedHeap[K, P]) Empty() bool {
+	return h.Len() == 0
+}
+
+// Len returns the number of items in the heap.
+//
+// Complexity: O(1) worst-case
+func (h *KeyedHeap[K, P]) Len() int {
+	if h == nil {
+		return 0
+	}
+	return h.items.Len()
+}
+
+// Priority returns the priority of the item with the given key if it exists.
+//
+// Complexity: O(log n) expected
+func (h *KeyedHeap[K, P]) Priority(key K) (P, bool) {
+	if h == nil {
+		var zero P
+		return zero, false
+	}
+	item, ok := h.items.Get(key)
+	return item.priority, ok
+}
+
+// SetPriority adds an item with the given key and priority, or changes the priority of the existing
+// item with the given key. Either way, the item is placed after any others with the same priority.
+// Like OrderedMap.Set, it panics if the key or priority is NaN.
+//
+// Complexity: O(log n) expected
+func (h *KeyedHeap[K, P]) SetPriority(key K, priority P) *KeyedHeap[K, P] {
+	orderedMapCheckKey(priority)
+	ret := h.without(key)
+	item := keyedHeapItem[P]{
+		priority: priority,
+		seq:      ret.seq,
+	}
+	ret.seq++
+	keys, _ := ret.byPriority.Get(priority)
+	ret.items = ret.items.Set(key, item)
+	ret.byPriority = ret.byPriority.Set(priority, keys.Set(item.seq, key))
+	return ret
+}
+
+// DeleteKey removes the item with the given key.
+//
+// Complexity: O(log n) expected
+func (h *KeyedHeap[K, P]) DeleteKey(key K) *KeyedHeap[K, P] {
+	if _, ok := h.Priority(key); !ok {
+		return h
+	}
+	return h.without(key)
+}
+
+// without returns a copy of the heap without the given key.
+func (h *KeyedHeap[K, P]) without(key K) *KeyedHeap[K, P] {
+	var ret KeyedHeap[K, P]
+	if h != nil {
+		ret = *h
+	}
+	if item, ok := ret.items.Get(key); ok {
+		ret.items = ret.items.Delete(key)
+		keys, _ := ret.byPriority.Get(item.priority)
+		if keys = keys.Delete(item.seq); keys.Empty() {
+			ret.byPriority = ret.byPriority.Delete(item.priority)
+		} else {
+			ret.byPriority = ret.byPriority.Set(item.priority, keys)
+		}
+	}
+	return &ret
+}
+
+// PeekLowest returns the key and priority of the item with the lowest priority. If there are
+// multiple, the one whose priority was set first is returned. If the heap is empty, false is
+// returned.
+//
+// Complexity: O(log n) worst-case
+func (h *KeyedHeap[K, P]) PeekLowest() (key K, priority P, ok bool) {
+	if h.Empty() {
+		return key, priority, false
+	}
+	lowest := h.byPriority.Min()
+	return lowest.Value().Min().Value(), lowest.Key(), true
+}
+
+// Pop removes the item returned by PeekLowest.
+//
+// Complexity: O(log n) expected
+func (h *KeyedHeap[K, P]) Pop() *KeyedHeap[K, P] {
+	key, _, ok := h.PeekLowest()
+	if !ok {
+		return h
+	}
+	return h.without(key)
+}
+
+// CheckInvariants verifies the structural integrity of the heap, returning an error describing the
+// first problem found. It checks that the priorities are in order, that each item is indexed by its
+// priority and sequence number exactly once, and that every sequence number was assigned before the
+// next one to be assigned.
+//
+// Complexity: O(n log n) expected
+func (h *KeyedHeap[K, P]) CheckInvariants() error {
+	if h == nil {
+		return nil
+	} else if err := h.items.checkInvariants(); err != nil {
+		return err
+	} else if err := h.byPriority.CheckInvariants(); err != nil {
+		return err
+	}
+	n := 0
+	var err error
+	h.byPriority.ForEach(func(priority P, keys *OrderedMap[uint64, K]) bool {
+		if err = keys.CheckInvariants(); err != nil {
+			return false
+		} else if keys.Empty() {
+			err = fmt.Errorf("priority %v has no items", priority)
+			return false
+		}
+		keys.ForEach(func(seq uint64, key K) bool {
+			if item, ok := h.items.Get(key); !ok {
+				err = fmt.Errorf("key %v is indexed, but isn't in the heap", key)
+			} else if item.priority != priority || item.seq != seq {
+				err = fmt.Errorf("key %v is indexed with priority %v and sequence number %v, but has priority %v and sequence number %v", key, priority, seq, item.priority, item.seq)
+			} else if seq >= h.seq {
+				err = fmt.Errorf("key %v has sequence number %v, but the next is %v", key, seq, h.seq)
+			}
+			n++
+			return err == nil
+		})
+		return err == nil
+	})
+	if err != nil {
+		return err
+	} else if n != h.items.Len() {
+		return fmt.Errorf("heap has %v items, but %v are indexed", h.items.Len(), n)
+	}
+	return nil
+}
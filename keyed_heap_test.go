@@ -0,0 +1,113 @@
+package immutable
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyedHeap(t *testing.T) {
+	var h *KeyedHeap[string, int]
+	assert.True(t, h.Empty())
+	_, _, ok := h.PeekLowest()
+	assert.False(t, ok)
+	assert.Nil(t, h.Pop())
+	assert.Nil(t, h.DeleteKey("a"))
+
+	h2 := h.SetPriority("a", 3).SetPriority("b", 1).SetPriority("c", 2)
+	assert.True(t, h.Empty())
+	assert.Equal(t, 3, h2.Len())
+	p, ok := h2.Priority("a")
+	assert.True(t, ok)
+	assert.Equal(t, 3, p)
+
+	key, p, ok := h2.PeekLowest()
+	assert.True(t, ok)
+	assert.Equal(t, "b", key)
+	assert.Equal(t, 1, p)
+
+	key, _, _ = h2.Pop().PeekLowest()
+	assert.Equal(t, "c", key)
+	key, _, _ = h2.SetPriority("a", 0).PeekLowest()
+	assert.Equal(t, "a", key)
+	key, _, _ = h2.SetPriority("a", 1).PeekLowest()
+	assert.Equal(t, "b", key)
+	key, _, _ = h2.SetPriority("a", 1).SetPriority("b", 1).PeekLowest()
+	assert.Equal(t, "a", key)
+	key, _, _ = h2.DeleteKey("b").PeekLowest()
+	assert.Equal(t, "c", key)
+	assert.Equal(t, h2, h2.DeleteKey("z"))
+	require.NoError(t, h2.CheckInvariants())
+
+	// An item whose priority doesn't match its place in the heap is detected.
+	corrupted := *h2
+	corrupted.items = corrupted.items.Set("a", keyedHeapItem[int]{priority: 0})
+	assert.Error(t, corrupted.CheckInvariants())
+	assert.Panics(t, func() {
+		(*KeyedHeap[string, float64])(nil).SetPriority("a", math.NaN())
+	})
+}
+
+func TestKeyedHeap_Comparable(t *testing.T) {
+	type timer struct {
+		host string
+		port int
+	}
+	var h *KeyedHeap[timer, int]
+	h = h.SetPriority(timer{"a", 80}, 2).SetPriority(timer{"a", 443}, 1)
+	key, p, ok := h.PeekLowest()
+	assert.True(t, ok)
+	assert.Equal(t, timer{"a", 443}, key)
+	assert.Equal(t, 1, p)
+	key, _, _ = h.Pop().PeekLowest()
+	assert.Equal(t, timer{"a", 80}, key)
+}
+
+func TestKeyedHeap_Fuzz(t *testing.T) {
+	ref := map[int]int{}
+	seqs := map[int]int{}
+	var h *KeyedHeap[int, int]
+	for i := 0; i < 10000; i++ {
+		switch rand.Intn(4) {
+		case 0:
+			k := rand.Intn(100)
+			delete(ref, k)
+			h = h.DeleteKey(k)
+		case 1:
+			if len(ref) > 0 {
+				key, _, ok := h.PeekLowest()
+				require.True(t, ok)
+				delete(ref, key)
+			}
+			h = h.Pop()
+		default:
+			k, p := rand.Intn(100), rand.Intn(20)
+			ref[k] = p
+			seqs[k] = i
+			h = h.SetPriority(k, p)
+		}
+		require.NoError(t, h.CheckInvariants())
+		require.Equal(t, len(ref), h.Len())
+		if len(ref) == 0 {
+			continue
+		}
+		keys := make([]int, 0, len(ref))
+		for k := range ref {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if ref[keys[i]] != ref[keys[j]] {
+				return ref[keys[i]] < ref[keys[j]]
+			}
+			return seqs[keys[i]] < seqs[keys[j]]
+		})
+		key, p, ok := h.PeekLowest()
+		require.True(t, ok)
+		require.Equal(t, keys[0], key)
+		require.Equal(t, ref[keys[0]], p)
+	}
+}
@@ -0,0 +1,99 @@
+package immutable
+
+import (
+	"encoding/binary"
+	"math/big"
+)
+
+// BytesKey is a byte slice which can be used as the key of an OrderedMap or any other container
+// that requires ordered keys. Keys are ordered lexicographically by byte, like bytes.Compare, with
+// shorter keys ordered before longer keys that they are a prefix of.
+//
+// Since BytesKey is a string type, it's immutable, and converting to and from byte slices copies
+// them. This means the key of a map can never be modified by the code that inserted it.
+type BytesKey string
+
+// NewBytesKey returns a key containing a copy of the given bytes.
+//
+// Complexity: O(n) worst-case
+func NewBytesKey(b []byte) BytesKey {
+	return BytesKey(b)
+}
+
+// Bytes returns a copy of the key's bytes.
+//
+// Complexity: O(n) worst-case
+func (k BytesKey) Bytes() []byte {
+	return []byte(k)
+}
+
+// BigIntKey is an arbitrary-precision integer which can be used as the key of an OrderedMap or any
+// other container that requires ordered keys. Keys are ordered numerically.
+//
+// The integer is stored in an encoding whose byte order matches numeric order, so BigIntKey must
+// be created with NewBigIntKey. The zero value is not a valid key.
+type BigIntKey string
+
+const (
+	bigIntKeyNegative = 0x7f
+	bigIntKeyZero     = 0x80
+	bigIntKeyPositive = 0x81
+)
+
+// NewBigIntKey returns a key for the given integer.
+//
+// Complexity: O(n) worst-case, where n is the number of bytes in the integer
+func NewBigIntKey(x *big.Int) BigIntKey {
+	sign := x.Sign()
+	if sign == 0 {
+		return BigIntKey([]byte{bigIntKeyZero})
+	}
+	// Integers with more bytes have greater magnitudes, so the length is encoded before the
+	// magnitude. For negative integers, both are complemented so that greater magnitudes sort
+	// first.
+	magnitude := x.Bytes()
+	buf := make([]byte, 9, 9+len(magnitude))
+	binary.BigEndian.PutUint64(buf[1:], uint64(len(magnitude)))
+	buf = append(buf, magnitude...)
+	if sign > 0 {
+		buf[0] = bigIntKeyPositive
+	} else {
+		buf[0] = bigIntKeyNegative
+		for i := 1; i < len(buf); i++ {
+			buf[i] = ^buf[i]
+		}
+	}
+	return BigIntKey(buf)
+}
+
+// Int returns the integer represented by the key. It panics if the key wasn't created by
+// NewBigIntKey.
+//
+// Complexity: O(n) worst-case, where n is the number of bytes in the integer
+func (k BigIntKey) Int() *big.Int {
+	if k == BigIntKey([]byte{bigIntKeyZero}) {
+		return new(big.Int)
+	} else if len(k) < 10 || (k[0] != bigIntKeyNegative && k[0] != bigIntKeyPositive) {
+		panic("immutable: invalid BigIntKey")
+	}
+	buf := []byte(k[1:])
+	if k[0] == bigIntKeyNegative {
+		for i := range buf {
+			buf[i] = ^buf[i]
+		}
+	}
+	magnitude := buf[8:]
+	if binary.BigEndian.Uint64(buf) != uint64(len(magnitude)) || magnitude[0] == 0 {
+		panic("immutable: invalid BigIntKey")
+	}
+	ret := new(big.Int).SetBytes(magnitude)
+	if k[0] == bigIntKeyNegative {
+		ret.Neg(ret)
+	}
+	return ret
+}
+
+// String returns the key's integer in base 10.
+func (k BigIntKey) String() string {
+	return k.Int().String()
+}
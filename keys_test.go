@@ -0,0 +1,69 @@
+package immutable
+
+import (
+	"bytes"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBytesKey(t *testing.T) {
+	b := []byte{1, 2, 3}
+	k := NewBytesKey(b)
+	b[0] = 9
+	assert.Equal(t, []byte{1, 2, 3}, k.Bytes())
+
+	for i := 0; i < 1000; i++ {
+		a, b := make([]byte, rand.Intn(4)), make([]byte, rand.Intn(4))
+		rand.Read(a)
+		rand.Read(b)
+		require.Equal(t, bytes.Compare(a, b) < 0, NewBytesKey(a) < NewBytesKey(b))
+	}
+
+	var m *OrderedMap[BytesKey, int]
+	m = m.Set(NewBytesKey([]byte("b")), 2)
+	m = m.Set(NewBytesKey([]byte("ab")), 1)
+	m = m.Set(NewBytesKey([]byte{}), 0)
+	var keys [][]byte
+	m.ForEach(func(key BytesKey, _ int) bool {
+		keys = append(keys, key.Bytes())
+		return true
+	})
+	assert.Equal(t, [][]byte{{}, []byte("ab"), []byte("b")}, keys)
+}
+
+func TestBigIntKey(t *testing.T) {
+	var values []*big.Int
+	for _, s := range []string{"0", "1", "-1", "255", "256", "-255", "-256", "123456789012345678901234567890", "-123456789012345678901234567890"} {
+		x, ok := new(big.Int).SetString(s, 10)
+		require.True(t, ok)
+		values = append(values, x)
+	}
+	for i := 0; i < 200; i++ {
+		x := new(big.Int).Rand(rand.New(rand.NewSource(int64(i))), new(big.Int).Lsh(big.NewInt(1), uint(rand.Intn(200))))
+		if rand.Intn(2) == 0 {
+			x.Neg(x)
+		}
+		values = append(values, x)
+	}
+
+	for _, a := range values {
+		ka := NewBigIntKey(a)
+		require.Equal(t, 0, a.Cmp(ka.Int()), a.String())
+		require.Equal(t, a.String(), ka.String())
+		for _, b := range values {
+			kb := NewBigIntKey(b)
+			require.Equal(t, a.Cmp(b) < 0, ka < kb, "%v < %v", a, b)
+			require.Equal(t, a.Cmp(b) == 0, ka == kb, "%v == %v", a, b)
+		}
+	}
+
+	for _, k := range []BigIntKey{"", "x", BigIntKey([]byte{bigIntKeyPositive, 0, 0, 0, 0, 0, 0, 0, 2, 1})} {
+		assert.Panics(t, func() {
+			k.Int()
+		})
+	}
+}
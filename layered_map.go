@@ -0,0 +1,191 @@
+package immutable
+
+import "golang.org/x/exp/constraints"
+
+// LayeredMap implements a map made up of a base OrderedMap and a stack of overlay layers. Changes
+// are made to the topmost layer, and deletions are recorded as tombstones so that they hide
+// elements in the layers below. Layers can be pushed and popped, which makes LayeredMap useful for
+// configuration overlays and nested transactions that may be rolled back.
+//
+// Nil and the zero value for LayeredMap are both empty maps with no overlay layers.
+type LayeredMap[K constraints.Ordered, V any] struct {
+	base     *OrderedMap[K, V]
+	overlays *Stack[*OrderedMap[K, layeredMapEntry[V]]]
+	layers   int
+}
+
+type layeredMapEntry[V any] struct {
+	value   V
+	deleted bool
+}
+
+// NewLayeredMap returns a layered map with the given base and no overlay layers.
+//
+// Complexity: O(1) worst-case
+func NewLayeredMap[K constraints.Ordered, V any](base *OrderedMap[K, V]) *LayeredMap[K, V] {
+	return &LayeredMap[K, V]{
+		base: base,
+	}
+}
+
+// Base returns the base map, which doesn't reflect any changes in the overlay layers.
+//
+// Complexity: O(1) worst-case
+func (m *LayeredMap[K, V]) Base() *OrderedMap[K, V] {
+	if m == nil {
+		return nil
+	}
+	return m.base
+}
+
+// Layers returns the number of overlay layers.
+//
+// Complexity: O(1) worst-case
+func (m *LayeredMap[K, V]) Layers() int {
+	if m == nil {
+		return 0
+	}
+	return m.layers
+}
+
+// PushLayer returns a map with a new, empty overlay layer on top. Subsequent changes are made to
+// the new layer.
+//
+// Complexity: O(1) worst-case
+func (m *LayeredMap[K, V]) PushLayer() *LayeredMap[K, V] {
+	return &LayeredMap[K, V]{
+		base:     m.Base(),
+		overlays: m.overlayStack().Push(nil),
+		layers:   m.Layers() + 1,
+	}
+}
+
+// PopLayer returns a map with the topmost overlay layer and all of the changes made to it
+// discarded. If there are no overlay layers, the map is returned unchanged.
+//
+// Complexity: O(1) worst-case
+func (m *LayeredMap[K, V]) PopLayer() *LayeredMap[K, V] {
+	if m.Layers() == 0 {
+		return m
+	}
+	return &LayeredMap[K, V]{
+		base:     m.base,
+		overlays: m.overlays.Pop(),
+		layers:   m.layers - 1,
+	}
+}
+
+func (m *LayeredMap[K, V]) overlayStack() *Stack[*OrderedMap[K, layeredMapEntry[V]]] {
+	if m == nil {
+		return nil
+	}
+	return m.overlays
+}
+
+// Get returns the value associated with the given key if set.
+//
+// Complexity: O(k log n) worst-case, where k is the number of layers
+func (m *LayeredMap[K, V]) Get(key K) (V, bool) {
+	for s := m.overlayStack(); !s.Empty(); s = s.Pop() {
+		if entry, ok := s.Peek().Get(key); ok {
+			return entry.value, !entry.deleted
+		}
+	}
+	return m.Base().Get(key)
+}
+
+// Set associates a value with the given key in the topmost layer. It panics if the key is NaN.
+//
+// Complexity: O(log n) worst-case
+func (m *LayeredMap[K, V]) Set(key K, value V) *LayeredMap[K, V] {
+	if m.Layers() == 0 {
+		return &LayeredMap[K, V]{
+			base: m.Base().Set(key, value),
+		}
+	}
+	return m.withTop(m.overlays.Peek().Set(key, layeredMapEntry[V]{
+		value: value,
+	}))
+}
+
+// Delete removes the given key. If there are overlay layers, a tombstone is recorded in the topmost
+// one to hide the key in the layers below.
+//
+// Complexity: O(log n) worst-case
+func (m *LayeredMap[K, V]) Delete(key K) *LayeredMap[K, V] {
+	if m.Layers() == 0 {
+		return &LayeredMap[K, V]{
+			base: m.Base().Delete(key),
+		}
+	} else if key != key {
+		return m
+	}
+	return m.withTop(m.overlays.Peek().Set(key, layeredMapEntry[V]{
+		deleted: true,
+	}))
+}
+
+func (m *LayeredMap[K, V]) withTop(top *OrderedMap[K, layeredMapEntry[V]]) *LayeredMap[K, V] {
+	return &LayeredMap[K, V]{
+		base:     m.base,
+		overlays: m.overlays.Pop().Push(top),
+		layers:   m.layers,
+	}
+}
+
+// ForEach calls f for each element of the map in ascending order of keys, with each key's value
+// taken from the topmost layer that contains it, stopping early if f returns false.
+//
+// Complexity: O(log k) worst-case per element of each layer, where k is the number of layers
+func (m *LayeredMap[K, V]) ForEach(f func(key K, value V) bool) {
+	nexts := make([]func() (K, layeredMapEntry[V], bool), 0, m.Layers()+1)
+	for s := m.overlayStack(); !s.Empty(); s = s.Pop() {
+		it := s.Peek().Iterator()
+		nexts = append(nexts, func() (key K, entry layeredMapEntry[V], ok bool) {
+			if !it.Next() {
+				return key, entry, false
+			}
+			return it.Key(), it.Value(), true
+		})
+	}
+	base := m.Base().Iterator()
+	nexts = append(nexts, func() (key K, entry layeredMapEntry[V], ok bool) {
+		if !base.Next() {
+			return key, entry, false
+		}
+		return base.Key(), layeredMapEntry[V]{value: base.Value()}, true
+	})
+	first := true
+	var prev K
+	mergeSorted(nexts, func(key K, entry layeredMapEntry[V]) bool {
+		// Equal keys are merged from the topmost layer down, so only the first one counts.
+		if !first && !(prev < key) {
+			return true
+		}
+		first, prev = false, key
+		return entry.deleted || f(key, entry.value)
+	})
+}
+
+// Compact returns an equivalent map with all of the overlay layers applied to the base, leaving no
+// overlay layers.
+//
+// Complexity: O(m log n) worst-case, where m is the total number of elements in the overlay layers
+func (m *LayeredMap[K, V]) Compact() *LayeredMap[K, V] {
+	if m.Layers() == 0 {
+		return m
+	}
+	overlays := m.overlays.ToSlice()
+	base := m.base
+	for i := len(overlays) - 1; i >= 0; i-- {
+		overlays[i].ForEach(func(key K, entry layeredMapEntry[V]) bool {
+			if entry.deleted {
+				base = base.Delete(key)
+			} else {
+				base = base.Set(key, entry.value)
+			}
+			return true
+		})
+	}
+	return NewLayeredMap(base)
+}
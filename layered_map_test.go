@@ -0,0 +1,117 @@
+package immutable
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func layeredMapToMap(m *LayeredMap[int, int]) map[int]int {
+	ret := map[int]int{}
+	m.ForEach(func(k, v int) bool {
+		ret[k] = v
+		return true
+	})
+	return ret
+}
+
+func TestLayeredMap(t *testing.T) {
+	var m *LayeredMap[string, int]
+	assert.Equal(t, 0, m.Layers())
+	assert.Nil(t, m.Base())
+	_, ok := m.Get("a")
+	assert.False(t, ok)
+	m.ForEach(func(string, int) bool {
+		t.Fatal("empty maps should have no elements")
+		return true
+	})
+	assert.Equal(t, m, m.PopLayer())
+
+	m = m.Set("a", 1).Set("b", 2).Set("c", 3)
+	assert.Equal(t, 0, m.Layers())
+	assert.Equal(t, 3, m.Base().Len())
+
+	overlay := m.PushLayer().Set("b", 20).Delete("c").Set("d", 4)
+	assert.Equal(t, 1, overlay.Layers())
+	assert.Equal(t, 3, overlay.Base().Len())
+	v, ok := overlay.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 20, v)
+	_, ok = overlay.Get("c")
+	assert.False(t, ok)
+
+	var keys []string
+	var values []int
+	overlay.ForEach(func(k string, v int) bool {
+		keys = append(keys, k)
+		values = append(values, v)
+		return true
+	})
+	assert.Equal(t, []string{"a", "b", "d"}, keys)
+	assert.Equal(t, []int{1, 20, 4}, values)
+
+	nested := overlay.PushLayer().Set("c", 30).Delete("a")
+	keys = nil
+	nested.ForEach(func(k string, _ int) bool {
+		keys = append(keys, k)
+		return k < "c"
+	})
+	assert.Equal(t, []string{"b", "c"}, keys)
+
+	rolledBack := nested.PopLayer()
+	assert.Equal(t, 1, rolledBack.Layers())
+	v, ok = rolledBack.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	compacted := nested.Compact()
+	assert.Equal(t, 0, compacted.Layers())
+	require.NoError(t, compacted.Base().CheckInvariants())
+	assert.Equal(t, map[string]int{"b": 20, "c": 30, "d": 4}, compacted.Base().ToMap())
+	assert.Equal(t, 3, m.Base().Len())
+}
+
+func TestLayeredMap_Fuzz(t *testing.T) {
+	type version struct {
+		m   *LayeredMap[int, int]
+		ref map[int]int
+	}
+	stack := []version{{m: nil, ref: map[int]int{}}}
+	for i := 0; i < 2000; i++ {
+		top := &stack[len(stack)-1]
+		switch n := rand.Intn(20); {
+		case n == 0:
+			ref := map[int]int{}
+			for k, v := range top.ref {
+				ref[k] = v
+			}
+			stack = append(stack, version{m: top.m.PushLayer(), ref: ref})
+		case n == 1 && len(stack) > 1:
+			stack = stack[:len(stack)-1]
+			require.Equal(t, stack[len(stack)-1].ref, layeredMapToMap(top.m.PopLayer()))
+		case n == 2:
+			// Compacting merges all of the layers, so there's nothing left to roll back to.
+			stack = []version{{m: top.m.Compact(), ref: top.ref}}
+			require.Equal(t, 0, stack[0].m.Layers())
+		case n < 8:
+			k := rand.Intn(50)
+			top.m = top.m.Delete(k)
+			delete(top.ref, k)
+		default:
+			k, v := rand.Intn(50), rand.Int()
+			top.m = top.m.Set(k, v)
+			top.ref[k] = v
+		}
+		top = &stack[len(stack)-1]
+		k := rand.Intn(50)
+		v, ok := top.m.Get(k)
+		expected, expectedOK := top.ref[k]
+		require.Equal(t, expectedOK, ok)
+		require.Equal(t, expected, v)
+	}
+	top := stack[len(stack)-1]
+	assert.Equal(t, top.ref, layeredMapToMap(top.m))
+	assert.Equal(t, top.ref, layeredMapToMap(top.m.Compact()))
+}
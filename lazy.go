@@ -0,0 +1,222 @@
+package immutable
+
+import "sync"
+
+// Lazy implements a lazily evaluated, memoized stream of items.
+//
+// Each item is computed at most once, the first time it's needed, and the result is shared by all
+// references to the stream. Streams like this are the building block for structures such as Queue,
+// which use lazy evaluation to turn amortized bounds into worst-case bounds while remaining
+// persistent.
+//
+// Evaluation is safe for concurrent use. Functions given to Lazy's constructors and methods must not
+// depend on the order in which goroutines force evaluation.
+//
+// Nil and the zero value for Lazy are both empty streams.
+type Lazy[T any] struct {
+	thunk      func() *lazyCell[T]
+	cell       *lazyCell[T]
+	evaluation sync.Once
+}
+
+type lazyCell[T any] struct {
+	value T
+	next  *Lazy[T]
+}
+
+func newLazy[T any](thunk func() *lazyCell[T]) *Lazy[T] {
+	return &Lazy[T]{
+		thunk: thunk,
+	}
+}
+
+// DeferLazy returns a stream which is produced by calling f the first time it is evaluated.
+//
+// Complexity: O(1) worst-case
+func DeferLazy[T any](f func() *Lazy[T]) *Lazy[T] {
+	return newLazy(func() *lazyCell[T] {
+		return f().force()
+	})
+}
+
+// GenerateLazy returns a stream whose items are produced on demand by f, which is given the index of
+// the item to produce. The stream ends the first time f returns false.
+//
+// Complexity: O(1) worst-case
+func GenerateLazy[T any](f func(i int) (T, bool)) *Lazy[T] {
+	return generateLazy(0, f)
+}
+
+func generateLazy[T any](i int, f func(i int) (T, bool)) *Lazy[T] {
+	return newLazy(func() *lazyCell[T] {
+		value, ok := f(i)
+		if !ok {
+			return nil
+		}
+		return &lazyCell[T]{
+			value: value,
+			next:  generateLazy(i+1, f),
+		}
+	})
+}
+
+// LazyFromSlice returns a stream containing the given items.
+//
+// Complexity: O(1) worst-case
+func LazyFromSlice[T any](items []T) *Lazy[T] {
+	items = append([]T(nil), items...)
+	return GenerateLazy(func(i int) (T, bool) {
+		if i < len(items) {
+			return items[i], true
+		}
+		var zero T
+		return zero, false
+	})
+}
+
+// MapLazy returns a stream containing the result of applying f to each item of the given stream.
+//
+// Complexity: O(1) worst-case
+func MapLazy[T, U any](l *Lazy[T], f func(T) U) *Lazy[U] {
+	return newLazy(func() *lazyCell[U] {
+		c := l.force()
+		if c == nil {
+			return nil
+		}
+		return &lazyCell[U]{
+			value: f(c.value),
+			next:  MapLazy(c.next, f),
+		}
+	})
+}
+
+func (l *Lazy[T]) force() *lazyCell[T] {
+	if l == nil {
+		return nil
+	}
+	l.evaluation.Do(func() {
+		if l.thunk != nil {
+			l.cell = l.thunk()
+			l.thunk = nil
+		}
+	})
+	return l.cell
+}
+
+// Empty returns true if the stream is empty. This forces evaluation of the first item.
+//
+// Complexity: O(1) worst-case, not including the cost of evaluation
+func (l *Lazy[T]) Empty() bool {
+	return l.force() == nil
+}
+
+// Front returns the first item of the stream. This forces evaluation of the first item. It panics
+// if the stream is empty.
+//
+// Complexity: O(1) worst-case, not including the cost of evaluation
+func (l *Lazy[T]) Front() T {
+	c := l.force()
+	if c == nil {
+		panic("immutable: Lazy stream is empty")
+	}
+	return c.value
+}
+
+// TryFront returns the first item of the stream, or false if the stream is empty. This forces
+// evaluation of the first item.
+//
+// Complexity: O(1) worst-case, not including the cost of evaluation
+func (l *Lazy[T]) TryFront() (T, bool) {
+	if c := l.force(); c != nil {
+		return c.value, true
+	}
+	var zero T
+	return zero, false
+}
+
+// PopFront returns the stream without its first item. If the stream is empty, it returns nil.
+//
+// Complexity: O(1) worst-case, not including the cost of evaluation
+func (l *Lazy[T]) PopFront() *Lazy[T] {
+	if c := l.force(); c != nil {
+		return c.next
+	}
+	return nil
+}
+
+// PushFront returns a stream with the given item in front of the items of this stream.
+//
+// Complexity: O(1) worst-case
+func (l *Lazy[T]) PushFront(value T) *Lazy[T] {
+	return &Lazy[T]{
+		cell: &lazyCell[T]{
+			value: value,
+			next:  l,
+		},
+	}
+}
+
+// Take returns a stream containing at most the first n items of this stream.
+//
+// Complexity: O(1) worst-case
+func (l *Lazy[T]) Take(n int) *Lazy[T] {
+	if n <= 0 {
+		return nil
+	}
+	return newLazy(func() *lazyCell[T] {
+		c := l.force()
+		if c == nil {
+			return nil
+		}
+		return &lazyCell[T]{
+			value: c.value,
+			next:  c.next.Take(n - 1),
+		}
+	})
+}
+
+// Drop returns a stream without the first n items of this stream. The items are skipped when the
+// returned stream is first evaluated.
+//
+// Complexity: O(1) worst-case
+func (l *Lazy[T]) Drop(n int) *Lazy[T] {
+	if n <= 0 {
+		return l
+	}
+	return newLazy(func() *lazyCell[T] {
+		s := l
+		for i := 0; i < n && !s.Empty(); i++ {
+			s = s.PopFront()
+		}
+		return s.force()
+	})
+}
+
+// Filter returns a stream containing only the items of this stream for which f returns true.
+//
+// Complexity: O(1) worst-case
+func (l *Lazy[T]) Filter(f func(T) bool) *Lazy[T] {
+	return newLazy(func() *lazyCell[T] {
+		for s := l; !s.Empty(); s = s.PopFront() {
+			if value := s.Front(); f(value) {
+				return &lazyCell[T]{
+					value: value,
+					next:  s.PopFront().Filter(f),
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// ToSlice evaluates the entire stream and returns its items as a slice. It never returns if the
+// stream is infinite.
+//
+// Complexity: O(n) worst-case, not including the cost of evaluation
+func (l *Lazy[T]) ToSlice() []T {
+	var ret []T
+	for ; !l.Empty(); l = l.PopFront() {
+		ret = append(ret, l.Front())
+	}
+	return ret
+}
@@ -4,18 +4,26 @@ import (
 	"sync"
 )
 
+// lazyList is the lazily evaluated front of a Queue. The only deferred computation a queue needs is
+// the next step of a rotation, so rather than holding a closure, which would need its own
+// allocation, each node holds the arguments of that step directly.
+//
+// Nodes are shared by every version of a queue which reaches them, so the step is guarded by a
+// sync.Once: it's performed at most once no matter how many versions or goroutines pop the node,
+// and its arguments are then released.
 type lazyList[T any] struct {
 	value      T
-	lazyNext   func() *lazyList[T]
+	rotation   lazyListRotation[T]
 	next       *lazyList[T]
 	evaluation sync.Once
 }
 
-func newLazyList[T any](front T, next func() *lazyList[T]) *lazyList[T] {
-	return &lazyList[T]{
-		value:    front,
-		lazyNext: next,
-	}
+// lazyListRotation holds the arguments to queueRotate for the rest of a list. If r is nil, there is
+// no pending rotation.
+type lazyListRotation[T any] struct {
+	f *lazyList[T]
+	r *Stack[T]
+	s *lazyList[T]
 }
 
 func (l *lazyList[T]) Front() T {
@@ -24,9 +32,9 @@ func (l *lazyList[T]) Front() T {
 
 func (l *lazyList[T]) PopFront() *lazyList[T] {
 	l.evaluation.Do(func() {
-		if l.lazyNext != nil {
-			l.next = l.lazyNext()
-			l.lazyNext = nil
+		if rot := l.rotation; rot.r != nil {
+			l.next = queueRotate(rot.f.PopFront(), rot.r.Pop(), rot.s.PushFront(rot.r.Peek()))
+			l.rotation = lazyListRotation[T]{}
 		}
 	})
 	return l.next
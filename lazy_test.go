@@ -0,0 +1,82 @@
+package immutable
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazy(t *testing.T) {
+	var l *Lazy[int]
+	assert.True(t, l.Empty())
+	assert.Nil(t, l.PopFront())
+	assert.Empty(t, l.ToSlice())
+	assert.True(t, (&Lazy[int]{}).Empty())
+	assert.PanicsWithValue(t, "immutable: Lazy stream is empty", func() { l.Front() })
+	_, ok := l.TryFront()
+	assert.False(t, ok)
+
+	l2 := l.PushFront(2).PushFront(1)
+	assert.Equal(t, 1, l2.Front())
+	v, ok := l2.TryFront()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, []int{1, 2}, l2.ToSlice())
+	assert.Equal(t, []int{2}, l2.PopFront().ToSlice())
+
+	assert.Equal(t, []int{1, 2, 3}, LazyFromSlice([]int{1, 2, 3}).ToSlice())
+	assert.True(t, LazyFromSlice[int](nil).Empty())
+}
+
+func TestLazy_Memoization(t *testing.T) {
+	calls := 0
+	naturals := GenerateLazy(func(i int) (int, bool) {
+		calls++
+		return i, true
+	})
+	assert.Equal(t, 0, calls)
+
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, naturals.Take(5).ToSlice())
+	assert.Equal(t, 5, calls)
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, naturals.Take(5).ToSlice())
+	assert.Equal(t, 5, calls)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, 99, naturals.Drop(99).Front())
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 100, calls)
+}
+
+func TestLazy_Operations(t *testing.T) {
+	naturals := GenerateLazy(func(i int) (int, bool) {
+		return i, true
+	})
+	evens := naturals.Filter(func(n int) bool {
+		return n%2 == 0
+	})
+	squares := MapLazy(evens, func(n int) int {
+		return n * n
+	})
+	assert.Equal(t, []int{16, 36, 64}, squares.Drop(2).Take(3).ToSlice())
+	assert.Equal(t, naturals, naturals.Drop(0))
+	assert.Nil(t, naturals.Take(0))
+	assert.True(t, LazyFromSlice([]int{1, 2}).Drop(3).Empty())
+	assert.True(t, LazyFromSlice([]int{1, 3}).Filter(func(n int) bool { return n%2 == 0 }).Empty())
+
+	deferred := 0
+	d := DeferLazy(func() *Lazy[int] {
+		deferred++
+		return LazyFromSlice([]int{1, 2})
+	})
+	assert.Equal(t, 0, deferred)
+	assert.Equal(t, []int{1, 2}, d.ToSlice())
+	assert.Equal(t, []int{1, 2}, d.ToSlice())
+	assert.Equal(t, 1, deferred)
+}
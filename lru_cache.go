@@ -0,0 +1,168 @@
+package immutable
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+// LRUCache implements a cache which evicts the least recently used entries once its capacity is
+// exceeded.
+//
+// Nil and the zero value for LRUCache are both empty caches with no capacity limit.
+type LRUCache[K constraints.Ordered, V any] struct {
+	capacity int
+	clock    uint64
+	entries  *OrderedMap[K, lruCacheEntry[V]]
+	recency  *OrderedMap[uint64, K]
+}
+
+type lruCacheEntry[V any] struct {
+	value    V
+	lastUsed uint64
+}
+
+// NewLRUCache returns an empty cache which can hold up to the given number of entries. If capacity
+// is not positive, the cache has no capacity limit.
+//
+// Complexity: O(1) worst-case
+func NewLRUCache[K constraints.Ordered, V any](capacity int) *LRUCache[K, V] {
+	return &LRUCache[K, V]{
+		capacity: capacity,
+	}
+}
+
+// Len returns the number of entries in the cache.
+//
+// Complexity: O(1) worst-case
+func (c *LRUCache[K, V]) Len() int {
+	if c == nil {
+		return 0
+	}
+	return c.entries.Len()
+}
+
+// Capacity returns the maximum number of entries in the cache, or zero if there is no limit.
+//
+// Complexity: O(1) worst-case
+func (c *LRUCache[K, V]) Capacity() int {
+	if c == nil || c.capacity < 0 {
+		return 0
+	}
+	return c.capacity
+}
+
+// Get returns the value associated with the given key if present, along with a cache in which the
+// entry is the most recently used. If the key is not present, the cache is returned unchanged.
+//
+// Complexity: O(log n) worst-case
+func (c *LRUCache[K, V]) Get(key K) (V, bool, *LRUCache[K, V]) {
+	entry, ok := c.peek(key)
+	if !ok {
+		return entry.value, false, c
+	}
+	return entry.value, true, c.use(key, entry.value, entry.lastUsed, true)
+}
+
+// Peek returns the value associated with the given key if present, without affecting its recency.
+//
+// Complexity: O(log n) worst-case
+func (c *LRUCache[K, V]) Peek(key K) (V, bool) {
+	entry, ok := c.peek(key)
+	return entry.value, ok
+}
+
+// Put associates a value with the given key and makes it the most recently used entry. If the cache
+// exceeds its capacity as a result, the least recently used entry is evicted.
+//
+// Complexity: O(log n) worst-case
+func (c *LRUCache[K, V]) Put(key K, value V) *LRUCache[K, V] {
+	entry, ok := c.peek(key)
+	ret := c.use(key, value, entry.lastUsed, ok)
+	if ret.capacity > 0 && ret.entries.Len() > ret.capacity {
+		oldest := ret.recency.Min()
+		ret.entries = ret.entries.Delete(oldest.Value())
+		ret.recency = ret.recency.Delete(oldest.Key())
+	}
+	return ret
+}
+
+// Delete removes the entry with the given key from the cache.
+//
+// Complexity: O(log n) worst-case
+func (c *LRUCache[K, V]) Delete(key K) *LRUCache[K, V] {
+	entry, ok := c.peek(key)
+	if !ok {
+		return c
+	}
+	return &LRUCache[K, V]{
+		capacity: c.capacity,
+		clock:    c.clock,
+		entries:  c.entries.Delete(key),
+		recency:  c.recency.Delete(entry.lastUsed),
+	}
+}
+
+// Oldest returns the key and value of the least recently used entry.
+//
+// Complexity: O(log n) worst-case
+func (c *LRUCache[K, V]) Oldest() (key K, value V, ok bool) {
+	if c.Len() == 0 {
+		return key, value, false
+	}
+	key = c.recency.Min().Value()
+	entry, _ := c.entries.Get(key)
+	return key, entry.value, true
+}
+
+// CheckInvariants verifies the structural integrity of the cache, returning an error describing the
+// first problem found. It checks that the entries and the recency order agree with each other and
+// with the clock, and that the cache is within its capacity.
+//
+// Complexity: O(n log n) worst-case
+func (c *LRUCache[K, V]) CheckInvariants() error {
+	if c == nil {
+		return nil
+	} else if err := c.entries.CheckInvariants(); err != nil {
+		return err
+	} else if err := c.recency.CheckInvariants(); err != nil {
+		return err
+	} else if c.entries.Len() != c.recency.Len() {
+		return fmt.Errorf("cache has %v entries but %v recency records", c.entries.Len(), c.recency.Len())
+	} else if c.capacity > 0 && c.entries.Len() > c.capacity {
+		return fmt.Errorf("cache has %v entries but a capacity of %v", c.entries.Len(), c.capacity)
+	}
+	for e := c.recency.Min(); e != nil; e = e.Next() {
+		if entry, ok := c.entries.Get(e.Value()); !ok || entry.lastUsed != e.Key() {
+			return fmt.Errorf("recency record for key %v is inconsistent", e.Value())
+		} else if e.Key() > c.clock {
+			return fmt.Errorf("recency record for key %v is in the future", e.Value())
+		}
+	}
+	return nil
+}
+
+func (c *LRUCache[K, V]) peek(key K) (lruCacheEntry[V], bool) {
+	if c == nil {
+		return lruCacheEntry[V]{}, false
+	}
+	return c.entries.Get(key)
+}
+
+// use returns a cache in which the given entry is set and most recently used.
+func (c *LRUCache[K, V]) use(key K, value V, lastUsed uint64, exists bool) *LRUCache[K, V] {
+	ret := &LRUCache[K, V]{}
+	if c != nil {
+		*ret = *c
+	}
+	if exists {
+		ret.recency = ret.recency.Delete(lastUsed)
+	}
+	ret.clock++
+	ret.entries = ret.entries.Set(key, lruCacheEntry[V]{
+		value:    value,
+		lastUsed: ret.clock,
+	})
+	ret.recency = ret.recency.Set(ret.clock, key)
+	return ret
+}
@@ -0,0 +1,110 @@
+package immutable
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUCache(t *testing.T) {
+	var unlimited *LRUCache[string, int]
+	assert.Equal(t, 0, unlimited.Len())
+	assert.Equal(t, 0, unlimited.Capacity())
+	_, _, ok := unlimited.Oldest()
+	assert.False(t, ok)
+	for i := 0; i < 100; i++ {
+		unlimited = unlimited.Put(string(rune('a'+i)), i)
+	}
+	assert.Equal(t, 100, unlimited.Len())
+
+	c := NewLRUCache[string, int](2)
+	assert.Equal(t, 2, c.Capacity())
+	c = c.Put("a", 1).Put("b", 2)
+	assert.Equal(t, 2, c.Len())
+
+	v, ok, c2 := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	_, ok, c3 := c2.Get("z")
+	assert.False(t, ok)
+	assert.Same(t, c2, c3)
+
+	// c2 has used "a" more recently than "b", so "b" is evicted.
+	c2 = c2.Put("c", 3)
+	assert.Equal(t, 2, c2.Len())
+	_, ok = c2.Peek("b")
+	assert.False(t, ok)
+	_, ok = c2.Peek("a")
+	assert.True(t, ok)
+
+	// c has used "b" more recently than "a", so "a" is evicted.
+	c = c.Put("c", 3)
+	_, ok = c.Peek("a")
+	assert.False(t, ok)
+	k, v, ok := c.Oldest()
+	assert.True(t, ok)
+	assert.Equal(t, "b", k)
+	assert.Equal(t, 2, v)
+
+	c = c.Delete("b")
+	assert.Equal(t, 1, c.Len())
+	assert.Same(t, c, c.Delete("b"))
+}
+
+func TestLRUCache_Fuzz(t *testing.T) {
+	const capacity = 10
+	c := NewLRUCache[int, int](capacity)
+	var ref []int // keys, least recently used first
+	values := map[int]int{}
+	touch := func(k int) {
+		for i, rk := range ref {
+			if rk == k {
+				ref = append(ref[:i], ref[i+1:]...)
+				break
+			}
+		}
+		ref = append(ref, k)
+	}
+	for i := 0; i < 10000; i++ {
+		k := rand.Intn(20)
+		switch rand.Intn(3) {
+		case 0:
+			v, ok, next := c.Get(k)
+			_, refOk := values[k]
+			require.Equal(t, refOk, ok)
+			if ok {
+				require.Equal(t, values[k], v)
+				touch(k)
+			}
+			c = next
+		case 1:
+			c = c.Put(k, i)
+			values[k] = i
+			touch(k)
+			if len(ref) > capacity {
+				delete(values, ref[0])
+				ref = ref[1:]
+			}
+		default:
+			c = c.Delete(k)
+			if _, ok := values[k]; ok {
+				delete(values, k)
+				for i, rk := range ref {
+					if rk == k {
+						ref = append(ref[:i], ref[i+1:]...)
+						break
+					}
+				}
+			}
+		}
+		require.NoError(t, c.CheckInvariants())
+		require.Equal(t, len(ref), c.Len())
+		if len(ref) > 0 {
+			k, _, _ := c.Oldest()
+			require.Equal(t, ref[0], k)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package immutable
+
+import "unsafe"
+
+// MemoryCounter estimates the memory retained by a set of persistent containers. Since containers
+// share structure between versions, each node is only counted the first time it's seen, so adding
+// many versions of a container to the same counter estimates the memory they retain together.
+//
+// Estimates include the nodes of containers, but not memory referenced by keys, values, or items,
+// such as string contents. That memory can be included with Add.
+//
+// The zero value is an empty counter ready to use. Counters are not safe for concurrent use.
+type MemoryCounter struct {
+	seen  map[unsafe.Pointer]struct{}
+	bytes uintptr
+}
+
+// Bytes returns the estimated number of bytes counted so far.
+//
+// Complexity: O(1) worst-case
+func (c *MemoryCounter) Bytes() uintptr {
+	return c.bytes
+}
+
+// Add adds the given number of bytes to the count, such as for memory referenced by values.
+//
+// Complexity: O(1) worst-case
+func (c *MemoryCounter) Add(bytes uintptr) {
+	c.bytes += bytes
+}
+
+// visit counts a node of the given size, returning false if it was already counted.
+func (c *MemoryCounter) visit(p unsafe.Pointer, size uintptr) bool {
+	if _, ok := c.seen[p]; ok {
+		return false
+	} else if c.seen == nil {
+		c.seen = map[unsafe.Pointer]struct{}{}
+	}
+	c.seen[p] = struct{}{}
+	c.bytes += size
+	return true
+}
@@ -0,0 +1,49 @@
+package immutable
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCounter(t *testing.T) {
+	var c MemoryCounter
+	assert.Equal(t, uintptr(0), c.Bytes())
+	c.Add(10)
+	assert.Equal(t, uintptr(10), c.Bytes())
+
+	nodeSize := unsafe.Sizeof(OrderedMap[int, int]{})
+	var m *OrderedMap[int, int]
+	for i := 0; i < 100; i++ {
+		m = m.Set(i, i)
+	}
+	c = MemoryCounter{}
+	m.CountMemory(&c)
+	assert.Equal(t, 100*nodeSize, c.Bytes())
+
+	// Counting the same map again adds nothing, and counting a derived version only adds the nodes
+	// it doesn't share.
+	m.CountMemory(&c)
+	assert.Equal(t, 100*nodeSize, c.Bytes())
+	m2 := m.Set(0, -1)
+	m2.CountMemory(&c)
+	stats := m2.Stats(m)
+	assert.Equal(t, uintptr(100)*nodeSize+stats.EstimatedUniqueBytes, c.Bytes())
+
+	var empty *OrderedMap[int, int]
+	empty.CountMemory(&c)
+	assert.Equal(t, uintptr(100)*nodeSize+stats.EstimatedUniqueBytes, c.Bytes())
+}
+
+func TestStack_CountMemory(t *testing.T) {
+	nodeSize := unsafe.Sizeof(Stack[int]{})
+	base := StackOf(1, 2, 3)
+	var c MemoryCounter
+	base.Push(4).CountMemory(&c)
+	assert.Equal(t, 4*nodeSize, c.Bytes())
+	base.Push(5).CountMemory(&c)
+	assert.Equal(t, 5*nodeSize, c.Bytes())
+	(*Stack[int])(nil).CountMemory(&c)
+	assert.Equal(t, 5*nodeSize, c.Bytes())
+}
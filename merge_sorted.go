@@ -0,0 +1,70 @@
+package immutable
+
+import (
+	"container/heap"
+
+	"golang.org/x/exp/constraints"
+)
+
+// mergeSorted merges sequences that are each in ascending order of keys, passing each element to
+// yield until it returns false. Each sequence is given as a function which returns its next
+// element, or false once it's exhausted, like the functions returned by iter.Pull2. See
+// MergeSorted.
+func mergeSorted[K constraints.Ordered, V any](nexts []func() (K, V, bool), yield func(key K, value V) bool) {
+	h := make(mergeSortedHeap[K, V], 0, len(nexts))
+	for i, next := range nexts {
+		if key, value, ok := next(); ok {
+			h = append(h, mergeSortedHead[K, V]{
+				key:   key,
+				value: value,
+				index: i,
+				next:  next,
+			})
+		}
+	}
+	heap.Init(&h)
+	for len(h) > 0 {
+		head := &h[0]
+		if !yield(head.key, head.value) {
+			return
+		}
+		var ok bool
+		if head.key, head.value, ok = head.next(); ok {
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+}
+
+type mergeSortedHead[K constraints.Ordered, V any] struct {
+	key   K
+	value V
+	index int
+	next  func() (K, V, bool)
+}
+
+type mergeSortedHeap[K constraints.Ordered, V any] []mergeSortedHead[K, V]
+
+func (h mergeSortedHeap[K, V]) Len() int {
+	return len(h)
+}
+
+func (h mergeSortedHeap[K, V]) Less(i, j int) bool {
+	return h[i].key < h[j].key || (!(h[j].key < h[i].key) && h[i].index < h[j].index)
+}
+
+func (h mergeSortedHeap[K, V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *mergeSortedHeap[K, V]) Push(x any) {
+	*h = append(*h, x.(mergeSortedHead[K, V]))
+}
+
+func (h *mergeSortedHeap[K, V]) Pop() any {
+	old := *h
+	ret := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return ret
+}
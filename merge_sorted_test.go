@@ -0,0 +1,69 @@
+//go:build go1.23
+
+package immutable
+
+import (
+	"iter"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeSorted(t *testing.T) {
+	a := (*OrderedMap[int, string])(nil).Set(1, "a").Set(4, "a").Set(5, "a")
+	b := (*OrderedMap[int, string])(nil).Set(2, "b").Set(4, "b")
+	var c *OrderedMap[int, string]
+
+	type kv struct {
+		Key   int
+		Value string
+	}
+	var got []kv
+	for k, v := range MergeSorted(a.All(), b.All(), c.All()) {
+		got = append(got, kv{k, v})
+	}
+	assert.Equal(t, []kv{{1, "a"}, {2, "b"}, {4, "a"}, {4, "b"}, {5, "a"}}, got)
+
+	got = nil
+	for k, v := range MergeSorted(b.All(), a.All()) {
+		got = append(got, kv{k, v})
+		if len(got) == 3 {
+			break
+		}
+	}
+	assert.Equal(t, []kv{{1, "a"}, {2, "b"}, {4, "b"}}, got)
+
+	for range MergeSorted[int, string]() {
+		t.Fatal("empty merge yielded an element")
+	}
+}
+
+func TestMergeSorted_Random(t *testing.T) {
+	var seqs []iter.Seq2[int, int]
+	var expected []int
+	for i := 0; i < 10; i++ {
+		var m *OrderedMap[int, int]
+		for j := rand.Intn(100); j > 0; j-- {
+			m = m.Set(rand.Intn(1000), i)
+		}
+		m.ForEach(func(key, value int) bool {
+			expected = append(expected, key)
+			return true
+		})
+		seqs = append(seqs, m.All())
+	}
+	sort.Ints(expected)
+
+	var keys []int
+	prevKey, prevValue := -1, -1
+	for k, v := range MergeSorted(seqs...) {
+		keys = append(keys, k)
+		if k == prevKey {
+			assert.Less(t, prevValue, v)
+		}
+		prevKey, prevValue = k, v
+	}
+	assert.Equal(t, expected, keys)
+}
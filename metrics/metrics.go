@@ -0,0 +1,101 @@
+// Package metrics exposes the state of containers from package immutable, along with the operation
+// counters kept by Ref and Adapter, as expvar variables, so services built on them can be monitored
+// without wrapping every operation.
+//
+// The variables are computed when they're read, such as when /debug/vars is requested, so they
+// add no cost to the operations being monitored. Their values are JSON, which most metrics systems,
+// including Prometheus via expvar exporters, can consume.
+package metrics
+
+import (
+	"expvar"
+
+	"golang.org/x/exp/constraints"
+
+	"github.com/ccbrown/go-immutable"
+)
+
+// Container is implemented by the containers of package immutable which know their length, such as
+// OrderedMap, SortedList, Vector, Adapter, and FairQueue.
+type Container interface {
+	Len() int
+}
+
+// Len returns a variable whose value is the length of the container returned by f. Since
+// containers are immutable, f would typically load the current version from a Ref, an Adapter, or
+// the field of a struct.
+func Len[C Container](f func() C) expvar.Func {
+	return func() any {
+		return f().Len()
+	}
+}
+
+// RefStats is the value of a variable returned by Ref.
+type RefStats struct {
+	// Version is the number of times the Ref's value has been replaced.
+	Version uint64 `json:"version"`
+
+	// Commits is the number of transactions which were committed.
+	Commits uint64 `json:"commits"`
+
+	// Conflicts is the number of transactions which failed to commit due to contention.
+	Conflicts uint64 `json:"conflicts"`
+
+	// Lens contains the lengths reported by the functions given to Ref, by name.
+	Lens map[string]int `json:"lens,omitempty"`
+}
+
+// Ref returns a variable whose value is the RefStats of the given Ref. The lens functions are
+// given the Ref's current value, and typically report the lengths of the containers within it.
+func Ref[S any](r *immutable.Ref[S], lens map[string]func(value S) int) expvar.Func {
+	return func() any {
+		ret := RefStats{
+			Version:   r.Version(),
+			Commits:   r.Commits(),
+			Conflicts: r.Conflicts(),
+		}
+		if len(lens) > 0 {
+			value := r.Load()
+			ret.Lens = make(map[string]int, len(lens))
+			for name, f := range lens {
+				ret.Lens[name] = f(value)
+			}
+		}
+		return ret
+	}
+}
+
+// AdapterStats is the value of a variable returned by Adapter.
+type AdapterStats struct {
+	// Len is the number of elements in the Adapter's map.
+	Len int `json:"len"`
+
+	// Sets is the number of calls to the Adapter's Set method.
+	Sets uint64 `json:"sets"`
+
+	// Deletes is the number of calls to the Adapter's Delete method.
+	Deletes uint64 `json:"deletes"`
+
+	// Retries is the number of times a change had to be applied again due to contention.
+	Retries uint64 `json:"retries"`
+}
+
+// Adapter returns a variable whose value is the AdapterStats of the given Adapter.
+func Adapter[K constraints.Ordered, V any](a *immutable.Adapter[K, V]) expvar.Func {
+	return func() any {
+		return AdapterStats{
+			Len:     a.Len(),
+			Sets:    a.Sets(),
+			Deletes: a.Deletes(),
+			Retries: a.Retries(),
+		}
+	}
+}
+
+// OrderedMap returns a variable whose value is the OrderedMapStats of the map returned by f. This
+// is more expensive than Len, as it traverses the entire map, so it's best suited to debugging.
+func OrderedMap[K constraints.Ordered, V any](f func() *immutable.OrderedMap[K, V]) expvar.Func {
+	return func() any {
+		return f().Stats(nil)
+	}
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/go-immutable"
+)
+
+type state struct {
+	users *immutable.OrderedMap[string, int]
+	jobs  *immutable.Queue[string]
+}
+
+func TestLen(t *testing.T) {
+	var a immutable.Adapter[string, int]
+	v := Len(func() *immutable.Adapter[string, int] {
+		return &a
+	})
+	assert.Equal(t, "0", v.String())
+	a.Set("a", 1)
+	a.Set("b", 2)
+	assert.Equal(t, "2", v.String())
+}
+
+func TestRef(t *testing.T) {
+	var r immutable.Ref[state]
+	v := Ref(&r, map[string]func(state) int{
+		"users": func(s state) int {
+			return s.users.Len()
+		},
+	})
+
+	var stats RefStats
+	require.NoError(t, json.Unmarshal([]byte(v.String()), &stats))
+	assert.Equal(t, RefStats{Lens: map[string]int{"users": 0}}, stats)
+
+	txn := r.Begin()
+	r.Update(func(s state) state {
+		s.users = s.users.Set("a", 1)
+		s.jobs = s.jobs.PushBack("x")
+		return s
+	})
+	txn.State.users = txn.State.users.Set("b", 2)
+	assert.False(t, txn.Commit())
+
+	require.NoError(t, json.Unmarshal([]byte(v.String()), &stats))
+	assert.Equal(t, RefStats{
+		Version:   1,
+		Commits:   1,
+		Conflicts: 1,
+		Lens:      map[string]int{"users": 1},
+	}, stats)
+
+	assert.Equal(t, `{"version":1,"commits":1,"conflicts":1}`, Ref(&r, nil).String())
+}
+
+func TestAdapter(t *testing.T) {
+	var a immutable.Adapter[string, int]
+	v := Adapter(&a)
+	assert.Equal(t, `{"len":0,"sets":0,"deletes":0,"retries":0}`, v.String())
+
+	a.Set("a", 1)
+	a.Set("b", 2)
+	a.Set("a", 3)
+	a.Delete("b")
+	a.Delete("c")
+
+	var stats AdapterStats
+	require.NoError(t, json.Unmarshal([]byte(v.String()), &stats))
+	assert.Equal(t, AdapterStats{
+		Len:     1,
+		Sets:    3,
+		Deletes: 2,
+	}, stats)
+}
+
+func TestOrderedMap(t *testing.T) {
+	m := immutable.OrderedMapOf(immutable.Pair[int, int]{Key: 1, Value: 1}, immutable.Pair[int, int]{Key: 2, Value: 2})
+	v := OrderedMap(func() *immutable.OrderedMap[int, int] {
+		return m
+	})
+	var stats immutable.OrderedMapStats
+	require.NoError(t, json.Unmarshal([]byte(v.String()), &stats))
+	assert.Equal(t, 2, stats.Nodes)
+}
@@ -0,0 +1,110 @@
+package immutable
+
+import "golang.org/x/exp/constraints"
+
+// MinMaxHeap implements a double-ended priority queue, from which both the least and the greatest
+// items can be retrieved and removed efficiently. This is useful for bounded collections which
+// evict from one end while consuming from the other, such as a top-k buffer. Like OrderedMap, it
+// doesn't support NaN values.
+//
+// It is backed by the same red-black tree as SortedList, so it may contain duplicates.
+//
+// Nil and the zero value for MinMaxHeap are both empty heaps.
+type MinMaxHeap[T constraints.Ordered] struct {
+	l *SortedList[T]
+}
+
+func newMinMaxHeap[T constraints.Ordered](l *SortedList[T]) *MinMaxHeap[T] {
+	if l.Empty() {
+		return nil
+	}
+	return &MinMaxHeap[T]{
+		l: l,
+	}
+}
+
+func (h *MinMaxHeap[T]) list() *SortedList[T] {
+	if h == nil {
+		return nil
+	}
+	return h.l
+}
+
+// Empty returns true if the heap is empty.
+//
+// Complexity: O(1) worst-case
+func (h *MinMaxHeap[T]) Empty() bool {
+	return h.list().Empty()
+}
+
+// Len returns the number of items in the heap.
+//
+// Complexity: O(1) worst-case
+func (h *MinMaxHeap[T]) Len() int {
+	return h.list().Len()
+}
+
+// Push adds an item to the heap. It panics if the item is NaN.
+//
+// Complexity: O(log n) worst-case
+func (h *MinMaxHeap[T]) Push(value T) *MinMaxHeap[T] {
+	return newMinMaxHeap(h.list().Insert(value))
+}
+
+// Min returns the least item in the heap. It panics if the heap is empty.
+//
+// Complexity: O(log n) worst-case
+func (h *MinMaxHeap[T]) Min() T {
+	if h.Empty() {
+		panic("immutable: MinMaxHeap is empty")
+	}
+	return h.l.Min()
+}
+
+// Max returns the greatest item in the heap. It panics if the heap is empty.
+//
+// Complexity: O(log n) worst-case
+func (h *MinMaxHeap[T]) Max() T {
+	if h.Empty() {
+		panic("immutable: MinMaxHeap is empty")
+	}
+	return h.l.Max()
+}
+
+// PopMin removes the least item from the heap. It panics if the heap is empty.
+//
+// Complexity: O(log n) worst-case
+func (h *MinMaxHeap[T]) PopMin() *MinMaxHeap[T] {
+	if h.Empty() {
+		panic("immutable: MinMaxHeap is empty")
+	}
+	_, rest := h.l.m.splitAt(1)
+	return newMinMaxHeap(newSortedList(rest.blacken()))
+}
+
+// PopMax removes the greatest item from the heap. It panics if the heap is empty.
+//
+// Complexity: O(log n) worst-case
+func (h *MinMaxHeap[T]) PopMax() *MinMaxHeap[T] {
+	if h.Empty() {
+		panic("immutable: MinMaxHeap is empty")
+	}
+	rest, _ := h.l.m.splitAt(h.Len() - 1)
+	return newMinMaxHeap(newSortedList(rest.blacken()))
+}
+
+// ToSlice returns the items in the heap as a slice, in ascending order.
+//
+// Complexity: O(n) worst-case
+func (h *MinMaxHeap[T]) ToSlice() []T {
+	return h.list().ToSlice()
+}
+
+// CheckInvariants verifies the structural integrity of the heap, returning an error describing the
+// first problem found. It checks the red-black invariants of the underlying tree and that its items
+// are in non-descending order, which is what keeps the least and greatest items at its ends.
+//
+// Complexity: O(n) worst-case
+func (h *MinMaxHeap[T]) CheckInvariants() error {
+	return h.list().CheckInvariants()
+}
@@ -0,0 +1,72 @@
+package immutable
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinMaxHeap(t *testing.T) {
+	var h *MinMaxHeap[int]
+	assert.True(t, h.Empty())
+	assert.Equal(t, 0, h.Len())
+	assert.Nil(t, h.ToSlice())
+	assert.Panics(t, func() {
+		h.Min()
+	})
+	assert.Panics(t, func() {
+		h.Max()
+	})
+	assert.Panics(t, func() {
+		h.PopMin()
+	})
+	assert.Panics(t, func() {
+		h.PopMax()
+	})
+
+	var ref []int
+	for i := 0; i < 5000; i++ {
+		switch {
+		case len(ref) > 0 && rand.Intn(4) == 0:
+			h = h.PopMin()
+			ref = ref[1:]
+		case len(ref) > 0 && rand.Intn(3) == 0:
+			h = h.PopMax()
+			ref = ref[:len(ref)-1]
+		default:
+			v := rand.Intn(100)
+			h = h.Push(v)
+			ref = append(ref, v)
+			sort.Ints(ref)
+		}
+		require.Equal(t, len(ref), h.Len())
+		require.NoError(t, h.CheckInvariants())
+		if len(ref) > 0 {
+			require.Equal(t, ref[0], h.Min())
+			require.Equal(t, ref[len(ref)-1], h.Max())
+		} else {
+			require.True(t, h.Empty())
+		}
+	}
+	if len(ref) > 0 {
+		assert.Equal(t, ref, h.ToSlice())
+	}
+}
+
+func TestMinMaxHeap_BoundedTopK(t *testing.T) {
+	var h *MinMaxHeap[float64]
+	for i := 0; i < 100; i++ {
+		h = h.Push(float64(i * 37 % 100))
+		if h.Len() > 5 {
+			h = h.PopMin()
+		}
+	}
+	assert.Equal(t, []float64{95, 96, 97, 98, 99}, h.ToSlice())
+	assert.Panics(t, func() {
+		h.Push(math.NaN())
+	})
+}
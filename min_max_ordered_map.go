@@ -0,0 +1,136 @@
+package immutable
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+// MinMaxOrderedMap implements an ordered map which retains its minimum and maximum elements so that
+// they can be accessed in constant time. This is useful for workloads that frequently poll the
+// boundaries of the map, such as timer wheels.
+//
+// Nil and the zero value for MinMaxOrderedMap are both empty maps.
+type MinMaxOrderedMap[K constraints.Ordered, V any] struct {
+	m   *OrderedMap[K, V]
+	min *OrderedMapElement[K, V]
+	max *OrderedMapElement[K, V]
+}
+
+// NewMinMaxOrderedMap returns a MinMaxOrderedMap with the same contents as the given map.
+//
+// Complexity: O(log n) worst-case
+func NewMinMaxOrderedMap[K constraints.Ordered, V any](m *OrderedMap[K, V]) *MinMaxOrderedMap[K, V] {
+	return &MinMaxOrderedMap[K, V]{
+		m:   m,
+		min: m.Min(),
+		max: m.Max(),
+	}
+}
+
+// OrderedMap returns an OrderedMap with the same contents as the map.
+//
+// Complexity: O(1) worst-case
+func (m *MinMaxOrderedMap[K, V]) OrderedMap() *OrderedMap[K, V] {
+	if m == nil {
+		return nil
+	}
+	return m.m
+}
+
+// Empty returns true if the map is empty.
+//
+// Complexity: O(1) worst-case
+func (m *MinMaxOrderedMap[K, V]) Empty() bool {
+	return m.OrderedMap().Empty()
+}
+
+// Len returns the number of elements in the map.
+//
+// Complexity: O(1) worst-case
+func (m *MinMaxOrderedMap[K, V]) Len() int {
+	return m.OrderedMap().Len()
+}
+
+// Get returns the value associated with the given key if set.
+//
+// Complexity: O(log n) worst-case
+func (m *MinMaxOrderedMap[K, V]) Get(key K) (V, bool) {
+	return m.OrderedMap().Get(key)
+}
+
+// Set associates a value with the given key.
+//
+// Complexity: O(log n) worst-case
+func (m *MinMaxOrderedMap[K, V]) Set(key K, value V) *MinMaxOrderedMap[K, V] {
+	return NewMinMaxOrderedMap(m.OrderedMap().Set(key, value))
+}
+
+// Delete removes a key from the map.
+//
+// Complexity: O(log n) worst-case
+func (m *MinMaxOrderedMap[K, V]) Delete(key K) *MinMaxOrderedMap[K, V] {
+	if _, ok := m.Get(key); !ok {
+		return m
+	}
+	return NewMinMaxOrderedMap(m.OrderedMap().Delete(key))
+}
+
+// Min returns the minimum element in the map.
+//
+// Complexity: O(1) worst-case
+func (m *MinMaxOrderedMap[K, V]) Min() *OrderedMapElement[K, V] {
+	if m == nil {
+		return nil
+	}
+	return m.min
+}
+
+// Max returns the maximum element in the map.
+//
+// Complexity: O(1) worst-case
+func (m *MinMaxOrderedMap[K, V]) Max() *OrderedMapElement[K, V] {
+	if m == nil {
+		return nil
+	}
+	return m.max
+}
+
+// MinAfter returns the minimum element in the map that is greater than the given key.
+//
+// Complexity: O(log n) worst-case
+func (m *MinMaxOrderedMap[K, V]) MinAfter(key K) *OrderedMapElement[K, V] {
+	return m.OrderedMap().MinAfter(key)
+}
+
+// MaxBefore returns the maximum element in the map that is less than the given key.
+//
+// Complexity: O(log n) worst-case
+func (m *MinMaxOrderedMap[K, V]) MaxBefore(key K) *OrderedMapElement[K, V] {
+	return m.OrderedMap().MaxBefore(key)
+}
+
+// CheckInvariants verifies the structural integrity of the map, returning an error describing the
+// first problem found. It checks the underlying map and that the retained minimum and maximum
+// elements are its actual minimum and maximum.
+//
+// Complexity: O(n) worst-case
+func (m *MinMaxOrderedMap[K, V]) CheckInvariants() error {
+	if m == nil {
+		return nil
+	} else if err := m.m.CheckInvariants(); err != nil {
+		return err
+	} else if !minMaxOrderedMapSameKey(m.min, m.m.Min()) {
+		return fmt.Errorf("retained minimum is not the map's minimum")
+	} else if !minMaxOrderedMapSameKey(m.max, m.m.Max()) {
+		return fmt.Errorf("retained maximum is not the map's maximum")
+	}
+	return nil
+}
+
+func minMaxOrderedMapSameKey[K constraints.Ordered, V any](a, b *OrderedMapElement[K, V]) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Key() == b.Key()
+}
@@ -0,0 +1,62 @@
+package immutable
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinMaxOrderedMap(t *testing.T) {
+	var m *MinMaxOrderedMap[int, int]
+	assert.True(t, m.Empty())
+	assert.Equal(t, 0, m.Len())
+	assert.Nil(t, m.Min())
+	assert.Nil(t, m.Max())
+	assert.Nil(t, m.OrderedMap())
+
+	ref := map[int]int{}
+	for i := 0; i < 10000; i++ {
+		k := rand.Intn(100)
+		if rand.Intn(3) == 0 {
+			delete(ref, k)
+			m = m.Delete(k)
+		} else {
+			ref[k] = i
+			m = m.Set(k, i)
+		}
+		require.NoError(t, m.CheckInvariants())
+		require.Equal(t, len(ref), m.Len())
+		if len(ref) == 0 {
+			require.Nil(t, m.Min())
+			require.Nil(t, m.Max())
+			continue
+		}
+		min, max := 100, -1
+		for k := range ref {
+			if k < min {
+				min = k
+			}
+			if k > max {
+				max = k
+			}
+		}
+		require.Equal(t, min, m.Min().Key())
+		require.Equal(t, ref[min], m.Min().Value())
+		require.Equal(t, max, m.Max().Key())
+		require.Equal(t, ref[max], m.Max().Value())
+		require.Equal(t, len(ref)-1, m.Min().CountGreater())
+	}
+
+	v, ok := m.Get(m.Min().Key())
+	assert.True(t, ok)
+	assert.Equal(t, m.Min().Value(), v)
+	assert.Equal(t, ref, m.OrderedMap().ToMap())
+	if e := m.MinAfter(m.Min().Key()); e != nil {
+		assert.Equal(t, m.Min().Next().Key(), e.Key())
+	}
+	if e := m.MaxBefore(m.Max().Key()); e != nil {
+		assert.Equal(t, m.Max().Prev().Key(), e.Key())
+	}
+}
@@ -0,0 +1,35 @@
+package immutable
+
+// OrderedContainer is the interface shared by the ordered maps in this package: OrderedMap, Treap,
+// and CompactOrderedMap. C is the type of the container itself, which is returned by Set and
+// Delete. The backends have different performance characteristics, so code which is generic over
+// this interface can be benchmarked with each of them and switched between them without changing
+// any call sites:
+//
+//	func countWords[C OrderedContainer[string, int, C]](empty C, words []string) C {
+//		counts := empty
+//		for _, w := range words {
+//			n, _ := counts.Get(w)
+//			counts = counts.Set(w, n+1)
+//		}
+//		return counts
+//	}
+type OrderedContainer[K, V, C any] interface {
+	// Empty returns true if the container is empty.
+	Empty() bool
+
+	// Len returns the number of elements in the container.
+	Len() int
+
+	// Get returns the value associated with the given key if set.
+	Get(key K) (V, bool)
+
+	// Set associates a value with the given key.
+	Set(key K, value V) C
+
+	// Delete removes a key from the container.
+	Delete(key K) C
+
+	// ForEach calls f for each element in ascending order of keys, stopping early if f returns false.
+	ForEach(f func(key K, value V) bool)
+}
@@ -0,0 +1,86 @@
+package immutable
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func orderedContainerCountWords[C OrderedContainer[string, int, C]](empty C, words []string) C {
+	counts := empty
+	for _, w := range words {
+		n, _ := counts.Get(w)
+		counts = counts.Set(w, n+1)
+	}
+	return counts
+}
+
+func orderedContainerToMap[K comparable, V any, C OrderedContainer[K, V, C]](c C) map[K]V {
+	ret := map[K]V{}
+	c.ForEach(func(key K, value V) bool {
+		ret[key] = value
+		return true
+	})
+	return ret
+}
+
+func TestOrderedContainer(t *testing.T) {
+	words := []string{"a", "b", "a", "c", "a", "b"}
+	expected := map[string]int{"a": 3, "b": 2, "c": 1}
+	assert.Equal(t, expected, orderedContainerToMap(orderedContainerCountWords((*OrderedMap[string, int])(nil), words)))
+	assert.Equal(t, expected, orderedContainerToMap(orderedContainerCountWords((*Treap[string, int])(nil), words)))
+	assert.Equal(t, expected, orderedContainerToMap(orderedContainerCountWords((*CompactOrderedMap[string, int])(nil), words)))
+}
+
+var orderedContainerValueResult string
+
+func benchmarkOrderedContainerGet[C OrderedContainer[int, string, C]](b *testing.B, empty C) {
+	for _, n := range []int{100, 10000, 1000000} {
+		m := empty
+		for i := 0; i < n; i++ {
+			m = m.Set(i, "foo")
+		}
+		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				v, _ := m.Get(i % n)
+				orderedContainerValueResult = v
+			}
+		})
+	}
+}
+
+func BenchmarkOrderedContainer_Get(b *testing.B) {
+	b.Run("OrderedMap", func(b *testing.B) {
+		benchmarkOrderedContainerGet(b, (*OrderedMap[int, string])(nil))
+	})
+	b.Run("Treap", func(b *testing.B) {
+		benchmarkOrderedContainerGet(b, (*Treap[int, string])(nil))
+	})
+}
+
+var orderedContainerResult any
+
+func benchmarkOrderedContainerSet[C OrderedContainer[int, string, C]](b *testing.B, empty C) {
+	for _, n := range []int{100, 10000, 1000000} {
+		m := empty
+		for i := 0; i < n; i++ {
+			m = m.Set(i*2, "foo")
+		}
+		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				orderedContainerResult = m.Set((i%n)*2+1, "bar")
+			}
+		})
+	}
+}
+
+func BenchmarkOrderedContainer_Set(b *testing.B) {
+	b.Run("OrderedMap", func(b *testing.B) {
+		benchmarkOrderedContainerSet(b, (*OrderedMap[int, string])(nil))
+	})
+	b.Run("Treap", func(b *testing.B) {
+		benchmarkOrderedContainerSet(b, (*Treap[int, string])(nil))
+	})
+}
@@ -1,6 +1,20 @@
 package immutable
 
-import "golang.org/x/exp/constraints"
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"unsafe"
+
+	"golang.org/x/exp/constraints"
+)
+
+const (
+	orderedMapUnchanged = iota
+	orderedMapUpdated
+	orderedMapInserted
+	orderedMapDeleted
+)
 
 const (
 	orderedMapNegativeBlack = -1
@@ -9,23 +23,162 @@ const (
 	orderedMapDoubleBlack   = 2
 )
 
+// orderedMapMeta returns the meta field of a node with the given length and color.
+func orderedMapMeta(len, color int) int {
+	return len<<2 | color&3
+}
+
+// size returns the number of elements in the subtree of the node, which must not be nil.
+func (m *OrderedMap[K, V]) size() int {
+	return m.meta >> 2
+}
+
+// color returns the color of the node, which must not be nil.
+func (m *OrderedMap[K, V]) color() int {
+	return (m.meta+1)&3 - 1
+}
+
+func (m *OrderedMap[K, V]) setColor(color int) {
+	m.meta = m.meta&^3 | color&3
+}
+
+func (m *OrderedMap[K, V]) setLen(len int) {
+	m.meta = len<<2 | m.meta&3
+}
+
 // OrderedMap implements an ordered map.
 //
+// Keys are ordered using the < operator. Because NaN can't be ordered this way, floating point NaN
+// keys are not supported: attempting to set one panics, and lookups of NaN never find an element.
+//
+// Values are stored directly in the tree's nodes, and every change copies the nodes on the path to
+// the changed element, values included. For large struct values, consider storing pointers
+// instead and treating the pointed-to values as immutable, so that only the pointers are copied.
+// SetIfChanged can also avoid copying entirely when a value is set to one equal to its current
+// value.
+//
+// Small maps are stored as sorted arrays: when a map with at most 16 elements is modified, the
+// result's nodes are allocated together in a single slab in ascending order of keys, and the same is
+// done for small maps that are built all at once. Lookups in such maps binary search contiguous
+// memory, and each map is a single allocation rather than one per element. Because the slab is
+// bounded in size, copying it doesn't affect the time complexities.
+//
 // Nil and the zero value for OrderedMap are both empty maps.
 type OrderedMap[K constraints.Ordered, V any] struct {
-	len   int
-	color int
+	// meta holds the number of elements in the subtree, shifted left by two bits, and the node's
+	// color in the two low bits. Packing them into one word keeps nodes small.
+	meta  int
 	left  *OrderedMap[K, V]
 	right *OrderedMap[K, V]
 	key   K
 	value V
 }
 
+// OrderedMapOf returns a new OrderedMap containing the given pairs. If a key is repeated, its last
+// value is used. Like Set, it panics if a key is NaN.
+//
+// Complexity: O(n) worst-case if the keys are in ascending order, O(n log n) otherwise
+func OrderedMapOf[K constraints.Ordered, V any](pairs ...Pair[K, V]) *OrderedMap[K, V] {
+	var b OrderedMapBuilder[K, V]
+	for _, pair := range pairs {
+		b.Set(pair.Key, pair.Value)
+	}
+	return b.Build()
+}
+
+// OrderedMapFromMap returns a new OrderedMap containing the contents of the given built-in map.
+//
+// Complexity: O(n log n) worst-case
+func OrderedMapFromMap[K constraints.Ordered, V any](m map[K]V) *OrderedMap[K, V] {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		orderedMapCheckKey(k)
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+	values := make([]V, len(keys))
+	for i, k := range keys {
+		values[i] = m[k]
+	}
+	return orderedMapFromSorted(keys, values, false)
+}
+
+// orderedMapMaxArrayLen is the largest number of elements in maps which are stored as sorted arrays.
+const orderedMapMaxArrayLen = 16
+
+// orderedMapFromSorted builds a balanced map from strictly ascending keys and their values. If arena
+// is true, or the map is small enough to be stored as a sorted array, the nodes are allocated in a
+// single slab.
+func orderedMapFromSorted[K constraints.Ordered, V any](keys []K, values []V, arena bool) *OrderedMap[K, V] {
+	var nodes []OrderedMap[K, V]
+	if arena || len(keys) <= orderedMapMaxArrayLen {
+		nodes = make([]OrderedMap[K, V], len(keys))
+	}
+	return orderedMapBuild(keys, values, nodes, 0, orderedMapRedDepth(len(keys)))
+}
+
+// orderedMapRedDepth returns the depth at which the nodes of a balanced tree with n elements are
+// colored red, or -1 if none are. If the tree isn't perfect, the nodes on the bottom level are
+// colored red so that every path has the same number of black nodes.
+func orderedMapRedDepth(n int) int {
+	if n&(n+1) == 0 {
+		return -1
+	}
+	return bits.Len(uint(n+1)) - 1
+}
+
+// orderedMapLink links a slab of nodes holding strictly ascending keys into a balanced tree, like
+// orderedMapBuild, and returns its root.
+func orderedMapLink[K constraints.Ordered, V any](nodes []OrderedMap[K, V], depth, redDepth int) *OrderedMap[K, V] {
+	if len(nodes) == 0 {
+		return nil
+	}
+	mid := len(nodes) / 2
+	ret := &nodes[mid]
+	color := orderedMapBlack
+	if depth == redDepth {
+		color = orderedMapRed
+	}
+	ret.meta = orderedMapMeta(len(nodes), color)
+	ret.left = orderedMapLink(nodes[:mid], depth+1, redDepth)
+	ret.right = orderedMapLink(nodes[mid+1:], depth+1, redDepth)
+	return ret
+}
+
+func orderedMapBuild[K constraints.Ordered, V any](keys []K, values []V, nodes []OrderedMap[K, V], depth, redDepth int) *OrderedMap[K, V] {
+	if len(keys) == 0 {
+		return nil
+	}
+	mid := len(keys) / 2
+	var ret *OrderedMap[K, V]
+	if nodes != nil {
+		ret = &nodes[mid]
+	} else {
+		ret = &OrderedMap[K, V]{}
+	}
+	ret.setLen(len(keys))
+	ret.setColor(orderedMapBlack)
+	if depth == redDepth {
+		ret.setColor(orderedMapRed)
+	}
+	var leftNodes, rightNodes []OrderedMap[K, V]
+	if nodes != nil {
+		leftNodes, rightNodes = nodes[:mid], nodes[mid+1:]
+	}
+	ret.left = orderedMapBuild(keys[:mid], values[:mid], leftNodes, depth+1, redDepth)
+	ret.right = orderedMapBuild(keys[mid+1:], values[mid+1:], rightNodes, depth+1, redDepth)
+	ret.key = keys[mid]
+	ret.value = values[mid]
+	return ret
+}
+
 // Empty returns true if the map is empty.
 //
 // Complexity: O(1) worst-case
 func (m *OrderedMap[K, V]) Empty() bool {
-	return m == nil || m.len == 0
+	return m == nil || m.size() == 0
 }
 
 // Len returns the number of elements in the map.
@@ -35,23 +188,48 @@ func (m *OrderedMap[K, V]) Len() int {
 	if m == nil {
 		return 0
 	}
-	return m.len
+	return m.size()
 }
 
 // Get returns the value associated with the given key if set.
 //
 // Complexity: O(log n) worst-case
 func (m *OrderedMap[K, V]) Get(key K) (v V, exists bool) {
-	l := m.findLessThanOrEqual(key, nil)
-	if l == nil {
+	if key != key {
 		return v, false
 	}
-	if l.key >= key {
-		return l.value, true
+	for !m.Empty() {
+		if key < m.key {
+			m = m.left
+		} else if m.key < key {
+			m = m.right
+		} else {
+			return m.value, true
+		}
 	}
 	return v, false
 }
 
+// Contains returns true if the given key is set. It's equivalent to checking the second result of
+// Get, but doesn't copy the value.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) Contains(key K) bool {
+	if key != key {
+		return false
+	}
+	for !m.Empty() {
+		if key < m.key {
+			m = m.left
+		} else if m.key < key {
+			m = m.right
+		} else {
+			return true
+		}
+	}
+	return false
+}
+
 // Set associates a value with the given key.
 //
 // Only the built-in types may be used as keys. Once a value is set within a map, all subsequent
@@ -59,22 +237,223 @@ func (m *OrderedMap[K, V]) Get(key K) (v V, exists bool) {
 //
 // Complexity: O(log n) worst-case
 func (m *OrderedMap[K, V]) Set(key K, value V) *OrderedMap[K, V] {
-	ret := m.insert(key, value)
-	ret.color = orderedMapBlack
+	orderedMapCheckKey(key)
+	if m.Len() <= orderedMapMaxArrayLen {
+		ret, _ := m.updateArray(key, func(V, bool) (V, bool) {
+			return value, true
+		})
+		return ret
+	}
+	ret := m.insert(key, value, true)
+	ret.setColor(orderedMapBlack)
 	return ret
 }
 
+// SetIfAbsent associates a value with the given key only if the key isn't already set, returning
+// whether it did. If the key is already set, the map is returned unchanged.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) SetIfAbsent(key K, value V) (*OrderedMap[K, V], bool) {
+	orderedMapCheckKey(key)
+	if m.Len() <= orderedMapMaxArrayLen {
+		if m.Contains(key) {
+			return m, false
+		}
+		ret, _ := m.updateArray(key, func(V, bool) (V, bool) {
+			return value, true
+		})
+		return ret, true
+	}
+	ret := m.insert(key, value, false)
+	if ret == nil {
+		return m, false
+	}
+	ret.setColor(orderedMapBlack)
+	return ret, true
+}
+
+// Replace associates a value with the given key only if the key is already set, returning whether
+// it did. If the key isn't set, the map is returned unchanged.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) Replace(key K, value V) (*OrderedMap[K, V], bool) {
+	if key != key {
+		return m, false
+	}
+	ret, change := m.modify(key, func(_ V, exists bool) (V, bool) {
+		return value, exists
+	})
+	if change == orderedMapUnchanged {
+		return m, false
+	}
+	return ret, true
+}
+
+// SetIfChanged is like Set, but if the key is already associated with a value equal to the given one
+// according to eq, the map is returned unchanged. Unlike Set, this doesn't copy any nodes when
+// nothing changes, which makes it cheaper for large values and allows callers to detect changes by
+// comparing maps with ==.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) SetIfChanged(key K, value V, eq func(a, b V) bool) *OrderedMap[K, V] {
+	if old, ok := m.Get(key); ok && eq(old, value) {
+		return m
+	}
+	return m.Set(key, value)
+}
+
 // Delete removes a key from the map.
 //
 // Complexity: O(log n) worst-case
 func (m *OrderedMap[K, V]) Delete(key K) *OrderedMap[K, V] {
-	if ret, _ := m.delete(key); !ret.Empty() {
-		ret.color = orderedMapBlack
+	if key != key {
+		return m
+	} else if m.Len() <= orderedMapMaxArrayLen {
+		ret, _ := m.updateArray(key, func(old V, _ bool) (V, bool) {
+			return old, false
+		})
+		return ret
+	}
+	ret, didDelete := m.delete(key)
+	if !didDelete {
+		// Don't write to the existing root, which may be read concurrently.
+		return m
+	} else if !ret.Empty() {
+		ret.setColor(orderedMapBlack)
 		return ret
 	}
 	return nil
 }
 
+// Pop removes a key from the map, returning the value that was associated with it in the same
+// traversal. If the key doesn't exist, false and the unchanged map are returned.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) Pop(key K) (value V, exists bool, ret *OrderedMap[K, V]) {
+	if key != key {
+		return value, false, m
+	}
+	ret, change := m.modify(key, func(old V, ok bool) (V, bool) {
+		value, exists = old, ok
+		return old, false
+	})
+	if change != orderedMapDeleted {
+		return value, false, m
+	} else if ret.Empty() {
+		return value, true, nil
+	}
+	ret.setColor(orderedMapBlack)
+	return value, true, ret
+}
+
+// ToMap returns a new built-in map containing the contents of the map.
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMap[K, V]) ToMap() map[K]V {
+	ret := make(map[K]V, m.Len())
+	for e := m.Min(); e != nil; e = e.Next() {
+		ret[e.Key()] = e.Value()
+	}
+	return ret
+}
+
+// Update performs a read-modify-write of the value associated with the given key in a single
+// traversal of the map. The function is given the current value and whether it exists, and returns
+// the new value and whether it should exist. If it returns false, the key is deleted.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) Update(key K, f func(old V, exists bool) (V, bool)) *OrderedMap[K, V] {
+	orderedMapCheckKey(key)
+	ret, change := m.modify(key, f)
+	switch change {
+	case orderedMapUnchanged:
+		return m
+	case orderedMapInserted:
+		ret.setColor(orderedMapBlack)
+	case orderedMapDeleted:
+		if ret.Empty() {
+			return nil
+		}
+		ret.setColor(orderedMapBlack)
+	}
+	return ret
+}
+
+// ToPairs returns the elements of the map as a slice of pairs, in ascending order of keys.
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMap[K, V]) ToPairs() []Pair[K, V] {
+	if m.Empty() {
+		return nil
+	}
+	ret := make([]Pair[K, V], 0, m.Len())
+	m.ForEach(func(key K, value V) bool {
+		ret = append(ret, MakePair(key, value))
+		return true
+	})
+	return ret
+}
+
+// ForEach calls f for each element in the map in ascending order of keys, stopping early if f
+// returns false. Unlike iterating with OrderedMapElement, this doesn't allocate.
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMap[K, V]) ForEach(f func(key K, value V) bool) {
+	var stack [orderedMapMaxHeight]*OrderedMap[K, V]
+	depth := 0
+	for {
+		for ; !m.Empty(); m = m.left {
+			stack[depth] = m
+			depth++
+		}
+		if depth == 0 {
+			return
+		}
+		depth--
+		m = stack[depth]
+		if !f(m.key, m.value) {
+			return
+		}
+		m = m.right
+	}
+}
+
+// Page returns up to limit elements in ascending order of keys, starting with the first key greater
+// than afterKey, or with the smallest key if afterKey is nil. If there are more elements after the
+// page, the returned cursor is the key of its last element and can be given as afterKey to get the
+// next page. Otherwise, the returned cursor is nil. It panics if limit is not positive.
+//
+// Since the cursor is a key rather than a position, paging through a snapshot of the map while
+// newer versions are modified neither skips nor repeats elements.
+//
+// Complexity: O(log n + limit) worst-case
+func (m *OrderedMap[K, V]) Page(afterKey *K, limit int) (page []Pair[K, V], nextCursor *K) {
+	if limit < 1 {
+		panic("immutable: Page requires a positive limit")
+	}
+	var e *OrderedMapElement[K, V]
+	if afterKey == nil {
+		e = m.Min()
+	} else {
+		e = m.MinAfter(*afterKey)
+	}
+	for ; e != nil && len(page) < limit; e = e.Next() {
+		page = append(page, MakePair(e.Key(), e.Value()))
+	}
+	if e != nil {
+		cursor := page[len(page)-1].Key
+		nextCursor = &cursor
+	}
+	return page, nextCursor
+}
+
+// orderedMapCheckKey panics if the key is NaN, which is the only value that isn't equal to itself.
+func orderedMapCheckKey[K constraints.Ordered](key K) {
+	if key != key {
+		panic("immutable: NaN keys are not supported")
+	}
+}
+
 // Min returns the minimum element in the map.
 //
 // Complexity: O(log n) worst-case
@@ -89,6 +468,36 @@ func (m *OrderedMap[K, V]) Max() *OrderedMapElement[K, V] {
 	return m.max(nil)
 }
 
+// MinKey returns the smallest key in the map, or false if the map is empty. Unlike Min, it doesn't
+// allocate.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) MinKey() (K, bool) {
+	if m.Empty() {
+		var zero K
+		return zero, false
+	}
+	for !m.left.Empty() {
+		m = m.left
+	}
+	return m.key, true
+}
+
+// MaxKey returns the largest key in the map, or false if the map is empty. Unlike Max, it doesn't
+// allocate.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) MaxKey() (K, bool) {
+	if m.Empty() {
+		var zero K
+		return zero, false
+	}
+	for !m.right.Empty() {
+		m = m.right
+	}
+	return m.key, true
+}
+
 // MinAfter returns the minimum element in the map that is greater than the given key.
 //
 // Complexity: O(log n) worst-case
@@ -103,6 +512,476 @@ func (m *OrderedMap[K, V]) MaxBefore(key K) *OrderedMapElement[K, V] {
 	return m.maxLessThan(key, nil)
 }
 
+// MinAtLeast returns the minimum element in the map that is greater than or equal to the given key.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) MinAtLeast(key K) *OrderedMapElement[K, V] {
+	if key != key {
+		return nil
+	}
+	return m.minGreaterThanOrEqual(key, nil)
+}
+
+// MaxAtMost returns the maximum element in the map that is less than or equal to the given key.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) MaxAtMost(key K) *OrderedMapElement[K, V] {
+	if key != key {
+		return nil
+	}
+	return m.maxLessThanOrEqual(key, nil)
+}
+
+// OrderedMapStats describes the structure of an OrderedMap.
+type OrderedMapStats struct {
+	// Nodes is the number of nodes in the map's tree. Each element occupies exactly one node.
+	Nodes int
+
+	// SharedNodes is the number of nodes that are shared with the other map given to Stats.
+	SharedNodes int
+
+	// Height is the number of nodes on the longest path from the root to a leaf.
+	Height int
+
+	// EstimatedBytes is an estimate of the memory occupied by the nodes. It does not include any
+	// memory referenced by keys or values, such as string contents.
+	EstimatedBytes uintptr
+
+	// EstimatedUniqueBytes is an estimate of the memory occupied by the nodes that are not shared
+	// with the other map. This is the additional memory retained by keeping this map alive in
+	// addition to the other.
+	EstimatedUniqueBytes uintptr
+}
+
+// Stats returns statistics describing the map's structure. If other is non-nil, the statistics
+// include information about the structure shared with it, such as an older version of the map.
+//
+// Complexity: O(n + m) worst-case, where m is the size of the other map
+func (m *OrderedMap[K, V]) Stats(other *OrderedMap[K, V]) OrderedMapStats {
+	nodes := make(map[*OrderedMap[K, V]]struct{}, other.Len())
+	other.collectNodes(nodes)
+	shared := m.countSharedNodes(nodes)
+	nodeSize := unsafe.Sizeof(OrderedMap[K, V]{})
+	return OrderedMapStats{
+		Nodes:                m.Len(),
+		SharedNodes:          shared,
+		Height:               m.Height(),
+		EstimatedBytes:       uintptr(m.Len()) * nodeSize,
+		EstimatedUniqueBytes: uintptr(m.Len()-shared) * nodeSize,
+	}
+}
+
+// CountMemory adds the map's nodes to the counter. Nodes which were already counted, such as those
+// shared with previously counted versions of the map, are skipped along with their subtrees.
+//
+// Complexity: O(n) worst-case, but subtrees which were already counted are not traversed
+func (m *OrderedMap[K, V]) CountMemory(c *MemoryCounter) {
+	if m.Empty() || !c.visit(unsafe.Pointer(m), unsafe.Sizeof(*m)) {
+		return
+	}
+	m.left.CountMemory(c)
+	m.right.CountMemory(c)
+}
+
+// CheckInvariants verifies the structural integrity of the map, returning an error describing the
+// first problem found. It checks that keys are in ascending order, that no red node has a red
+// child, that every path has the same number of black nodes, and that cached lengths are correct.
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMap[K, V]) CheckInvariants() error {
+	if m != nil && m.size() == 0 {
+		if m.left != nil || m.right != nil || m.color() == orderedMapDoubleBlack {
+			return fmt.Errorf("invalid empty map")
+		}
+		return nil
+	}
+	_, err := m.checkInvariants(nil, nil, false)
+	return err
+}
+
+// checkInvariants checks the invariants of the subtree, whose keys must be between lo and hi if
+// given, and returns its black height. If duplicates is true, keys may also be equal to lo or hi.
+func (m *OrderedMap[K, V]) checkInvariants(lo, hi *K, duplicates bool) (int, error) {
+	if m == nil {
+		return 0, nil
+	}
+
+	if m.color() == orderedMapDoubleBlack && m.size() == 0 {
+		return 0, fmt.Errorf("double black leaf")
+	}
+	if m.color() != orderedMapRed && m.color() != orderedMapBlack {
+		return 0, fmt.Errorf("invalid node color: %v", m.color())
+	}
+	if m.color() == orderedMapRed && ((m.left != nil && m.left.color() == orderedMapRed) || (m.right != nil && m.right.color() == orderedMapRed)) {
+		return 0, fmt.Errorf("red node has red child")
+	}
+	if duplicates {
+		if (lo != nil && m.key < *lo) || (hi != nil && *hi < m.key) {
+			return 0, fmt.Errorf("key %v is out of order", m.key)
+		}
+	} else if (lo != nil && !(*lo < m.key)) || (hi != nil && !(m.key < *hi)) {
+		return 0, fmt.Errorf("key %v is out of order", m.key)
+	}
+	if m.size() != 1+m.left.Len()+m.right.Len() {
+		return 0, fmt.Errorf("node with key %v has incorrect length %v", m.key, m.size())
+	}
+
+	left, err := m.left.checkInvariants(lo, &m.key, duplicates)
+	if err != nil {
+		return 0, err
+	}
+
+	right, err := m.right.checkInvariants(&m.key, hi, duplicates)
+	if err != nil {
+		return 0, err
+	}
+
+	if left != right {
+		return 0, fmt.Errorf("unbalanced black depths")
+	}
+
+	if m.color() == orderedMapBlack {
+		left++
+	}
+	return left, nil
+}
+
+func (m *OrderedMap[K, V]) collectNodes(nodes map[*OrderedMap[K, V]]struct{}) {
+	if m.Empty() {
+		return
+	}
+	nodes[m] = struct{}{}
+	m.left.collectNodes(nodes)
+	m.right.collectNodes(nodes)
+}
+
+func (m *OrderedMap[K, V]) countSharedNodes(nodes map[*OrderedMap[K, V]]struct{}) int {
+	if m.Empty() {
+		return 0
+	} else if _, ok := nodes[m]; ok {
+		// Nodes are never modified, so everything beneath a shared node is also shared.
+		return m.size()
+	}
+	return m.left.countSharedNodes(nodes) + m.right.countSharedNodes(nodes)
+}
+
+// Height returns the number of nodes on the longest path from the root of the map's tree to a leaf,
+// or zero if the map is empty. Red-black balancing guarantees that it's at most 2 log2(n+1), so this
+// can be used to verify that a map is balanced under a particular workload.
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMap[K, V]) Height() int {
+	if m.Empty() {
+		return 0
+	}
+	left, right := m.left.Height(), m.right.Height()
+	if left > right {
+		return left + 1
+	}
+	return right + 1
+}
+
+// Depth returns the number of nodes above the given key's node in the map's tree, so the key at the
+// root has a depth of zero. Lookups of keys with greater depths visit more nodes. If the key isn't
+// set, it returns false.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) Depth(key K) (int, bool) {
+	if key != key {
+		return 0, false
+	}
+	for depth := 0; !m.Empty(); depth++ {
+		if key < m.key {
+			m = m.left
+		} else if m.key < key {
+			m = m.right
+		} else {
+			return depth, true
+		}
+	}
+	return 0, false
+}
+
+// CountRange returns the number of elements with keys greater than or equal to lo and less than hi.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) CountRange(lo, hi K) int {
+	if lo != lo || hi != hi {
+		return 0
+	}
+	if count := m.countLess(hi) - m.countLess(lo); count > 0 {
+		return count
+	}
+	return 0
+}
+
+// DeleteRange removes all elements with keys greater than or equal to lo and less than hi.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) DeleteRange(lo, hi K) *OrderedMap[K, V] {
+	if m.CountRange(lo, hi) == 0 {
+		return m
+	}
+	left, _, rest := m.split(lo)
+	_, found, right := rest.split(hi)
+	if found != nil {
+		right = orderedMapJoin(nil, found.key, found.value, right)
+	}
+	return orderedMapJoin2(left, right)
+}
+
+// Compact returns a copy of the map whose nodes are freshly allocated in a single contiguous slab
+// and share nothing with any other map. Maps share nodes with the versions they were derived from,
+// so a long-lived map can keep the memory of many retired versions reachable through a few shared
+// nodes. Compacting it lets that memory be collected.
+//
+// Like maps built with OrderedMapBuilder.Arena, the whole slab remains in memory for as long as any
+// of its nodes are reachable, including via maps later derived from the compacted map.
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMap[K, V]) Compact() *OrderedMap[K, V] {
+	if m.Empty() {
+		return nil
+	}
+	keys := make([]K, 0, m.Len())
+	values := make([]V, 0, m.Len())
+	m.ForEach(func(key K, value V) bool {
+		keys = append(keys, key)
+		values = append(values, value)
+		return true
+	})
+	return orderedMapFromSorted(keys, values, true)
+}
+
+// DeleteIf removes all elements for which pred returns true. The predicate is called once for each
+// element in ascending order of keys. Subtrees in which nothing is removed are shared with the
+// original map, and if nothing is removed at all, the map is returned unchanged.
+//
+// This is more efficient than deleting the elements one at a time when many elements are removed.
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMap[K, V]) DeleteIf(pred func(key K, value V) bool) *OrderedMap[K, V] {
+	ret, changed := m.deleteIf(pred)
+	if !changed {
+		return m
+	}
+	return ret.blacken()
+}
+
+func (m *OrderedMap[K, V]) deleteIf(pred func(key K, value V) bool) (*OrderedMap[K, V], bool) {
+	if m.Empty() {
+		return m, false
+	}
+	left, leftChanged := m.left.deleteIf(pred)
+	del := pred(m.key, m.value)
+	right, rightChanged := m.right.deleteIf(pred)
+	if del {
+		return orderedMapJoin2(left, right), true
+	} else if leftChanged || rightChanged {
+		return orderedMapJoin(left, m.key, m.value, right), true
+	}
+	return m, false
+}
+
+// Diff calls f for each key whose presence or value differs between this map and newer, in
+// ascending order of keys. The old and new values are given along with whether they exist. Values
+// are compared using eq. If eq is nil, only keys which were inserted or deleted are reported.
+//
+// Subtrees shared by the two maps are skipped, so diffing a map against a version derived from it
+// is efficient.
+//
+// Complexity: O(n + m) worst-case, but subtrees shared by the two maps are not traversed
+func (m *OrderedMap[K, V]) Diff(newer *OrderedMap[K, V], eq func(a, b V) bool, f func(key K, old V, oldExists bool, new V, newExists bool)) {
+	var zero V
+	if m == newer || (m.Empty() && newer.Empty()) {
+		return
+	} else if m.Empty() {
+		for e := newer.Min(); e != nil; e = e.Next() {
+			f(e.Key(), zero, false, e.Value(), true)
+		}
+		return
+	} else if newer.Empty() {
+		for e := m.Min(); e != nil; e = e.Next() {
+			f(e.Key(), e.Value(), true, zero, false)
+		}
+		return
+	}
+	left, found, right := newer.split(m.key)
+	m.left.Diff(left, eq, f)
+	if found == nil {
+		f(m.key, m.value, true, zero, false)
+	} else if found != m && eq != nil && !eq(m.value, found.value) {
+		f(m.key, m.value, true, found.value, true)
+	}
+	m.right.Diff(right, eq, f)
+}
+
+// Compare compares the maps lexicographically by their elements in ascending order of keys, like
+// slices.Compare. At the first position where the elements differ, the map with the smaller key,
+// or with the smaller value according to cmpV if the keys are equal, is ordered first. If one map
+// is a prefix of the other, the shorter one is ordered first. It returns -1, 0, or 1 if this map is
+// less than, equal to, or greater than the other.
+//
+// This gives maps a total order, so snapshots can be sorted or used in canonical forms.
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMap[K, V]) Compare(other *OrderedMap[K, V], cmpV func(a, b V) int) int {
+	if m == other {
+		return 0
+	}
+	a, b := m.Iterator(), other.Iterator()
+	for {
+		okA, okB := a.Next(), b.Next()
+		if !okA || !okB {
+			if okA {
+				return 1
+			} else if okB {
+				return -1
+			}
+			return 0
+		} else if a.Key() < b.Key() {
+			return -1
+		} else if a.Key() > b.Key() {
+			return 1
+		} else if c := cmpV(a.Value(), b.Value()); c < 0 {
+			return -1
+		} else if c > 0 {
+			return 1
+		}
+	}
+}
+
+// TakeFirst returns a map containing the n elements with the smallest keys. If the map has n or
+// fewer elements, it is returned unchanged.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) TakeFirst(n int) *OrderedMap[K, V] {
+	if n >= m.Len() {
+		return m
+	}
+	left, _ := m.splitAt(n)
+	return left
+}
+
+// TakeLast returns a map containing the n elements with the largest keys. If the map has n or fewer
+// elements, it is returned unchanged.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) TakeLast(n int) *OrderedMap[K, V] {
+	if n >= m.Len() {
+		return m
+	}
+	_, right := m.splitAt(m.Len() - n)
+	return right
+}
+
+// SplitAt divides the map into a map containing the first i elements and a map containing the
+// rest. If i is out of range, one of the maps is empty and the other is this map. This is useful
+// for pagination and for dividing work into chunks of a particular size.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) SplitAt(i int) (left, right *OrderedMap[K, V]) {
+	if i <= 0 {
+		return nil, m
+	} else if i >= m.Len() {
+		return m, nil
+	}
+	left, right = m.splitAt(i)
+	return left.blacken(), right.blacken()
+}
+
+// Split divides the map into a map containing the elements with keys less than the given key and a
+// map containing the rest. It panics if the key is NaN.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) Split(key K) (left, right *OrderedMap[K, V]) {
+	orderedMapCheckKey(key)
+	return m.SplitAt(m.countLess(key))
+}
+
+// SplitN divides the map into n maps containing consecutive ranges of keys, in ascending order. The
+// maps differ in length by at most one. If the map has fewer than n elements, some of them will be
+// empty. This is useful for processing large maps across multiple goroutines. It panics if n is
+// less than one.
+//
+// Complexity: O(n log m) worst-case
+func (m *OrderedMap[K, V]) SplitN(n int) []*OrderedMap[K, V] {
+	if n < 1 {
+		panic("immutable: SplitN requires a positive number of maps")
+	}
+	ret := make([]*OrderedMap[K, V], n)
+	rest, offset := m, 0
+	for i := 0; i < n-1; i++ {
+		end := (i + 1) * m.Len() / n
+		ret[i], rest = rest.splitAt(end - offset)
+		offset = end
+	}
+	ret[n-1] = rest.blacken()
+	return ret
+}
+
+func (m *OrderedMap[K, V]) countLess(key K) int {
+	count := 0
+	for !m.Empty() {
+		if m.key < key {
+			count += 1 + m.left.Len()
+			m = m.right
+		} else {
+			m = m.left
+		}
+	}
+	return count
+}
+
+func (m *OrderedMap[K, V]) countLessOrEqual(key K) int {
+	count := 0
+	for !m.Empty() {
+		if key < m.key {
+			m = m.left
+		} else {
+			count += 1 + m.left.Len()
+			m = m.right
+		}
+	}
+	return count
+}
+
+// at returns the node with the given number of smaller elements, or nil if there isn't one.
+func (m *OrderedMap[K, V]) at(i int) *OrderedMap[K, V] {
+	for !m.Empty() {
+		if n := m.left.Len(); i < n {
+			m = m.left
+		} else if i > n {
+			i -= n + 1
+			m = m.right
+		} else {
+			return m
+		}
+	}
+	return nil
+}
+
+// elementAt is like at, but returns an element which can be used for iteration.
+func (m *OrderedMap[K, V]) elementAt(i int) *OrderedMapElement[K, V] {
+	var lineage *Stack[*OrderedMap[K, V]]
+	for !m.Empty() {
+		if n := m.left.Len(); i < n {
+			lineage = lineage.Push(m)
+			m = m.left
+		} else if i > n {
+			i -= n + 1
+			lineage = lineage.Push(m)
+			m = m.right
+		} else {
+			return &OrderedMapElement[K, V]{
+				lineage: lineage,
+				element: m,
+			}
+		}
+	}
+	return nil
+}
+
 func (m *OrderedMap[K, V]) min(lineage *Stack[*OrderedMap[K, V]]) *OrderedMapElement[K, V] {
 	if m.Empty() {
 		return nil
@@ -165,6 +1044,38 @@ func (m *OrderedMap[K, V]) maxLessThan(key K, lineage *Stack[*OrderedMap[K, V]])
 	return m.left.max(lineage.Push(m))
 }
 
+func (m *OrderedMap[K, V]) minGreaterThanOrEqual(key K, lineage *Stack[*OrderedMap[K, V]]) *OrderedMapElement[K, V] {
+	if m.Empty() {
+		return nil
+	} else if m.key < key {
+		return m.right.minGreaterThanOrEqual(key, lineage.Push(m))
+	} else if key < m.key && m.left != nil {
+		if r := m.left.minGreaterThanOrEqual(key, lineage.Push(m)); r != nil {
+			return r
+		}
+	}
+	return &OrderedMapElement[K, V]{
+		lineage: lineage,
+		element: m,
+	}
+}
+
+func (m *OrderedMap[K, V]) maxLessThanOrEqual(key K, lineage *Stack[*OrderedMap[K, V]]) *OrderedMapElement[K, V] {
+	if m.Empty() {
+		return nil
+	} else if key < m.key {
+		return m.left.maxLessThanOrEqual(key, lineage.Push(m))
+	} else if m.key < key && m.right != nil {
+		if r := m.right.maxLessThanOrEqual(key, lineage.Push(m)); r != nil {
+			return r
+		}
+	}
+	return &OrderedMapElement[K, V]{
+		lineage: lineage,
+		element: m,
+	}
+}
+
 func (m *OrderedMap[K, V]) delete(key K) (*OrderedMap[K, V], bool) {
 	if m.Empty() {
 		return m, false
@@ -182,67 +1093,271 @@ func (m *OrderedMap[K, V]) delete(key K) (*OrderedMap[K, V], bool) {
 	return m.remove(), true
 }
 
-func (m *OrderedMap[K, V]) adopt(left, right *OrderedMap[K, V]) *OrderedMap[K, V] {
-	return &OrderedMap[K, V]{
-		len:   1 + left.Len() + right.Len(),
-		color: m.color,
-		left:  left,
-		right: right,
-		key:   m.key,
-		value: m.value,
+// modify is like update, but stores the result as a sorted array if the map is small.
+func (m *OrderedMap[K, V]) modify(key K, f func(old V, exists bool) (V, bool)) (*OrderedMap[K, V], int) {
+	if m.Len() <= orderedMapMaxArrayLen {
+		return m.updateArray(key, f)
 	}
+	return m.update(key, f)
 }
 
-func (m *OrderedMap[K, V]) findLessThanOrEqual(key K, candidate *OrderedMap[K, V]) *OrderedMap[K, V] {
+// updateArray is like update, but copies the whole map into a new slab of nodes in ascending order
+// of keys. The returned map's root is black.
+func (m *OrderedMap[K, V]) updateArray(key K, f func(old V, exists bool) (V, bool)) (*OrderedMap[K, V], int) {
+	nodes := m.appendNodes(make([]OrderedMap[K, V], 0, m.Len()+1))
+	i := sort.Search(len(nodes), func(i int) bool {
+		return !(nodes[i].key < key)
+	})
+	exists := i < len(nodes) && !(key < nodes[i].key)
+	var old V
+	if exists {
+		old = nodes[i].value
+	}
+	value, keep := f(old, exists)
+	var change int
+	switch {
+	case exists && keep:
+		nodes[i].value = value
+		change = orderedMapUpdated
+	case exists:
+		copy(nodes[i:], nodes[i+1:])
+		nodes[len(nodes)-1] = OrderedMap[K, V]{}
+		nodes = nodes[:len(nodes)-1]
+		change = orderedMapDeleted
+	case keep:
+		nodes = append(nodes, OrderedMap[K, V]{})
+		copy(nodes[i+1:], nodes[i:])
+		nodes[i] = OrderedMap[K, V]{key: key, value: value}
+		change = orderedMapInserted
+	default:
+		return m, orderedMapUnchanged
+	}
+	return orderedMapLink(nodes, 0, orderedMapRedDepth(len(nodes))), change
+}
+
+// appendNodes appends unlinked copies of the map's nodes to nodes in ascending order of keys.
+func (m *OrderedMap[K, V]) appendNodes(nodes []OrderedMap[K, V]) []OrderedMap[K, V] {
 	if m.Empty() {
-		return candidate
-	} else if key < m.key {
-		return m.left.findLessThanOrEqual(key, candidate)
+		return nodes
 	}
-	return m.right.findLessThanOrEqual(key, m)
+	nodes = m.left.appendNodes(nodes)
+	nodes = append(nodes, OrderedMap[K, V]{key: m.key, value: m.value})
+	return m.right.appendNodes(nodes)
 }
 
-func (m *OrderedMap[K, V]) insert(key K, value V) *OrderedMap[K, V] {
+func (m *OrderedMap[K, V]) update(key K, f func(old V, exists bool) (V, bool)) (*OrderedMap[K, V], int) {
 	if m.Empty() {
+		var zero V
+		value, keep := f(zero, false)
+		if !keep {
+			return m, orderedMapUnchanged
+		}
 		return &OrderedMap[K, V]{
-			len:   1,
-			color: orderedMapRed,
+			meta:  orderedMapMeta(1, orderedMapRed),
 			key:   key,
 			value: value,
-		}
+		}, orderedMapInserted
 	} else if key < m.key {
-		return m.adopt(m.left.insert(key, value), m.right).balanceLeft()
+		left, change := m.left.update(key, f)
+		switch change {
+		case orderedMapUnchanged:
+			return m, change
+		case orderedMapInserted:
+			return m.adopt(left, m.right).balanceLeft(), change
+		case orderedMapDeleted:
+			return m.adopt(left, m.right).bubble(), change
+		}
+		return m.adopt(left, m.right), change
 	} else if m.key < key {
-		return m.adopt(m.left, m.right.insert(key, value)).balanceRight()
+		right, change := m.right.update(key, f)
+		switch change {
+		case orderedMapUnchanged:
+			return m, change
+		case orderedMapInserted:
+			return m.adopt(m.left, right).balanceRight(), change
+		case orderedMapDeleted:
+			return m.adopt(m.left, right).bubble(), change
+		}
+		return m.adopt(m.left, right), change
+	}
+	value, keep := f(m.value, true)
+	if !keep {
+		return m.remove(), orderedMapDeleted
 	}
 	return &OrderedMap[K, V]{
-		len:   m.len,
-		color: m.color,
+		meta:  orderedMapMeta(m.size(), m.color()),
 		left:  m.left,
 		right: m.right,
 		key:   m.key,
 		value: value,
+	}, orderedMapUpdated
+}
+
+func (m *OrderedMap[K, V]) adopt(left, right *OrderedMap[K, V]) *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{
+		meta:  orderedMapMeta(1+left.Len()+right.Len(), m.color()),
+		left:  left,
+		right: right,
+		key:   m.key,
+		value: m.value,
 	}
 }
 
+// orderedMapMaxHeight is an upper bound on the height of any map. A red-black tree's height is at
+// most twice the logarithm of its size.
+const orderedMapMaxHeight = 2 * bits.UintSize
+
+// insert returns the map with the given key set. If the key already exists, its value is replaced if
+// replace is true, and otherwise nil is returned.
+func (m *OrderedMap[K, V]) insert(key K, value V, replace bool) *OrderedMap[K, V] {
+	// Descend iteratively, recording the path so that it can be rebuilt from the bottom up.
+	var path [orderedMapMaxHeight]*OrderedMap[K, V]
+	var wentLeft [orderedMapMaxHeight]bool
+	depth := 0
+	for !m.Empty() {
+		path[depth] = m
+		if key < m.key {
+			wentLeft[depth] = true
+			m = m.left
+		} else if m.key < key {
+			wentLeft[depth] = false
+			m = m.right
+		} else {
+			break
+		}
+		depth++
+	}
+
+	if !m.Empty() {
+		if !replace {
+			return nil
+		}
+		// The key already exists, so its value is replaced and no rebalancing is necessary.
+		ret := &OrderedMap[K, V]{
+			meta:  orderedMapMeta(m.size(), m.color()),
+			left:  m.left,
+			right: m.right,
+			key:   m.key,
+			value: value,
+		}
+		for depth > 0 {
+			depth--
+			if parent := path[depth]; wentLeft[depth] {
+				ret = parent.adopt(ret, parent.right)
+			} else {
+				ret = parent.adopt(parent.left, ret)
+			}
+		}
+		return ret
+	}
+
+	ret := &OrderedMap[K, V]{
+		meta:  orderedMapMeta(1, orderedMapRed),
+		key:   key,
+		value: value,
+	}
+	// child is the node below ret on the rebuilt path. Both were allocated by this insertion and
+	// aren't shared with any other map yet, so a rotation can reuse them in place rather than
+	// allocating replacements and discarding them.
+	var child *OrderedMap[K, V]
+	childIsLeft := false
+	for depth > 0 {
+		depth--
+		parent, isLeft := path[depth], wentLeft[depth]
+		if parent.color() == orderedMapBlack && ret.color() == orderedMapRed && child != nil && child.color() == orderedMapRed {
+			// The only possible red-red violation is between ret and child, since every other node on
+			// the path either keeps its original children or was produced by a rotation with black
+			// ones. After the rotation, ret's children are black.
+			orderedMapInsertRotation(parent, isLeft, ret, child, childIsLeft)
+			child = nil
+			continue
+		}
+		if isLeft {
+			ret, child = parent.adopt(ret, parent.right), ret
+		} else {
+			ret, child = parent.adopt(parent.left, ret), ret
+		}
+		childIsLeft = isLeft
+	}
+	return ret
+}
+
+// orderedMapInsertRotation resolves a red-red violation between top, a newly allocated red node
+// which replaces one of parent's children, and child, a newly allocated red child of top. This is
+// equivalent to balanceLeft or balanceRight, but top becomes the root of the balanced subtree and
+// child becomes one of its black children, so only one new node needs to be allocated.
+func orderedMapInsertRotation[K constraints.Ordered, V any](parent *OrderedMap[K, V], topIsLeft bool, top, child *OrderedMap[K, V], childIsLeft bool) {
+	// Order the three nodes and the four subtrees hanging off of them by key.
+	var a, b, c OrderedMap[K, V]
+	var t1, t2, t3, t4 *OrderedMap[K, V]
+	switch {
+	case topIsLeft && childIsLeft:
+		a, b, c = *child, *top, *parent
+		t1, t2, t3, t4 = child.left, child.right, top.right, parent.right
+	case topIsLeft:
+		a, b, c = *top, *child, *parent
+		t1, t2, t3, t4 = top.left, child.left, child.right, parent.right
+	case childIsLeft:
+		a, b, c = *parent, *child, *top
+		t1, t2, t3, t4 = parent.left, child.left, child.right, top.right
+	default:
+		a, b, c = *parent, *top, *child
+		t1, t2, t3, t4 = parent.left, top.left, child.left, child.right
+	}
+	*child = OrderedMap[K, V]{
+		meta:  orderedMapMeta(1+t1.Len()+t2.Len(), orderedMapBlack),
+		left:  t1,
+		right: t2,
+		key:   a.key,
+		value: a.value,
+	}
+	right := &OrderedMap[K, V]{
+		meta:  orderedMapMeta(1+t3.Len()+t4.Len(), orderedMapBlack),
+		left:  t3,
+		right: t4,
+		key:   c.key,
+		value: c.value,
+	}
+	*top = OrderedMap[K, V]{
+		meta:  orderedMapMeta(parent.size()+1, parent.color()-1),
+		left:  child,
+		right: right,
+		key:   b.key,
+		value: b.value,
+	}
+}
+
+// insertDuplicate is like insert, but if the key is already present, a new element is added after
+// the existing ones instead of replacing them. Maps with duplicate keys are only used internally,
+// and most methods will not behave correctly on them.
+func (m *OrderedMap[K, V]) insertDuplicate(key K, value V) *OrderedMap[K, V] {
+	if m.Empty() {
+		return &OrderedMap[K, V]{
+			meta:  orderedMapMeta(1, orderedMapRed),
+			key:   key,
+			value: value,
+		}
+	} else if key < m.key {
+		return m.adopt(m.left.insertDuplicate(key, value), m.right).balanceLeft()
+	}
+	return m.adopt(m.left, m.right.insertDuplicate(key, value)).balanceRight()
+}
+
 func (m *OrderedMap[K, V]) balanceLeft() *OrderedMap[K, V] {
-	if m.color >= orderedMapBlack && m.left != nil {
-		if m.left.color == orderedMapRed {
-			if m.left.left != nil && m.left.left.color == orderedMapRed {
+	if m.color() >= orderedMapBlack && m.left != nil {
+		if m.left.color() == orderedMapRed {
+			if m.left.left != nil && m.left.left.color() == orderedMapRed {
 				return &OrderedMap[K, V]{
-					len:   m.len,
-					color: m.color - 1,
+					meta: orderedMapMeta(m.size(), m.color()-1),
 					left: &OrderedMap[K, V]{
-						len:   m.left.left.len,
-						color: orderedMapBlack,
+						meta:  orderedMapMeta(m.left.left.size(), orderedMapBlack),
 						left:  m.left.left.left,
 						right: m.left.left.right,
 						key:   m.left.left.key,
 						value: m.left.left.value,
 					},
 					right: &OrderedMap[K, V]{
-						len:   1 + m.left.right.Len() + m.right.Len(),
-						color: orderedMapBlack,
+						meta:  orderedMapMeta(1+m.left.right.Len()+m.right.Len(), orderedMapBlack),
 						left:  m.left.right,
 						right: m.right,
 						key:   m.key,
@@ -251,21 +1366,18 @@ func (m *OrderedMap[K, V]) balanceLeft() *OrderedMap[K, V] {
 					key:   m.left.key,
 					value: m.left.value,
 				}
-			} else if m.left.right != nil && m.left.right.color == orderedMapRed {
+			} else if m.left.right != nil && m.left.right.color() == orderedMapRed {
 				return &OrderedMap[K, V]{
-					len:   m.len,
-					color: m.color - 1,
+					meta: orderedMapMeta(m.size(), m.color()-1),
 					left: &OrderedMap[K, V]{
-						len:   1 + m.left.left.Len() + m.left.right.left.Len(),
-						color: orderedMapBlack,
+						meta:  orderedMapMeta(1+m.left.left.Len()+m.left.right.left.Len(), orderedMapBlack),
 						left:  m.left.left,
 						right: m.left.right.left,
 						key:   m.left.key,
 						value: m.left.value,
 					},
 					right: &OrderedMap[K, V]{
-						len:   1 + m.left.right.right.Len() + m.right.Len(),
-						color: orderedMapBlack,
+						meta:  orderedMapMeta(1+m.left.right.right.Len()+m.right.Len(), orderedMapBlack),
 						left:  m.left.right.right,
 						right: m.right,
 						key:   m.key,
@@ -275,10 +1387,9 @@ func (m *OrderedMap[K, V]) balanceLeft() *OrderedMap[K, V] {
 					value: m.left.right.value,
 				}
 			}
-		} else if m.left.color == orderedMapNegativeBlack {
+		} else if m.left.color() == orderedMapNegativeBlack {
 			left := &OrderedMap[K, V]{
-				len:   1 + m.left.left.Len() + m.left.right.left.Len(),
-				color: orderedMapBlack,
+				meta:  orderedMapMeta(1+m.left.left.Len()+m.left.right.left.Len(), orderedMapBlack),
 				left:  m.left.left.redden(),
 				right: m.left.right.left,
 				key:   m.left.key,
@@ -286,16 +1397,14 @@ func (m *OrderedMap[K, V]) balanceLeft() *OrderedMap[K, V] {
 			}
 			left = left.balanceLeft()
 			right := &OrderedMap[K, V]{
-				len:   1 + m.left.right.right.Len() + m.right.Len(),
-				color: orderedMapBlack,
+				meta:  orderedMapMeta(1+m.left.right.right.Len()+m.right.Len(), orderedMapBlack),
 				left:  m.left.right.right,
 				right: m.right,
 				key:   m.key,
 				value: m.value,
 			}
 			return &OrderedMap[K, V]{
-				len:   1 + left.Len() + right.Len(),
-				color: orderedMapBlack,
+				meta:  orderedMapMeta(1+left.Len()+right.Len(), orderedMapBlack),
 				left:  left,
 				right: right,
 				key:   m.left.right.key,
@@ -307,23 +1416,20 @@ func (m *OrderedMap[K, V]) balanceLeft() *OrderedMap[K, V] {
 }
 
 func (m *OrderedMap[K, V]) balanceRight() *OrderedMap[K, V] {
-	if m.color >= orderedMapBlack && m.right != nil {
-		if m.right.color == orderedMapRed {
-			if m.right.left != nil && m.right.left.color == orderedMapRed {
+	if m.color() >= orderedMapBlack && m.right != nil {
+		if m.right.color() == orderedMapRed {
+			if m.right.left != nil && m.right.left.color() == orderedMapRed {
 				return &OrderedMap[K, V]{
-					len:   m.len,
-					color: m.color - 1,
+					meta: orderedMapMeta(m.size(), m.color()-1),
 					left: &OrderedMap[K, V]{
-						len:   1 + m.left.Len() + m.right.left.left.Len(),
-						color: orderedMapBlack,
+						meta:  orderedMapMeta(1+m.left.Len()+m.right.left.left.Len(), orderedMapBlack),
 						left:  m.left,
 						right: m.right.left.left,
 						key:   m.key,
 						value: m.value,
 					},
 					right: &OrderedMap[K, V]{
-						len:   1 + m.right.left.right.Len() + m.right.right.Len(),
-						color: orderedMapBlack,
+						meta:  orderedMapMeta(1+m.right.left.right.Len()+m.right.right.Len(), orderedMapBlack),
 						left:  m.right.left.right,
 						right: m.right.right,
 						key:   m.right.key,
@@ -332,21 +1438,18 @@ func (m *OrderedMap[K, V]) balanceRight() *OrderedMap[K, V] {
 					key:   m.right.left.key,
 					value: m.right.left.value,
 				}
-			} else if m.right.right != nil && m.right.right.color == orderedMapRed {
+			} else if m.right.right != nil && m.right.right.color() == orderedMapRed {
 				return &OrderedMap[K, V]{
-					len:   m.len,
-					color: m.color - 1,
+					meta: orderedMapMeta(m.size(), m.color()-1),
 					left: &OrderedMap[K, V]{
-						len:   1 + m.left.Len() + m.right.left.Len(),
-						color: orderedMapBlack,
+						meta:  orderedMapMeta(1+m.left.Len()+m.right.left.Len(), orderedMapBlack),
 						left:  m.left,
 						right: m.right.left,
 						key:   m.key,
 						value: m.value,
 					},
 					right: &OrderedMap[K, V]{
-						len:   m.right.right.len,
-						color: orderedMapBlack,
+						meta:  orderedMapMeta(m.right.right.size(), orderedMapBlack),
 						left:  m.right.right.left,
 						right: m.right.right.right,
 						key:   m.right.right.key,
@@ -356,18 +1459,16 @@ func (m *OrderedMap[K, V]) balanceRight() *OrderedMap[K, V] {
 					value: m.right.value,
 				}
 			}
-		} else if m.right.color == orderedMapNegativeBlack {
+		} else if m.right.color() == orderedMapNegativeBlack {
 			left := &OrderedMap[K, V]{
-				len:   1 + m.left.Len() + m.right.left.left.Len(),
-				color: orderedMapBlack,
+				meta:  orderedMapMeta(1+m.left.Len()+m.right.left.left.Len(), orderedMapBlack),
 				left:  m.left,
 				right: m.right.left.left,
 				key:   m.key,
 				value: m.value,
 			}
 			right := &OrderedMap[K, V]{
-				len:   1 + m.right.left.right.Len() + m.right.right.Len(),
-				color: orderedMapBlack,
+				meta:  orderedMapMeta(1+m.right.left.right.Len()+m.right.right.Len(), orderedMapBlack),
 				left:  m.right.left.right,
 				right: m.right.right.redden(),
 				key:   m.right.key,
@@ -375,8 +1476,7 @@ func (m *OrderedMap[K, V]) balanceRight() *OrderedMap[K, V] {
 			}
 			right = right.balanceRight()
 			return &OrderedMap[K, V]{
-				len:   1 + left.Len() + right.Len(),
-				color: orderedMapBlack,
+				meta:  orderedMapMeta(1+left.Len()+right.Len(), orderedMapBlack),
 				left:  left,
 				right: right,
 				key:   m.right.left.key,
@@ -391,8 +1491,7 @@ func (m *OrderedMap[K, V]) remove() *OrderedMap[K, V] {
 	if !m.left.Empty() && !m.right.Empty() {
 		left, removed := m.left.removeMax()
 		reduced := &OrderedMap[K, V]{
-			len:   m.len - 1,
-			color: m.color,
+			meta:  orderedMapMeta(m.size()-1, m.color()),
 			left:  left,
 			right: m.right,
 			key:   removed.key,
@@ -406,13 +1505,13 @@ func (m *OrderedMap[K, V]) remove() *OrderedMap[K, V] {
 	} else if !m.right.Empty() {
 		child = m.right
 	} else {
-		if m.color == orderedMapRed {
+		if m.color() == orderedMapRed {
 			return nil
 		}
-		return &OrderedMap[K, V]{color: orderedMapDoubleBlack}
+		return &OrderedMap[K, V]{meta: orderedMapMeta(0, orderedMapDoubleBlack)}
 	}
 	ret := *child
-	ret.color = orderedMapBlack
+	ret.setColor(orderedMapBlack)
 	return &ret
 }
 
@@ -425,25 +1524,24 @@ func (m *OrderedMap[K, V]) removeMax() (result, removed *OrderedMap[K, V]) {
 }
 
 func (m *OrderedMap[K, V]) redden() *OrderedMap[K, V] {
-	if m.color == orderedMapDoubleBlack && m.len == 0 {
+	if m.color() == orderedMapDoubleBlack && m.size() == 0 {
 		return nil
 	}
 	ret := *m
-	ret.color--
+	ret.setColor(ret.color() - 1)
 	return &ret
 }
 
 func (m *OrderedMap[K, V]) bubble() *OrderedMap[K, V] {
-	if (m.left != nil && m.left.color == orderedMapDoubleBlack) || (m.right != nil && m.right.color == orderedMapDoubleBlack) {
+	if (m.left != nil && m.left.color() == orderedMapDoubleBlack) || (m.right != nil && m.right.color() == orderedMapDoubleBlack) {
 		unbalanced := &OrderedMap[K, V]{
-			len:   m.len,
-			color: m.color + 1,
+			meta:  orderedMapMeta(m.size(), m.color()+1),
 			left:  m.left.redden(),
 			right: m.right.redden(),
 			key:   m.key,
 			value: m.value,
 		}
-		if m.left != nil && m.left.color == orderedMapDoubleBlack {
+		if m.left != nil && m.left.color() == orderedMapDoubleBlack {
 			return unbalanced.balanceRight()
 		}
 		return unbalanced.balanceLeft()
@@ -451,6 +1549,180 @@ func (m *OrderedMap[K, V]) bubble() *OrderedMap[K, V] {
 	return m
 }
 
+// split returns a map containing the elements less than the given key, the node with the given key
+// if one exists, and a map containing the elements greater than the given key.
+func (m *OrderedMap[K, V]) split(key K) (left, found, right *OrderedMap[K, V]) {
+	if m.Empty() {
+		return nil, nil, nil
+	} else if key < m.key {
+		left, found, right = m.left.split(key)
+		return left, found, orderedMapJoin(right, m.key, m.value, m.right)
+	} else if m.key < key {
+		left, found, right = m.right.split(key)
+		return orderedMapJoin(m.left, m.key, m.value, left), found, right
+	}
+	return m.left.blacken(), m, m.right.blacken()
+}
+
+// splitAt returns a map containing the first i elements and a map containing the rest.
+func (m *OrderedMap[K, V]) splitAt(i int) (left, right *OrderedMap[K, V]) {
+	if m.Empty() {
+		return nil, nil
+	} else if i <= m.left.Len() {
+		left, right = m.left.splitAt(i)
+		return left, orderedMapJoin(right, m.key, m.value, m.right)
+	}
+	left, right = m.right.splitAt(i - m.left.Len() - 1)
+	return orderedMapJoin(m.left, m.key, m.value, left), right
+}
+
+// splitLess returns a map containing the elements less than the given key and a map containing the
+// rest. Unlike split, it supports maps with duplicate keys.
+func (m *OrderedMap[K, V]) splitLess(key K) (left, right *OrderedMap[K, V]) {
+	if m.Empty() {
+		return nil, nil
+	} else if m.key < key {
+		left, right = m.right.splitLess(key)
+		return orderedMapJoin(m.left, m.key, m.value, left), right
+	}
+	left, right = m.left.splitLess(key)
+	return left, orderedMapJoin(right, m.key, m.value, m.right)
+}
+
+// splitLessOrEqual returns a map containing the elements less than or equal to the given key and a
+// map containing the rest. Unlike split, it supports maps with duplicate keys.
+func (m *OrderedMap[K, V]) splitLessOrEqual(key K) (left, right *OrderedMap[K, V]) {
+	if m.Empty() {
+		return nil, nil
+	} else if key < m.key {
+		left, right = m.left.splitLessOrEqual(key)
+		return left, orderedMapJoin(right, m.key, m.value, m.right)
+	}
+	left, right = m.right.splitLessOrEqual(key)
+	return orderedMapJoin(m.left, m.key, m.value, left), right
+}
+
+// orderedMapJoin returns a map containing the elements of left, the given key and value, and the
+// elements of right. All keys in left must be less than the given key, and all keys in right must be
+// greater.
+func orderedMapJoin[K constraints.Ordered, V any](left *OrderedMap[K, V], key K, value V, right *OrderedMap[K, V]) *OrderedMap[K, V] {
+	left, right = left.blacken(), right.blacken()
+	leftHeight, rightHeight := left.blackHeight(), right.blackHeight()
+	if leftHeight > rightHeight {
+		return left.joinRight(leftHeight, key, value, right, rightHeight).blacken()
+	} else if rightHeight > leftHeight {
+		return right.joinLeft(rightHeight, key, value, left, leftHeight).blacken()
+	}
+	return &OrderedMap[K, V]{
+		meta:  orderedMapMeta(1+left.Len()+right.Len(), orderedMapBlack),
+		left:  left,
+		right: right,
+		key:   key,
+		value: value,
+	}
+}
+
+// orderedMapJoin2 returns a map containing the elements of left and right. All keys in left must be
+// less than all keys in right.
+func orderedMapJoin2[K constraints.Ordered, V any](left, right *OrderedMap[K, V]) *OrderedMap[K, V] {
+	if left.Empty() {
+		return right.blacken()
+	}
+	max := left.max(nil).element
+	return orderedMapJoin(left.Delete(max.key), max.key, max.value, right)
+}
+
+// joinRight joins right onto the right spine of m, which must have a greater black height.
+func (m *OrderedMap[K, V]) joinRight(height int, key K, value V, right *OrderedMap[K, V], rightHeight int) *OrderedMap[K, V] {
+	if (m.Empty() || m.color() == orderedMapBlack) && height == rightHeight {
+		return &OrderedMap[K, V]{
+			meta:  orderedMapMeta(1+m.Len()+right.Len(), orderedMapRed),
+			left:  m,
+			right: right,
+			key:   key,
+			value: value,
+		}
+	}
+	childHeight := height
+	if m.color() == orderedMapBlack {
+		childHeight--
+	}
+	r := m.right.joinRight(childHeight, key, value, right, rightHeight)
+	if m.color() == orderedMapBlack && r.color() == orderedMapRed && r.right != nil && r.right.color() == orderedMapRed {
+		return &OrderedMap[K, V]{
+			meta: orderedMapMeta(1+m.left.Len()+r.size(), orderedMapRed),
+			left: &OrderedMap[K, V]{
+				meta:  orderedMapMeta(1+m.left.Len()+r.left.Len(), orderedMapBlack),
+				left:  m.left,
+				right: r.left,
+				key:   m.key,
+				value: m.value,
+			},
+			right: r.right.blacken(),
+			key:   r.key,
+			value: r.value,
+		}
+	}
+	return m.adopt(m.left, r)
+}
+
+// joinLeft joins left onto the left spine of m, which must have a greater black height.
+func (m *OrderedMap[K, V]) joinLeft(height int, key K, value V, left *OrderedMap[K, V], leftHeight int) *OrderedMap[K, V] {
+	if (m.Empty() || m.color() == orderedMapBlack) && height == leftHeight {
+		return &OrderedMap[K, V]{
+			meta:  orderedMapMeta(1+left.Len()+m.Len(), orderedMapRed),
+			left:  left,
+			right: m,
+			key:   key,
+			value: value,
+		}
+	}
+	childHeight := height
+	if m.color() == orderedMapBlack {
+		childHeight--
+	}
+	l := m.left.joinLeft(childHeight, key, value, left, leftHeight)
+	if m.color() == orderedMapBlack && l.color() == orderedMapRed && l.left != nil && l.left.color() == orderedMapRed {
+		return &OrderedMap[K, V]{
+			meta: orderedMapMeta(1+l.size()+m.right.Len(), orderedMapRed),
+			left: l.left.blacken(),
+			right: &OrderedMap[K, V]{
+				meta:  orderedMapMeta(1+l.right.Len()+m.right.Len(), orderedMapBlack),
+				left:  l.right,
+				right: m.right,
+				key:   m.key,
+				value: m.value,
+			},
+			key:   l.key,
+			value: l.value,
+		}
+	}
+	return m.adopt(l, m.right)
+}
+
+// blackHeight returns the number of black nodes on each path from the root to a leaf.
+func (m *OrderedMap[K, V]) blackHeight() int {
+	height := 0
+	for ; !m.Empty(); m = m.left {
+		if m.color() == orderedMapBlack {
+			height++
+		}
+	}
+	return height
+}
+
+// blacken returns the map with a black root.
+func (m *OrderedMap[K, V]) blacken() *OrderedMap[K, V] {
+	if m.Empty() {
+		return nil
+	} else if m.color() == orderedMapBlack {
+		return m
+	}
+	ret := *m
+	ret.setColor(orderedMapBlack)
+	return &ret
+}
+
 // OrderedMapElement represents a key-value pair and can be used to iterate over elements in a map.
 type OrderedMapElement[K constraints.Ordered, V any] struct {
 	lineage *Stack[*OrderedMap[K, V]]
@@ -467,6 +1739,32 @@ func (e *OrderedMapElement[K, V]) Value() V {
 	return e.element.value
 }
 
+// SetValue returns a new version of the map in which the represented element has the given value.
+// Because the element already knows its position within the map, this is faster than using Set with
+// its key.
+//
+// Complexity: O(log n) worst-case
+func (e *OrderedMapElement[K, V]) SetValue(value V) *OrderedMap[K, V] {
+	replaced := e.element
+	ret := &OrderedMap[K, V]{
+		meta:  orderedMapMeta(replaced.size(), replaced.color()),
+		left:  replaced.left,
+		right: replaced.right,
+		key:   replaced.key,
+		value: value,
+	}
+	for l := e.lineage; !l.Empty(); l = l.Pop() {
+		parent := l.Peek()
+		if parent.left == replaced {
+			ret = parent.adopt(ret, parent.right)
+		} else {
+			ret = parent.adopt(parent.left, ret)
+		}
+		replaced = parent
+	}
+	return ret
+}
+
 // Next returns the next element in the map.
 //
 // Complexity: O(log n) worst-case, amortized O(1) if iterating over the entire map
@@ -0,0 +1,85 @@
+package immutable
+
+import (
+	"sort"
+
+	"golang.org/x/exp/constraints"
+)
+
+// OrderedMapBuilder efficiently constructs an OrderedMap from many elements.
+//
+// Rather than rebalancing the tree after each element like Set does, the builder buffers elements and
+// constructs a balanced tree all at once.
+//
+// The zero value is an empty builder ready to use. Builders are not safe for concurrent use.
+type OrderedMapBuilder[K constraints.Ordered, V any] struct {
+	// If Arena is true, the nodes of built maps are allocated in a single contiguous slab instead of
+	// individually. This greatly reduces the number of objects the garbage collector needs to track,
+	// but the entire slab remains in memory for as long as any of its nodes are reachable, including
+	// via maps derived from the built map.
+	Arena bool
+
+	// If Intern is non-nil, each key is replaced with its result before being stored, such as a
+	// canonical copy from the function Intern.
+	Intern func(key K) K
+
+	keys   []K
+	values []V
+}
+
+// Set associates a value with the given key. If the key is set multiple times, the last value wins.
+// Like OrderedMap.Set, it panics if the key is NaN.
+//
+// Complexity: amortized O(1)
+func (b *OrderedMapBuilder[K, V]) Set(key K, value V) {
+	orderedMapCheckKey(key)
+	if b.Intern != nil {
+		key = b.Intern(key)
+	}
+	b.keys = append(b.keys, key)
+	b.values = append(b.values, value)
+}
+
+// Len returns the number of elements that have been set, including duplicates.
+//
+// Complexity: O(1) worst-case
+func (b *OrderedMapBuilder[K, V]) Len() int {
+	return len(b.keys)
+}
+
+// Build returns a map containing the elements that have been set and resets the builder.
+//
+// Complexity: O(n) worst-case if the elements were set in ascending order, O(n log n) otherwise
+func (b *OrderedMapBuilder[K, V]) Build() *OrderedMap[K, V] {
+	keys, values := b.keys, b.values
+	b.keys, b.values = nil, nil
+
+	for i := 1; i < len(keys); i++ {
+		if !(keys[i-1] < keys[i]) {
+			keys, values = orderedMapBuilderSort(keys, values)
+			break
+		}
+	}
+	return orderedMapFromSorted(keys, values, b.Arena)
+}
+
+// orderedMapBuilderSort sorts the elements by key, keeping only the last value for each key.
+func orderedMapBuilderSort[K constraints.Ordered, V any](keys []K, values []V) ([]K, []V) {
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return keys[order[i]] < keys[order[j]]
+	})
+	sortedKeys := make([]K, 0, len(keys))
+	sortedValues := make([]V, 0, len(values))
+	for i, idx := range order {
+		if i+1 < len(order) && !(keys[idx] < keys[order[i+1]]) {
+			continue
+		}
+		sortedKeys = append(sortedKeys, keys[idx])
+		sortedValues = append(sortedValues, values[idx])
+	}
+	return sortedKeys, sortedValues
+}
@@ -0,0 +1,61 @@
+package immutable
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedMapBuilder(t *testing.T) {
+	for _, arena := range []bool{false, true} {
+		b := &OrderedMapBuilder[int, int]{
+			Arena: arena,
+		}
+		assert.Nil(t, b.Build())
+
+		for n := 0; n < 200; n++ {
+			ref := make(map[int]int)
+			sorted := n%2 == 0
+			for i := 0; i < n; i++ {
+				k := i
+				if !sorted {
+					k = rand.Intn(n)
+				}
+				ref[k] = i
+				b.Set(k, i)
+			}
+			assert.Equal(t, n, b.Len())
+			m := b.Build()
+			assert.Equal(t, 0, b.Len())
+			require.NoError(t, m.CheckInvariants(), fmt.Sprintf("arena=%v,n=%v", arena, n))
+			assert.Equal(t, len(ref), m.Len())
+			assert.Equal(t, ref, m.ToMap())
+
+			// Maps built in an arena must still be safely modifiable.
+			m2 := m.Set(-1, -1).Delete(0)
+			require.NoError(t, m2.CheckInvariants())
+			assert.Equal(t, ref, m.ToMap())
+		}
+	}
+}
+
+func BenchmarkOrderedMapBuilder(b *testing.B) {
+	for _, arena := range []bool{false, true} {
+		for _, n := range []int{100, 10000, 1000000} {
+			b.Run(fmt.Sprintf("arena=%v,n=%v", arena, n), func(b *testing.B) {
+				builder := &OrderedMapBuilder[int, string]{
+					Arena: arena,
+				}
+				for i := 0; i < b.N; i++ {
+					for j := 0; j < n; j++ {
+						builder.Set(j, "foo")
+					}
+					orderedMapResult = builder.Build()
+				}
+			})
+		}
+	}
+}
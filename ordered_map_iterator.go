@@ -0,0 +1,100 @@
+package immutable
+
+import (
+	"math/bits"
+
+	"golang.org/x/exp/constraints"
+)
+
+// OrderedMapIterator iterates over the elements of a map in ascending order of keys.
+//
+// Unlike OrderedMapElement, an iterator is mutable: advancing it modifies it in place, reusing its
+// internal storage. This means that scanning a map doesn't allocate for each element. The map
+// itself is not modified, and any number of iterators may be used concurrently over the same map,
+// but an individual iterator must not be used concurrently.
+//
+// The zero value for OrderedMapIterator is an exhausted iterator.
+type OrderedMapIterator[K constraints.Ordered, V any] struct {
+	root    *OrderedMap[K, V]
+	stack   []*OrderedMap[K, V]
+	current *OrderedMap[K, V]
+}
+
+// Iterator returns an iterator positioned before the first element of the map. The elements can be
+// iterated over like so:
+//
+//	for it := m.Iterator(); it.Next(); {
+//		...
+//	}
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) Iterator() *OrderedMapIterator[K, V] {
+	it := &OrderedMapIterator[K, V]{
+		root:  m,
+		stack: make([]*OrderedMap[K, V], 0, 2*bits.Len(uint(m.Len()))),
+	}
+	it.pushLeft(m)
+	return it
+}
+
+func (it *OrderedMapIterator[K, V]) pushLeft(m *OrderedMap[K, V]) {
+	for ; !m.Empty(); m = m.left {
+		it.stack = append(it.stack, m)
+	}
+}
+
+// Next advances the iterator to the next element, returning false if there are no more elements.
+//
+// Complexity: O(log n) worst-case, amortized O(1) if iterating over the entire map
+func (it *OrderedMapIterator[K, V]) Next() bool {
+	if len(it.stack) == 0 {
+		it.current = nil
+		return false
+	}
+	it.current = it.stack[len(it.stack)-1]
+	it.stack = it.stack[:len(it.stack)-1]
+	it.pushLeft(it.current.right)
+	return true
+}
+
+// Seek repositions the iterator so that the next call to Next advances it to the minimum element
+// with a key greater than or equal to the given key.
+//
+// Complexity: O(log n) worst-case
+func (it *OrderedMapIterator[K, V]) Seek(key K) {
+	it.stack = it.stack[:0]
+	it.current = nil
+	for m := it.root; !m.Empty(); {
+		if m.key < key {
+			m = m.right
+		} else {
+			it.stack = append(it.stack, m)
+			m = m.left
+		}
+	}
+}
+
+// seekAfter is like Seek, but positions the iterator before the minimum element with a key greater
+// than the given key.
+func (it *OrderedMapIterator[K, V]) seekAfter(key K) {
+	it.stack = it.stack[:0]
+	it.current = nil
+	for m := it.root; !m.Empty(); {
+		if !(key < m.key) {
+			m = m.right
+		} else {
+			it.stack = append(it.stack, m)
+			m = m.left
+		}
+	}
+}
+
+// Key returns the key of the current element. It must only be called after Next returns true.
+func (it *OrderedMapIterator[K, V]) Key() K {
+	return it.current.key
+}
+
+// Value returns the value of the current element. It must only be called after Next returns true.
+func (it *OrderedMapIterator[K, V]) Value() V {
+	return it.current.value
+}
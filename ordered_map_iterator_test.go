@@ -0,0 +1,63 @@
+package immutable
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedMapIterator(t *testing.T) {
+	var m *OrderedMap[int, int]
+	assert.False(t, m.Iterator().Next())
+	assert.False(t, (&OrderedMapIterator[int, int]{}).Next())
+
+	for i := 0; i < 1000; i++ {
+		m = m.Set(rand.Intn(2000), i)
+	}
+	e := m.Min()
+	for it := m.Iterator(); it.Next(); e = e.Next() {
+		require.NotNil(t, e)
+		require.Equal(t, e.Key(), it.Key())
+		require.Equal(t, e.Value(), it.Value())
+	}
+	assert.Nil(t, e)
+}
+
+func TestOrderedMapIterator_Seek(t *testing.T) {
+	var m *OrderedMap[int, int]
+	for i := 0; i < 100; i++ {
+		m = m.Set(i*2, i)
+	}
+	it := m.Iterator()
+	for i := -1; i < 200; i++ {
+		it.Seek(i)
+		e := m.MinAfter(i - 1)
+		for j := 0; j < 3 && e != nil; j++ {
+			require.True(t, it.Next())
+			require.Equal(t, e.Key(), it.Key())
+			e = e.Next()
+		}
+		if e == nil {
+			require.False(t, it.Next())
+		}
+	}
+}
+
+func BenchmarkOrderedMapIterator(b *testing.B) {
+	for _, n := range []int{100, 10000, 1000000} {
+		m := &OrderedMap[int, string]{}
+		for i := 0; i < n; i++ {
+			m = m.Set(i, "foo")
+		}
+		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for it := m.Iterator(); it.Next(); {
+					orderedMapKeyResult = it.Key()
+				}
+			}
+		})
+	}
+}
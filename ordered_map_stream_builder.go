@@ -0,0 +1,86 @@
+package immutable
+
+import "golang.org/x/exp/constraints"
+
+// OrderedMapStreamBuilder constructs an OrderedMap from elements given in strictly ascending order
+// of keys, such as when loading sorted data from disk or the network.
+//
+// Unlike OrderedMapBuilder, it doesn't buffer the elements: each one is placed in the tree as soon
+// as it's set, so beyond the nodes of the map itself, only O(log n) memory is used. The tree is
+// assembled from perfectly balanced subtrees, much like incrementing a binary counter.
+//
+// The zero value is an empty builder ready to use. Builders are not safe for concurrent use.
+type OrderedMapStreamBuilder[K constraints.Ordered, V any] struct {
+	// levels[h], if not nil, is an element waiting for a right subtree with black height h. Its left
+	// subtree is a perfect tree with black height h.
+	levels []*OrderedMap[K, V]
+	len    int
+}
+
+// Set adds an element to the map. It panics if the key isn't greater than every key set before it,
+// or if it's NaN.
+//
+// Complexity: amortized O(1)
+func (b *OrderedMapStreamBuilder[K, V]) Set(key K, value V) {
+	orderedMapCheckKey(key)
+	if last, ok := b.maxKey(); ok && !(last < key) {
+		panic("immutable: OrderedMapStreamBuilder keys must be strictly ascending")
+	}
+
+	// Complete the pending elements from the bottom up, carrying the resulting perfect trees upward
+	// until there's a free level for the new element. Pending elements are never shared with built
+	// maps, so they can be completed in place.
+	var carry *OrderedMap[K, V]
+	h := 0
+	for ; h < len(b.levels) && b.levels[h] != nil; h++ {
+		pending := b.levels[h]
+		b.levels[h] = nil
+		pending.right = carry
+		pending.meta = orderedMapMeta(1+pending.left.Len()+carry.Len(), orderedMapBlack)
+		carry = pending
+	}
+	if h == len(b.levels) {
+		b.levels = append(b.levels, nil)
+	}
+	b.levels[h] = &OrderedMap[K, V]{
+		left:  carry,
+		key:   key,
+		value: value,
+	}
+	b.len++
+}
+
+// maxKey returns the greatest key that has been set, which belongs to the pending element on the
+// lowest occupied level.
+func (b *OrderedMapStreamBuilder[K, V]) maxKey() (K, bool) {
+	for _, pending := range b.levels {
+		if pending != nil {
+			return pending.key, true
+		}
+	}
+	var zero K
+	return zero, false
+}
+
+// Len returns the number of elements that have been set.
+//
+// Complexity: O(1) worst-case
+func (b *OrderedMapStreamBuilder[K, V]) Len() int {
+	return b.len
+}
+
+// Build returns a map containing the elements that have been set. The builder isn't modified, so
+// more elements may be set afterwards and the map built again.
+//
+// Complexity: O(log² n) worst-case
+func (b *OrderedMapStreamBuilder[K, V]) Build() *OrderedMap[K, V] {
+	// Each pending element's subtrees are complete except for its right subtree, which consists of
+	// the elements on lower levels.
+	var ret *OrderedMap[K, V]
+	for _, pending := range b.levels {
+		if pending != nil {
+			ret = orderedMapJoin(pending.left, pending.key, pending.value, ret)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,71 @@
+package immutable
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedMapStreamBuilder(t *testing.T) {
+	var b OrderedMapStreamBuilder[int, int]
+	assert.Nil(t, b.Build())
+	assert.Equal(t, 0, b.Len())
+
+	var snapshots []*OrderedMap[int, int]
+	for i := 0; i < 300; i++ {
+		b.Set(i*2, i)
+		m := b.Build()
+		require.NoError(t, m.CheckInvariants())
+		require.Equal(t, i+1, m.Len())
+		require.Equal(t, i+1, b.Len())
+		snapshots = append(snapshots, m)
+	}
+
+	// Building doesn't modify the builder or previously built maps.
+	for i, m := range snapshots {
+		require.NoError(t, m.CheckInvariants())
+		j := 0
+		m.ForEach(func(k, _ int) bool {
+			require.Equal(t, j*2, k)
+			j++
+			return true
+		})
+		require.Equal(t, i+1, j)
+	}
+
+	// The working memory is logarithmic.
+	assert.LessOrEqual(t, len(b.levels), 10)
+
+	m := b.Build().Set(1, -1)
+	require.NoError(t, m.CheckInvariants())
+	assert.Equal(t, 301, m.Len())
+}
+
+func TestOrderedMapStreamBuilder_Order(t *testing.T) {
+	var b OrderedMapStreamBuilder[float64, int]
+	b.Set(1, 1)
+	assert.Panics(t, func() {
+		b.Set(1, 1)
+	})
+	assert.Panics(t, func() {
+		b.Set(0, 1)
+	})
+	assert.Panics(t, func() {
+		b.Set(math.NaN(), 1)
+	})
+	b.Set(2, 2)
+	assert.Equal(t, map[float64]int{1: 1, 2: 2}, b.Build().ToMap())
+}
+
+func BenchmarkOrderedMapStreamBuilder(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var builder OrderedMapStreamBuilder[int, string]
+		for j := 0; j < 1000; j++ {
+			builder.Set(j, "foo")
+		}
+		orderedMapResult = builder.Build()
+	}
+}
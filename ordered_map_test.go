@@ -2,23 +2,38 @@ package immutable
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/constraints"
 )
 
+func orderedMapKeys[K constraints.Ordered, V any](m *OrderedMap[K, V]) []K {
+	var ret []K
+	m.ForEach(func(key K, _ V) bool {
+		ret = append(ret, key)
+		return true
+	})
+	return ret
+}
+
 func TestOrderedMap(t *testing.T) {
 	var m *OrderedMap[string, string]
 	assert.True(t, m.Empty())
 	assert.Equal(t, 0, m.Len())
-	require.NoError(t, m.invariant())
+	require.NoError(t, m.CheckInvariants())
 
 	m = m.Set("foo", "bar")
 	assert.False(t, m.Empty())
 	assert.Equal(t, 1, m.Len())
-	require.NoError(t, m.invariant())
+	require.NoError(t, m.CheckInvariants())
 
 	v, ok := m.Get("foo")
 	assert.True(t, ok)
@@ -33,7 +48,7 @@ func TestOrderedMap(t *testing.T) {
 	m = m.Set("qux", "quux")
 	assert.False(t, m.Empty())
 	assert.Equal(t, 2, m.Len())
-	require.NoError(t, m.invariant())
+	require.NoError(t, m.CheckInvariants())
 
 	v, ok = m.Get("foo")
 	assert.True(t, ok)
@@ -51,10 +66,10 @@ func TestOrderedMap_Delete(t *testing.T) {
 	var m *OrderedMap[int, int]
 	for i := 0; i < 50; i++ {
 		m = m.Set(i, i)
-		require.NoError(t, m.invariant())
+		require.NoError(t, m.CheckInvariants())
 		for j := 0; j <= i; j++ {
 			m2 := m.Delete(j)
-			require.NoError(t, m2.invariant(), fmt.Sprintf("i=%v,j=%v", i, j))
+			require.NoError(t, m2.CheckInvariants(), fmt.Sprintf("i=%v,j=%v", i, j))
 			k := 0
 			for kv := m2.Min(); kv != nil; kv = kv.Next() {
 				if k == j {
@@ -68,6 +83,83 @@ func TestOrderedMap_Delete(t *testing.T) {
 	}
 }
 
+func TestOrderedMap_DeleteMissingConcurrently(t *testing.T) {
+	var m *OrderedMap[int, int]
+	for i := 0; i < 100; i++ {
+		m = m.Set(i, i)
+	}
+
+	// Deleting a key that doesn't exist mustn't modify the map, which other goroutines may be using.
+	// With the race detector enabled, this fails if it does.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.Same(t, m, m.Delete(-1))
+				assert.Same(t, m, m.Delete(100))
+			}
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, m.CheckInvariants())
+}
+
+func TestOrderedMap_Pop(t *testing.T) {
+	var m *OrderedMap[int, string]
+	v, ok, m2 := m.Pop(1)
+	assert.False(t, ok)
+	assert.Equal(t, "", v)
+	assert.Nil(t, m2)
+
+	m = m.Set(1, "a").Set(2, "b").Set(3, "c")
+	v, ok, m2 = m.Pop(2)
+	assert.True(t, ok)
+	assert.Equal(t, "b", v)
+	require.NoError(t, m2.CheckInvariants())
+	assert.Equal(t, map[int]string{1: "a", 3: "c"}, m2.ToMap())
+	assert.Equal(t, 3, m.Len())
+
+	_, ok, m3 := m2.Pop(2)
+	assert.False(t, ok)
+	assert.Equal(t, m2, m3)
+
+	_, _, m3 = m.Set(4, "d").Delete(4).Delete(1).Delete(3).Pop(2)
+	assert.Nil(t, m3)
+}
+
+func TestOrderedMap_NaN(t *testing.T) {
+	var m *OrderedMap[float64, int]
+	nan := math.NaN()
+	assert.Panics(t, func() { m.Set(nan, 1) })
+	assert.Panics(t, func() {
+		m.Update(nan, func(int, bool) (int, bool) { return 1, true })
+	})
+	assert.Panics(t, func() { OrderedMapFromMap(map[float64]int{nan: 1}) })
+	assert.Panics(t, func() {
+		var b OrderedMapBuilder[float64, int]
+		b.Set(nan, 1)
+	})
+	assert.Panics(t, func() { SortedListFromSlice([]float64{1, nan}) })
+
+	m = m.Set(math.Inf(-1), 1).Set(0, 2).Set(math.Inf(1), 3)
+	_, ok := m.Get(nan)
+	assert.False(t, ok)
+	assert.Equal(t, m, m.Delete(nan))
+	_, ok, m2 := m.Pop(nan)
+	assert.False(t, ok)
+	assert.Equal(t, m, m2)
+	assert.Equal(t, 0, m.CountRange(nan, 1))
+	assert.Equal(t, m, m.DeleteRange(0, nan))
+
+	l := SortedListFromSlice([]float64{1, 2})
+	assert.Equal(t, 0, l.Count(nan))
+	assert.Equal(t, l, l.DeleteAll(nan))
+	assert.Equal(t, l, l.DeleteOne(nan))
+	require.NoError(t, m.CheckInvariants())
+}
+
 func TestOrderedMap_MinAfter(t *testing.T) {
 	var m *OrderedMap[int, int]
 	for i := 0; i < 40; i += 2 {
@@ -102,6 +194,44 @@ func TestOrderedMap_MaxBefore(t *testing.T) {
 	}
 }
 
+func TestOrderedMap_MinAtLeast(t *testing.T) {
+	var m *OrderedMap[int, int]
+	for i := 0; i < 40; i += 2 {
+		m = m.Set(i, i)
+		assert.Nil(t, m.MinAtLeast(i+1))
+		for j := -1; j <= i; j++ {
+			kv := m.MinAtLeast(j)
+			require.NotNil(t, kv, fmt.Sprintf("i=%v,j=%v", i, j))
+			expected := j + (j+2)%2
+			assert.Equal(t, expected, kv.Key())
+			if expected+2 <= i {
+				assert.Equal(t, expected+2, kv.Next().Key())
+			}
+		}
+	}
+}
+
+func TestOrderedMap_MaxAtMost(t *testing.T) {
+	var m *OrderedMap[int, int]
+	for i := 0; i < 40; i += 2 {
+		m = m.Set(i, i)
+		assert.Nil(t, m.MaxAtMost(-1))
+		for j := 0; j <= i+1; j++ {
+			kv := m.MaxAtMost(j)
+			require.NotNil(t, kv, fmt.Sprintf("i=%v,j=%v", i, j))
+			expected := j - j%2
+			assert.Equal(t, expected, kv.Key())
+			if expected-2 >= 0 {
+				assert.Equal(t, expected-2, kv.Prev().Key())
+			}
+		}
+	}
+
+	f := (*OrderedMap[float64, int])(nil).Set(1, 1)
+	assert.Nil(t, f.MaxAtMost(math.NaN()))
+	assert.Nil(t, f.MinAtLeast(math.NaN()))
+}
+
 func TestOrderedMap_Iteration(t *testing.T) {
 	var m *OrderedMap[int, int]
 	assert.Nil(t, m.Min())
@@ -131,6 +261,781 @@ func TestOrderedMap_Iteration(t *testing.T) {
 	assert.Nil(t, e)
 }
 
+// raceEnabled is set when the race detector is enabled, which causes additional allocations.
+var raceEnabled bool
+
+func TestOrderedMap_SetAllocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations can't be counted accurately with the race detector enabled")
+	}
+
+	// Each node on the rebuilt path should be allocated exactly once, even when rebalancing, so
+	// inserting never allocates more than one node per level plus the new one.
+	var m *OrderedMap[int, int]
+	for i := 0; i < 2000; i++ {
+		key := rand.Int()
+		if i%2 == 0 {
+			key = i
+		}
+		var ret *OrderedMap[int, int]
+		allocs := testing.AllocsPerRun(1, func() {
+			ret = m.Set(key, i)
+		})
+		require.LessOrEqual(t, int(allocs), m.Stats(nil).Height+1)
+		m = ret
+	}
+	require.NoError(t, m.CheckInvariants())
+}
+
+func TestOrderedMap_SmallArrays(t *testing.T) {
+	// orderedMapIsArray returns true if the map's nodes are consecutive in memory.
+	orderedMapIsArray := func(m *OrderedMap[int, int]) bool {
+		for e := m.Min(); e.Next() != nil; e = e.Next() {
+			if uintptr(unsafe.Pointer(e.Next().element))-uintptr(unsafe.Pointer(e.element)) != unsafe.Sizeof(*m) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var m *OrderedMap[int, int]
+	var versions []*OrderedMap[int, int]
+	for _, k := range rand.Perm(orderedMapMaxArrayLen) {
+		m = m.Set(k, k)
+		require.NoError(t, m.CheckInvariants())
+		require.True(t, orderedMapIsArray(m))
+		versions = append(versions, m)
+	}
+	for i, v := range versions {
+		require.Equal(t, i+1, v.Len())
+	}
+
+	// Every kind of modification keeps small maps as arrays, and maps that shrink become arrays
+	// again.
+	m = m.Delete(3)
+	assert.True(t, orderedMapIsArray(m))
+	m, _ = m.SetIfAbsent(3, 3)
+	assert.True(t, orderedMapIsArray(m))
+	m, _ = m.Replace(3, 4)
+	assert.True(t, orderedMapIsArray(m))
+	_, _, m = m.Pop(3)
+	assert.True(t, orderedMapIsArray(m))
+	m = m.Update(3, func(int, bool) (int, bool) {
+		return 3, true
+	})
+	assert.True(t, orderedMapIsArray(m))
+	for i := 0; i < 100; i++ {
+		m = m.Set(orderedMapMaxArrayLen+i, i)
+	}
+	for i := 0; i < 100; i++ {
+		m = m.Delete(orderedMapMaxArrayLen + i)
+		require.NoError(t, m.CheckInvariants())
+	}
+	m = m.Delete(0)
+	assert.True(t, orderedMapIsArray(m))
+	assert.Equal(t, orderedMapMaxArrayLen-1, m.Len())
+	assert.Nil(t, OrderedMapOf(MakePair(1, 1)).Delete(1))
+
+	// Small maps built all at once are arrays too.
+	assert.True(t, orderedMapIsArray(OrderedMapFromMap(m.ToMap())))
+}
+
+func TestOrderedMap_SetIfChanged(t *testing.T) {
+	type big struct {
+		ID      int
+		Payload [64]byte
+	}
+	eq := func(a, b big) bool {
+		return a == b
+	}
+
+	var m *OrderedMap[int, big]
+	m = m.SetIfChanged(1, big{ID: 1}, eq)
+	assert.Equal(t, 1, m.Len())
+
+	assert.Same(t, m, m.SetIfChanged(1, big{ID: 1}, eq))
+
+	m2 := m.SetIfChanged(1, big{ID: 2}, eq)
+	assert.NotSame(t, m, m2)
+	v, _ := m2.Get(1)
+	assert.Equal(t, 2, v.ID)
+	v, _ = m.Get(1)
+	assert.Equal(t, 1, v.ID)
+
+	m3 := m.SetIfChanged(2, big{}, eq)
+	assert.Equal(t, 2, m3.Len())
+}
+
+func TestOrderedMap_SetIfAbsent(t *testing.T) {
+	var m *OrderedMap[int, string]
+	m, ok := m.SetIfAbsent(1, "a")
+	assert.True(t, ok)
+	assert.Equal(t, map[int]string{1: "a"}, m.ToMap())
+
+	m2, ok := m.SetIfAbsent(1, "b")
+	assert.False(t, ok)
+	assert.Same(t, m, m2)
+
+	for i := 0; i < 100; i++ {
+		m2, ok = m.SetIfAbsent(i, "c")
+		require.Equal(t, i != 1, ok)
+		m = m2
+		require.NoError(t, m.CheckInvariants())
+	}
+	assert.Equal(t, 100, m.Len())
+	v, _ := m.Get(1)
+	assert.Equal(t, "a", v)
+
+	assert.Panics(t, func() {
+		(*OrderedMap[float64, int])(nil).SetIfAbsent(math.NaN(), 1)
+	})
+}
+
+func TestOrderedMap_Replace(t *testing.T) {
+	var m *OrderedMap[int, string]
+	m2, ok := m.Replace(1, "a")
+	assert.False(t, ok)
+	assert.Nil(t, m2)
+
+	for i := 0; i < 100; i++ {
+		m = m.Set(i, "a")
+	}
+	m2, ok = m.Replace(100, "b")
+	assert.False(t, ok)
+	assert.Same(t, m, m2)
+
+	m2, ok = m.Replace(50, "b")
+	assert.True(t, ok)
+	require.NoError(t, m2.CheckInvariants())
+	assert.Equal(t, 100, m2.Len())
+	v, _ := m2.Get(50)
+	assert.Equal(t, "b", v)
+	v, _ = m.Get(50)
+	assert.Equal(t, "a", v)
+
+	_, ok = (*OrderedMap[float64, int])(nil).Set(1, 1).Replace(math.NaN(), 2)
+	assert.False(t, ok)
+}
+
+func TestOrderedMap_ForEach(t *testing.T) {
+	var m *OrderedMap[int, int]
+	m.ForEach(func(key, value int) bool {
+		t.Fatal("empty maps should have no elements")
+		return true
+	})
+
+	for i := 0; i < 1000; i++ {
+		m = m.Set(rand.Intn(2000), i)
+	}
+	var keys []int
+	m.ForEach(func(key, value int) bool {
+		v, _ := m.Get(key)
+		require.Equal(t, v, value)
+		keys = append(keys, key)
+		return true
+	})
+	var expected []int
+	for e := m.Min(); e != nil; e = e.Next() {
+		expected = append(expected, e.Key())
+	}
+	assert.Equal(t, expected, keys)
+
+	count := 0
+	m.ForEach(func(key, value int) bool {
+		count++
+		return count < 10
+	})
+	assert.Equal(t, 10, count)
+}
+
+func TestOrderedMap_Meta(t *testing.T) {
+	var m OrderedMap[int, int]
+	for _, n := range []int{0, 1, 1000, math.MaxInt >> 2} {
+		for _, color := range []int{orderedMapNegativeBlack, orderedMapRed, orderedMapBlack, orderedMapDoubleBlack} {
+			m.meta = orderedMapMeta(n, color)
+			require.Equal(t, n, m.size())
+			require.Equal(t, color, m.color())
+			m.setLen(5)
+			require.Equal(t, color, m.color())
+			m.setColor(orderedMapBlack)
+			require.Equal(t, 5, m.size())
+		}
+	}
+
+	// The length and color share a word, so a node is just its children, key, value, and one int.
+	assert.Equal(t, 5*unsafe.Sizeof(0), unsafe.Sizeof(m))
+}
+
+func TestOrderedMap_MinKey(t *testing.T) {
+	var m *OrderedMap[int, int]
+	_, ok := m.MinKey()
+	assert.False(t, ok)
+	_, ok = m.MaxKey()
+	assert.False(t, ok)
+
+	for i := 0; i < 200; i++ {
+		m = m.Set(rand.Intn(1000), i)
+		min, ok := m.MinKey()
+		require.True(t, ok)
+		require.Equal(t, m.Min().Key(), min)
+		max, ok := m.MaxKey()
+		require.True(t, ok)
+		require.Equal(t, m.Max().Key(), max)
+	}
+
+	assert.Equal(t, 0.0, testing.AllocsPerRun(100, func() {
+		m.MinKey()
+		m.MaxKey()
+	}))
+}
+
+func TestOrderedMap_Compare(t *testing.T) {
+	cmpV := func(a, b string) int {
+		return strings.Compare(a, b)
+	}
+	var empty *OrderedMap[int, string]
+	ab := OrderedMapOf(MakePair(1, "a"), MakePair(2, "b"))
+	assert.Equal(t, 0, empty.Compare(nil, cmpV))
+	assert.Equal(t, 0, empty.Compare(&OrderedMap[int, string]{}, cmpV))
+	assert.Equal(t, -1, empty.Compare(ab, cmpV))
+	assert.Equal(t, 1, ab.Compare(empty, cmpV))
+	assert.Equal(t, 0, ab.Compare(ab, cmpV))
+	assert.Equal(t, 0, ab.Compare(OrderedMapOf(MakePair(2, "b"), MakePair(1, "a")), cmpV))
+
+	// Keys are compared before values.
+	assert.Equal(t, -1, ab.Compare(OrderedMapOf(MakePair(1, "a"), MakePair(3, "a")), cmpV))
+	assert.Equal(t, 1, ab.Compare(OrderedMapOf(MakePair(0, "z")), cmpV))
+	assert.Equal(t, -1, ab.Compare(ab.Set(2, "c"), cmpV))
+	assert.Equal(t, 1, ab.Set(1, "b").Compare(ab, cmpV))
+
+	// Prefixes are ordered first.
+	assert.Equal(t, -1, ab.Compare(ab.Set(3, "a"), cmpV))
+	assert.Equal(t, 1, ab.Set(3, "a").Compare(ab, cmpV))
+
+	// cmpV's result is normalized.
+	assert.Equal(t, -1, ab.Compare(ab.Set(2, "c"), func(a, b string) int {
+		return -100 * strings.Compare(b, a)
+	}))
+}
+
+func TestOrderedMap_Height(t *testing.T) {
+	var m *OrderedMap[int, int]
+	assert.Equal(t, 0, m.Height())
+	_, ok := m.Depth(1)
+	assert.False(t, ok)
+
+	for i := 0; i < 1000; i++ {
+		m = m.Set(i, i)
+	}
+	assert.Equal(t, m.Stats(nil).Height, m.Height())
+	assert.LessOrEqual(t, float64(m.Height()), 2*math.Log2(1001))
+
+	maxDepth := 0
+	for i := 0; i < 1000; i++ {
+		depth, ok := m.Depth(i)
+		require.True(t, ok)
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+	}
+	assert.Equal(t, m.Height()-1, maxDepth)
+
+	depth, ok := m.Depth(m.key)
+	assert.True(t, ok)
+	assert.Equal(t, 0, depth)
+	_, ok = m.Depth(1000)
+	assert.False(t, ok)
+	_, ok = (*OrderedMap[float64, int])(nil).Set(1, 1).Depth(math.NaN())
+	assert.False(t, ok)
+}
+
+func TestOrderedMap_Contains(t *testing.T) {
+	var m *OrderedMap[float64, int]
+	assert.False(t, m.Contains(1))
+	for i := 0; i < 100; i++ {
+		m = m.Set(float64(i*2), i)
+	}
+	for i := -1; i <= 200; i++ {
+		_, ok := m.Get(float64(i))
+		require.Equal(t, ok, m.Contains(float64(i)))
+	}
+	assert.False(t, m.Contains(math.NaN()))
+}
+
+func TestOrderedMapOf(t *testing.T) {
+	assert.Nil(t, OrderedMapOf[int, string]())
+
+	m := OrderedMapOf(
+		Pair[string, int]{"b", 2},
+		Pair[string, int]{"a", 1},
+		Pair[string, int]{"b", 3},
+	)
+	require.NoError(t, m.CheckInvariants())
+	assert.Equal(t, map[string]int{"a": 1, "b": 3}, m.ToMap())
+
+	assert.Panics(t, func() { OrderedMapOf(Pair[float64, int]{math.NaN(), 1}) })
+}
+
+func TestOrderedMapFromMap(t *testing.T) {
+	for n := 0; n < 100; n++ {
+		ref := make(map[int]string)
+		for i := 0; i < n; i++ {
+			ref[i*2] = fmt.Sprint(i)
+		}
+		m := OrderedMapFromMap(ref)
+		require.NoError(t, m.CheckInvariants(), fmt.Sprintf("n=%v", n))
+		assert.Equal(t, n, m.Len())
+		assert.Equal(t, ref, m.ToMap())
+	}
+}
+
+func TestOrderedMap_Stats(t *testing.T) {
+	var m *OrderedMap[int, int]
+	stats := m.Stats(nil)
+	assert.Equal(t, OrderedMapStats{}, stats)
+
+	for i := 0; i < 1000; i++ {
+		m = m.Set(i, i)
+	}
+	stats = m.Stats(nil)
+	assert.Equal(t, 1000, stats.Nodes)
+	assert.Equal(t, 0, stats.SharedNodes)
+	assert.GreaterOrEqual(t, stats.Height, 10)
+	assert.LessOrEqual(t, stats.Height, 20)
+	assert.Equal(t, stats.EstimatedBytes, stats.EstimatedUniqueBytes)
+	assert.True(t, stats.EstimatedBytes > 0)
+
+	stats = m.Stats(m)
+	assert.Equal(t, 1000, stats.SharedNodes)
+	assert.Equal(t, uintptr(0), stats.EstimatedUniqueBytes)
+
+	m2 := m.Set(500, 0)
+	stats = m2.Stats(m)
+	assert.Equal(t, 1000, stats.Nodes)
+	assert.Less(t, stats.SharedNodes, 1000)
+	assert.GreaterOrEqual(t, stats.SharedNodes, 1000-stats.Height)
+}
+
+func TestOrderedMap_CountRange(t *testing.T) {
+	var m *OrderedMap[int, int]
+	assert.Equal(t, 0, m.CountRange(0, 10))
+
+	for i := 0; i < 100; i += 2 {
+		m = m.Set(i, i)
+	}
+	for lo := -2; lo < 102; lo++ {
+		for hi := -2; hi < 102; hi++ {
+			expected := 0
+			for i := 0; i < 100; i += 2 {
+				if i >= lo && i < hi {
+					expected++
+				}
+			}
+			assert.Equal(t, expected, m.CountRange(lo, hi), fmt.Sprintf("lo=%v,hi=%v", lo, hi))
+		}
+	}
+}
+
+func TestOrderedMap_DeleteRange(t *testing.T) {
+	var m *OrderedMap[int, int]
+	assert.Nil(t, m.DeleteRange(0, 10))
+
+	for n := 0; n < 50; n++ {
+		m = m.Set(n*2, n*2)
+		for lo := -1; lo <= n*2+1; lo++ {
+			for hi := lo - 1; hi <= n*2+2; hi++ {
+				m2 := m.DeleteRange(lo, hi)
+				require.NoError(t, m2.CheckInvariants(), fmt.Sprintf("n=%v,lo=%v,hi=%v", n, lo, hi))
+				var expected, actual []int
+				for i := 0; i <= n*2; i += 2 {
+					if i < lo || i >= hi {
+						expected = append(expected, i)
+					}
+				}
+				for e := m2.Min(); e != nil; e = e.Next() {
+					actual = append(actual, e.Key())
+				}
+				require.Equal(t, expected, actual, fmt.Sprintf("n=%v,lo=%v,hi=%v", n, lo, hi))
+				require.Equal(t, len(expected), m2.Len())
+			}
+		}
+	}
+}
+
+func TestOrderedMap_Compact(t *testing.T) {
+	var m *OrderedMap[int, int]
+	assert.Nil(t, m.Compact())
+
+	for i := 0; i < 1000; i++ {
+		m = m.Set(rand.Intn(500), i)
+	}
+	c := m.Compact()
+	require.NoError(t, c.CheckInvariants())
+	assert.Equal(t, m.ToMap(), c.ToMap())
+	assert.Equal(t, 0, c.Stats(m).SharedNodes)
+
+	c2 := c.Set(1000, 1)
+	assert.Equal(t, m.Len()+1, c2.Len())
+	assert.NoError(t, c2.CheckInvariants())
+}
+
+func TestOrderedMap_DeleteIf(t *testing.T) {
+	var m *OrderedMap[int, int]
+	assert.Nil(t, m.DeleteIf(func(int, int) bool { return true }))
+
+	for n := 0; n < 200; n++ {
+		m = m.Set(rand.Intn(300), n)
+		for _, mod := range []int{1, 2, 3, 7, 1000} {
+			var keys []int
+			m2 := m.DeleteIf(func(key, value int) bool {
+				keys = append(keys, key)
+				return key%mod == 0
+			})
+			require.NoError(t, m2.CheckInvariants())
+			require.True(t, sort.IntsAreSorted(keys))
+			require.Len(t, keys, m.Len())
+			expected := map[int]int{}
+			m.ForEach(func(key, value int) bool {
+				if key%mod != 0 {
+					expected[key] = value
+				}
+				return true
+			})
+			require.Equal(t, expected, m2.ToMap())
+			if len(expected) == m.Len() {
+				require.Same(t, m, m2)
+			}
+		}
+	}
+
+	// Removing a single element should share almost all of the tree.
+	m2 := m.DeleteIf(func(key, value int) bool {
+		return key == m.Min().Key()
+	})
+	assert.Equal(t, m.Len()-1, m2.Len())
+	assert.Greater(t, m2.Stats(m).SharedNodes, m2.Len()/2)
+}
+
+func TestOrderedMap_Diff(t *testing.T) {
+	type change struct {
+		key       int
+		old       int
+		oldExists bool
+		new       int
+		newExists bool
+	}
+	eq := func(a, b int) bool {
+		return a == b
+	}
+
+	var m *OrderedMap[int, int]
+	for i := 0; i < 1000; i++ {
+		m = m.Set(i, i)
+	}
+
+	for i := 0; i < 100; i++ {
+		other := m
+		ref := map[int]change{}
+		for j := 0; j < 10; j++ {
+			k := rand.Intn(1100)
+			old, oldExists := m.Get(k)
+			if rand.Intn(2) == 0 {
+				other = other.Delete(k)
+				ref[k] = change{k, old, oldExists, 0, false}
+			} else {
+				v := rand.Intn(1000)
+				other = other.Set(k, v)
+				ref[k] = change{k, old, oldExists, v, true}
+			}
+		}
+		for k, c := range ref {
+			if c.oldExists == c.newExists && c.old == c.new {
+				delete(ref, k)
+			}
+		}
+
+		var changes []change
+		m.Diff(other, eq, func(key int, old int, oldExists bool, new int, newExists bool) {
+			changes = append(changes, change{key, old, oldExists, new, newExists})
+		})
+		require.Len(t, changes, len(ref))
+		for i, c := range changes {
+			require.Equal(t, ref[c.key], c)
+			if i > 0 {
+				require.Less(t, changes[i-1].key, c.key)
+			}
+		}
+
+		m.Diff(other, nil, func(key int, old int, oldExists bool, new int, newExists bool) {
+			require.NotEqual(t, oldExists, newExists)
+		})
+	}
+
+	m.Diff(m, eq, func(key int, old int, oldExists bool, new int, newExists bool) {
+		t.Fatal("identical maps should have no differences")
+	})
+
+	count := 0
+	m.Diff(nil, eq, func(key int, old int, oldExists bool, new int, newExists bool) {
+		require.True(t, oldExists)
+		require.False(t, newExists)
+		count++
+	})
+	assert.Equal(t, 1000, count)
+}
+
+func TestOrderedMap_TakeFirst(t *testing.T) {
+	var m *OrderedMap[int, int]
+	assert.Nil(t, m.TakeFirst(1))
+	for i := 0; i < 100; i++ {
+		m = m.Set(i, i)
+	}
+	assert.Equal(t, m, m.TakeFirst(100))
+	assert.Nil(t, m.TakeFirst(0))
+	assert.Nil(t, m.TakeFirst(-1))
+	for _, n := range []int{1, 10, 50, 99} {
+		first := m.TakeFirst(n)
+		require.NoError(t, first.CheckInvariants())
+		assert.Equal(t, n, first.Len())
+		assert.Equal(t, 0, first.Min().Key())
+		assert.Equal(t, n-1, first.Max().Key())
+	}
+}
+
+func TestOrderedMap_TakeLast(t *testing.T) {
+	var m *OrderedMap[int, int]
+	assert.Nil(t, m.TakeLast(1))
+	for i := 0; i < 100; i++ {
+		m = m.Set(i, i)
+	}
+	assert.Equal(t, m, m.TakeLast(100))
+	assert.Nil(t, m.TakeLast(0))
+	for _, n := range []int{1, 10, 50, 99} {
+		last := m.TakeLast(n)
+		require.NoError(t, last.CheckInvariants())
+		assert.Equal(t, n, last.Len())
+		assert.Equal(t, 100-n, last.Min().Key())
+		assert.Equal(t, 99, last.Max().Key())
+	}
+}
+
+func TestOrderedMap_Page(t *testing.T) {
+	var m *OrderedMap[int, string]
+	page, cursor := m.Page(nil, 10)
+	assert.Empty(t, page)
+	assert.Nil(t, cursor)
+	assert.Panics(t, func() {
+		m.Page(nil, 0)
+	})
+
+	for n := 1; n <= 20; n++ {
+		m = m.Set(n*2, fmt.Sprint(n))
+		for _, limit := range []int{1, 3, n, n + 1} {
+			var keys []int
+			var cursor *int
+			for pages := 0; ; pages++ {
+				require.Less(t, pages, n+1)
+				page, next := m.Page(cursor, limit)
+				require.NotEmpty(t, page)
+				require.LessOrEqual(t, len(page), limit)
+				for _, p := range page {
+					v, _ := m.Get(p.Key)
+					require.Equal(t, v, p.Value)
+					keys = append(keys, p.Key)
+				}
+				if next == nil {
+					break
+				}
+				require.Len(t, page, limit)
+				require.Equal(t, page[len(page)-1].Key, *next)
+				cursor = next
+			}
+			require.Equal(t, orderedMapKeys(m), keys)
+		}
+	}
+
+	// Cursors don't need to be keys in the map.
+	after := 5
+	page, cursor = m.Page(&after, 2)
+	assert.Equal(t, []Pair[int, string]{{6, "3"}, {8, "4"}}, page)
+	assert.Equal(t, 8, *cursor)
+	after = 40
+	page, cursor = m.Page(&after, 2)
+	assert.Empty(t, page)
+	assert.Nil(t, cursor)
+}
+
+func TestOrderedMap_SplitAt(t *testing.T) {
+	left, right := (*OrderedMap[int, int])(nil).SplitAt(3)
+	assert.Nil(t, left)
+	assert.Nil(t, right)
+
+	for _, size := range []int{1, 2, 5, 100} {
+		var m *OrderedMap[int, int]
+		for i := 0; i < size; i++ {
+			m = m.Set(i*2, i)
+		}
+		for i := -1; i <= size+1; i++ {
+			left, right := m.SplitAt(i)
+			require.NoError(t, left.CheckInvariants())
+			require.NoError(t, right.CheckInvariants())
+			expected := i
+			if expected < 0 {
+				expected = 0
+			} else if expected > size {
+				expected = size
+			}
+			require.Equal(t, expected, left.Len())
+			require.Equal(t, size-expected, right.Len())
+			if !left.Empty() {
+				require.Equal(t, 0, left.Min().Key())
+				require.Equal(t, (expected-1)*2, left.Max().Key())
+			}
+			if !right.Empty() {
+				require.Equal(t, expected*2, right.Min().Key())
+			}
+		}
+	}
+}
+
+func TestOrderedMap_Split(t *testing.T) {
+	var m *OrderedMap[float64, int]
+	for i := 0; i < 100; i++ {
+		m = m.Set(float64(i*2), i)
+	}
+	for key := -1; key <= 201; key++ {
+		left, right := m.Split(float64(key))
+		require.NoError(t, left.CheckInvariants())
+		require.NoError(t, right.CheckInvariants())
+		require.Equal(t, m.Len(), left.Len()+right.Len())
+		if !left.Empty() {
+			require.Less(t, left.Max().Key(), float64(key))
+		}
+		if !right.Empty() {
+			require.GreaterOrEqual(t, right.Min().Key(), float64(key))
+		}
+	}
+	assert.Panics(t, func() {
+		m.Split(math.NaN())
+	})
+}
+
+func TestOrderedMap_SplitN(t *testing.T) {
+	assert.Panics(t, func() {
+		(*OrderedMap[int, int])(nil).SplitN(0)
+	})
+
+	for _, size := range []int{0, 1, 5, 100, 1000} {
+		var m *OrderedMap[int, int]
+		for i := 0; i < size; i++ {
+			m = m.Set(i, i)
+		}
+		for _, n := range []int{1, 2, 3, 7, 10} {
+			parts := m.SplitN(n)
+			require.Len(t, parts, n)
+			next := 0
+			for _, part := range parts {
+				require.NoError(t, part.CheckInvariants())
+				require.InDelta(t, float64(size)/float64(n), part.Len(), 1)
+				for e := part.Min(); e != nil; e = e.Next() {
+					require.Equal(t, next, e.Key())
+					next++
+				}
+			}
+			assert.Equal(t, size, next)
+		}
+	}
+}
+
+func TestOrderedMap_Join(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		var left, right *OrderedMap[int, int]
+		leftLen, rightLen := rand.Intn(200), rand.Intn(200)
+		for j := 0; j < leftLen; j++ {
+			left = left.Set(rand.Intn(1000), 0)
+		}
+		for j := 0; j < rightLen; j++ {
+			right = right.Set(1001+rand.Intn(1000), 0)
+		}
+		m := orderedMapJoin(left, 1000, 0, right)
+		require.NoError(t, m.CheckInvariants())
+		require.Equal(t, left.Len()+right.Len()+1, m.Len())
+		m = orderedMapJoin2(left, right)
+		require.NoError(t, m.CheckInvariants())
+		require.Equal(t, left.Len()+right.Len(), m.Len())
+	}
+}
+
+func TestOrderedMapElement_SetValue(t *testing.T) {
+	var m *OrderedMap[int, int]
+	for i := 0; i < 100; i++ {
+		m = m.Set(i, i)
+	}
+	for e := m.Min(); e != nil; e = e.Next() {
+		m2 := e.SetValue(-e.Key())
+		require.NoError(t, m2.CheckInvariants())
+		assert.Equal(t, m.Len(), m2.Len())
+		for e2 := m2.Min(); e2 != nil; e2 = e2.Next() {
+			if e2.Key() == e.Key() {
+				assert.Equal(t, -e.Key(), e2.Value())
+			} else {
+				assert.Equal(t, e2.Key(), e2.Value())
+			}
+		}
+	}
+
+	// The original map should be unchanged, and elements should be usable as cursors.
+	for e := m.Min(); e != nil; e = e.Next() {
+		assert.Equal(t, e.Key(), e.Value())
+	}
+	m2 := m
+	for e := m.Min(); e != nil; e = e.Next() {
+		m2 = m2.MinAfter(e.Key() - 1).SetValue(0)
+	}
+	for e := m2.Min(); e != nil; e = e.Next() {
+		assert.Equal(t, 0, e.Value())
+	}
+}
+
+func TestOrderedMap_Update(t *testing.T) {
+	var m *OrderedMap[string, int]
+	increment := func(old int, exists bool) (int, bool) {
+		return old + 1, true
+	}
+	m = m.Update("a", increment).Update("a", increment).Update("b", increment)
+	require.NoError(t, m.CheckInvariants())
+	assert.Equal(t, map[string]int{"a": 2, "b": 1}, m.ToMap())
+
+	remove := func(old int, exists bool) (int, bool) {
+		return 0, false
+	}
+	assert.Same(t, m, m.Update("c", remove))
+	assert.Equal(t, map[string]int{"b": 1}, m.Update("a", remove).ToMap())
+	assert.Nil(t, m.Update("a", remove).Update("b", remove))
+
+	ref := make(map[int]int)
+	var m2 *OrderedMap[int, int]
+	for i := 0; i < 100000; i++ {
+		k := rand.Intn(500)
+		m2 = m2.Update(k, func(old int, exists bool) (int, bool) {
+			refOld, refExists := ref[k]
+			require.Equal(t, refExists, exists)
+			require.Equal(t, refOld, old)
+			if rand.Intn(3) == 0 {
+				delete(ref, k)
+				return 0, false
+			}
+			ref[k] = i
+			return i, true
+		})
+		require.Equal(t, len(ref), m2.Len())
+		if i%100 == 0 {
+			require.NoError(t, m2.CheckInvariants())
+		}
+	}
+	assert.Equal(t, ref, m2.ToMap())
+}
+
 func TestOrderedMap_Fuzz(t *testing.T) {
 	ref := make(map[int]int)
 	var m *OrderedMap[int, int]
@@ -141,13 +1046,13 @@ func TestOrderedMap_Fuzz(t *testing.T) {
 			delete(ref, k)
 			m = m.Delete(k)
 			assert.Equal(t, len(ref), m.Len(), "after delete")
-			require.NoError(t, m.invariant(), "after delete")
+			require.NoError(t, m.CheckInvariants(), "after delete")
 		} else {
 			v := rand.Int()
 			ref[k] = v
 			m = m.Set(k, v)
 			assert.Equal(t, len(ref), m.Len(), "after set")
-			require.NoError(t, m.invariant(), "after set")
+			require.NoError(t, m.CheckInvariants(), "after set")
 		}
 	}
 	for k, refv := range ref {
@@ -160,7 +1065,7 @@ func TestOrderedMap_Fuzz(t *testing.T) {
 var orderedMapValueResult interface{}
 
 func BenchmarkOrderedMap_Get(b *testing.B) {
-	for _, n := range []int{100, 10000, 1000000} {
+	for _, n := range []int{10, 100, 10000, 1000000} {
 		m := &OrderedMap[int, string]{}
 		for i := 0; i < n; i++ {
 			m = m.Set(i, "foo")
@@ -174,9 +1079,9 @@ func BenchmarkOrderedMap_Get(b *testing.B) {
 	}
 }
 
-var orderedMapResult *OrderedMap[int, string]
+var orderedMapContainsResult bool
 
-func BenchmarkOrderedMap_Set(b *testing.B) {
+func BenchmarkOrderedMap_Contains(b *testing.B) {
 	for _, n := range []int{100, 10000, 1000000} {
 		m := &OrderedMap[int, string]{}
 		for i := 0; i < n; i++ {
@@ -184,58 +1089,70 @@ func BenchmarkOrderedMap_Set(b *testing.B) {
 		}
 		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
-				orderedMapResult = m.Set(i%n, "bar")
+				orderedMapContainsResult = m.Contains(i % n)
 			}
 		})
 	}
 }
 
-func (m *OrderedMap[K, V]) invariant() error {
-	_, err := m.invariantInfo()
-	return err
-}
-
-type orderedMapInvariantInfo struct {
-	BlackDepth int
-}
-
-func (m *OrderedMap[K, V]) invariantInfo() (*orderedMapInvariantInfo, error) {
-	if m == nil {
-		return &orderedMapInvariantInfo{
-			BlackDepth: 0,
-		}, nil
-	}
+var orderedMapResult *OrderedMap[int, string]
 
-	if m.color == orderedMapDoubleBlack && m.len == 0 {
-		return nil, fmt.Errorf("double black leaf")
-	}
-	if m.color != orderedMapRed && m.color != orderedMapBlack {
-		return nil, fmt.Errorf("invalid node color: %v", m.color)
-	}
-	if m.color == orderedMapRed && ((m.left != nil && m.left.color == orderedMapRed) || (m.right != nil && m.right.color == orderedMapRed)) {
-		return nil, fmt.Errorf("red node has red child")
+func BenchmarkOrderedMap_Set(b *testing.B) {
+	for _, n := range []int{10, 100, 10000, 1000000} {
+		m := &OrderedMap[int, string]{}
+		for i := 0; i < n; i++ {
+			m = m.Set(i, "foo")
+		}
+		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				orderedMapResult = m.Set(i%n, "bar")
+			}
+		})
 	}
+}
 
-	left, err := m.left.invariantInfo()
-	if err != nil {
-		return nil, err
+func BenchmarkOrderedMap_Insert(b *testing.B) {
+	for _, n := range []int{100, 10000, 1000000} {
+		m := &OrderedMap[int, string]{}
+		for i := 0; i < n; i++ {
+			m = m.Set(i*2, "foo")
+		}
+		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				orderedMapResult = m.Set((i%n)*2+1, "bar")
+			}
+		})
 	}
+}
 
-	right, err := m.right.invariantInfo()
-	if err != nil {
-		return nil, err
+func BenchmarkOrderedMap_SetAscending(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var m *OrderedMap[int, string]
+		for j := 0; j < 1000; j++ {
+			m = m.Set(j, "foo")
+		}
+		orderedMapResult = m
 	}
+}
 
-	if left.BlackDepth != right.BlackDepth {
-		return nil, fmt.Errorf("unbalanced black depths")
-	}
+var orderedMapKeyResult int
 
-	info := &orderedMapInvariantInfo{
-		BlackDepth: left.BlackDepth,
-	}
-	if m.color == orderedMapBlack {
-		info.BlackDepth++
+func BenchmarkOrderedMap_ForEach(b *testing.B) {
+	for _, n := range []int{100, 10000, 1000000} {
+		m := &OrderedMap[int, string]{}
+		for i := 0; i < n; i++ {
+			m = m.Set(i, "foo")
+		}
+		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m.ForEach(func(key int, value string) bool {
+					orderedMapKeyResult = key
+					return true
+				})
+			}
+		})
 	}
-
-	return info, nil
 }
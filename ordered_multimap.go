@@ -0,0 +1,138 @@
+package immutable
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+// OrderedMultimap implements an ordered map which can associate multiple values with each key.
+// Iteration yields elements in ascending order of keys, and the values of each key in the order they
+// were added. Like OrderedMap, it doesn't support NaN keys.
+//
+// Nil and the zero value for OrderedMultimap are both empty maps.
+type OrderedMultimap[K constraints.Ordered, V any] struct {
+	// values contains the values of each key with at least one value.
+	values *OrderedMap[K, *Vector[V]]
+
+	len int
+}
+
+// Empty returns true if the map is empty.
+//
+// Complexity: O(1) worst-case
+func (m *OrderedMultimap[K, V]) Empty() bool {
+	return m.Len() == 0
+}
+
+// Len returns the number of values in the map, across all keys.
+//
+// Complexity: O(1) worst-case
+func (m *OrderedMultimap[K, V]) Len() int {
+	if m == nil {
+		return 0
+	}
+	return m.len
+}
+
+// Keys returns the number of keys which have at least one value in the map.
+//
+// Complexity: O(1) worst-case
+func (m *OrderedMultimap[K, V]) Keys() int {
+	if m == nil {
+		return 0
+	}
+	return m.values.Len()
+}
+
+// Get returns the values associated with the given key, in the order they were added. If the key
+// has no values, the result is empty.
+//
+// Complexity: O(log k) worst-case, where k is the number of keys
+func (m *OrderedMultimap[K, V]) Get(key K) *Vector[V] {
+	if m == nil {
+		return nil
+	}
+	ret, _ := m.values.Get(key)
+	return ret
+}
+
+// Set adds a value for the given key after any values it already has. Unlike OrderedMap.Set, it
+// doesn't replace existing values. It panics if the key is NaN.
+//
+// Complexity: O(log k + log v) worst-case, where k is the number of keys and v is the number of
+// values for the key
+func (m *OrderedMultimap[K, V]) Set(key K, value V) *OrderedMultimap[K, V] {
+	var ret OrderedMultimap[K, V]
+	if m != nil {
+		ret = *m
+	}
+	ret.values = ret.values.Update(key, func(values *Vector[V], _ bool) (*Vector[V], bool) {
+		return values.Append(value), true
+	})
+	ret.len++
+	return &ret
+}
+
+// Delete removes all values for the given key.
+//
+// Complexity: O(log k) worst-case, where k is the number of keys
+func (m *OrderedMultimap[K, V]) Delete(key K) *OrderedMultimap[K, V] {
+	values := m.Get(key)
+	if values.Empty() {
+		return m
+	}
+	return &OrderedMultimap[K, V]{
+		values: m.values.Delete(key),
+		len:    m.len - values.Len(),
+	}
+}
+
+// ForEach calls f for each key and value in ascending order of keys, and for the values of each key
+// in the order they were added, stopping early if f returns false.
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMultimap[K, V]) ForEach(f func(key K, value V) bool) {
+	if m == nil {
+		return
+	}
+	m.values.ForEach(func(key K, values *Vector[V]) bool {
+		ok := true
+		values.ForEach(func(value V) bool {
+			ok = f(key, value)
+			return ok
+		})
+		return ok
+	})
+}
+
+// CheckInvariants verifies the structural integrity of the map, returning an error describing the
+// first problem found. It checks that the keys are in ascending order, that no key has an empty
+// list of values, and that the recorded length is correct.
+//
+// Complexity: O(n) worst-case
+func (m *OrderedMultimap[K, V]) CheckInvariants() error {
+	if m == nil {
+		return nil
+	} else if err := m.values.CheckInvariants(); err != nil {
+		return err
+	}
+	n := 0
+	var err error
+	m.values.ForEach(func(key K, values *Vector[V]) bool {
+		if err = values.CheckInvariants(); err != nil {
+			return false
+		} else if values.Empty() {
+			err = fmt.Errorf("key %v has no values", key)
+			return false
+		}
+		n += values.Len()
+		return true
+	})
+	if err != nil {
+		return err
+	} else if n != m.len {
+		return fmt.Errorf("map has %v values, but %v were recorded", n, m.len)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package immutable
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedMultimap(t *testing.T) {
+	var m *OrderedMultimap[string, int]
+	assert.True(t, m.Empty())
+	assert.Equal(t, 0, m.Len())
+	assert.Equal(t, 0, m.Keys())
+	assert.True(t, m.Get("a").Empty())
+	assert.Nil(t, m.Delete("a"))
+	m.ForEach(func(string, int) bool {
+		t.Fatal("empty maps should have no elements")
+		return true
+	})
+	assert.Equal(t, 1, (&OrderedMultimap[string, int]{}).Set("a", 1).Len())
+
+	m = m.Set("b", 1).Set("a", 2).Set("b", 3).Set("a", 4).Set("c", 5)
+	assert.False(t, m.Empty())
+	assert.Equal(t, 5, m.Len())
+	assert.Equal(t, 3, m.Keys())
+	assert.Equal(t, []int{2, 4}, m.Get("a").ToSlice())
+	assert.Equal(t, []int{1, 3}, m.Get("b").ToSlice())
+
+	var pairs []Pair[string, int]
+	m.ForEach(func(k string, v int) bool {
+		pairs = append(pairs, MakePair(k, v))
+		return true
+	})
+	assert.Equal(t, []Pair[string, int]{
+		{"a", 2}, {"a", 4}, {"b", 1}, {"b", 3}, {"c", 5},
+	}, pairs)
+
+	n := 0
+	m.ForEach(func(key string, value int) bool {
+		n++
+		return n < 3
+	})
+	assert.Equal(t, 3, n)
+
+	m2 := m.Delete("b")
+	assert.Equal(t, 3, m2.Len())
+	assert.Equal(t, 2, m2.Keys())
+	assert.True(t, m2.Get("b").Empty())
+	assert.Same(t, m2, m2.Delete("b"))
+	assert.Equal(t, 5, m.Len())
+
+	assert.Panics(t, func() {
+		(*OrderedMultimap[float64, int])(nil).Set(math.NaN(), 1)
+	})
+}
+
+func TestOrderedMultimap_Random(t *testing.T) {
+	var m *OrderedMultimap[int, int]
+	ref := map[int][]int{}
+	total := 0
+	for i := 0; i < 2000; i++ {
+		k := rand.Intn(20)
+		if rand.Intn(10) == 0 {
+			m = m.Delete(k)
+			total -= len(ref[k])
+			delete(ref, k)
+		} else {
+			m = m.Set(k, i)
+			ref[k] = append(ref[k], i)
+			total++
+		}
+		require.NoError(t, m.CheckInvariants())
+		require.Equal(t, total, m.Len())
+		require.Equal(t, len(ref), m.Keys())
+		require.Equal(t, ref[k], m.Get(k).ToSlice())
+	}
+}
@@ -0,0 +1,130 @@
+package immutable
+
+import "golang.org/x/exp/constraints"
+
+// OrderedSubMap is a read-only view of the elements of an OrderedMap with keys greater than or equal
+// to a lower bound and less than an upper bound. It shares the underlying map's tree, so creating
+// one doesn't copy anything.
+//
+// Nil and the zero value for OrderedSubMap are both empty views.
+type OrderedSubMap[K constraints.Ordered, V any] struct {
+	m      *OrderedMap[K, V]
+	lo, hi K
+}
+
+// SubMap returns a view of the elements with keys greater than or equal to lo and less than hi. If
+// either bound is NaN, the view is empty.
+//
+// Complexity: O(1) worst-case
+func (m *OrderedMap[K, V]) SubMap(lo, hi K) *OrderedSubMap[K, V] {
+	if lo != lo || hi != hi || !(lo < hi) {
+		m = nil
+	}
+	return &OrderedSubMap[K, V]{
+		m:  m,
+		lo: lo,
+		hi: hi,
+	}
+}
+
+func (s *OrderedSubMap[K, V]) inRange(key K) bool {
+	return !(key < s.lo) && key < s.hi
+}
+
+// SubMap returns a view of the elements of this view with keys greater than or equal to lo and less
+// than hi.
+//
+// Complexity: O(1) worst-case
+func (s *OrderedSubMap[K, V]) SubMap(lo, hi K) *OrderedSubMap[K, V] {
+	if s == nil {
+		return nil
+	}
+	if lo < s.lo {
+		lo = s.lo
+	}
+	if s.hi < hi {
+		hi = s.hi
+	}
+	return s.m.SubMap(lo, hi)
+}
+
+// Empty returns true if the view contains no elements.
+//
+// Complexity: O(log n) worst-case
+func (s *OrderedSubMap[K, V]) Empty() bool {
+	return s.Min() == nil
+}
+
+// Len returns the number of elements in the view.
+//
+// Complexity: O(log n) worst-case
+func (s *OrderedSubMap[K, V]) Len() int {
+	if s == nil {
+		return 0
+	}
+	return s.m.CountRange(s.lo, s.hi)
+}
+
+// Get returns the value associated with the given key if it is set and within the view's bounds.
+//
+// Complexity: O(log n) worst-case
+func (s *OrderedSubMap[K, V]) Get(key K) (V, bool) {
+	if s == nil || !s.inRange(key) {
+		var zero V
+		return zero, false
+	}
+	return s.m.Get(key)
+}
+
+// Min returns the element in the view with the smallest key, or nil if the view is empty. Note that
+// iterating from the returned element with Next is not limited to the view's bounds.
+//
+// Complexity: O(log n) worst-case
+func (s *OrderedSubMap[K, V]) Min() *OrderedMapElement[K, V] {
+	if s == nil {
+		return nil
+	}
+	if e := s.m.MinAtLeast(s.lo); e != nil && e.Key() < s.hi {
+		return e
+	}
+	return nil
+}
+
+// Max returns the element in the view with the greatest key, or nil if the view is empty. Note that
+// iterating from the returned element with Prev is not limited to the view's bounds.
+//
+// Complexity: O(log n) worst-case
+func (s *OrderedSubMap[K, V]) Max() *OrderedMapElement[K, V] {
+	if s == nil {
+		return nil
+	}
+	if e := s.m.MaxBefore(s.hi); e != nil && !(e.Key() < s.lo) {
+		return e
+	}
+	return nil
+}
+
+// ForEach calls f for each element in the view in ascending key order, stopping early if f returns
+// false.
+//
+// Complexity: O(log n + k) worst-case, where k is the number of elements visited
+func (s *OrderedSubMap[K, V]) ForEach(f func(key K, value V) bool) {
+	for e := s.Min(); e != nil && e.Key() < s.hi; e = e.Next() {
+		if !f(e.Key(), e.Value()) {
+			return
+		}
+	}
+}
+
+// OrderedMap returns a standalone map containing the elements in the view. Most of its structure is
+// shared with the underlying map.
+//
+// Complexity: O(log n) worst-case
+func (s *OrderedSubMap[K, V]) OrderedMap() *OrderedMap[K, V] {
+	if s.Empty() {
+		return nil
+	}
+	_, rest := s.m.splitLess(s.lo)
+	ret, _ := rest.splitLess(s.hi)
+	return ret
+}
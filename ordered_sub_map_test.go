@@ -0,0 +1,78 @@
+package immutable
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedSubMap(t *testing.T) {
+	var m *OrderedMap[int, int]
+	for i := 0; i < 100; i += 2 {
+		m = m.Set(i, i*10)
+	}
+
+	s := m.SubMap(9, 21)
+	assert.False(t, s.Empty())
+	assert.Equal(t, 6, s.Len())
+	v, ok := s.Get(10)
+	assert.True(t, ok)
+	assert.Equal(t, 100, v)
+	_, ok = s.Get(8)
+	assert.False(t, ok)
+	_, ok = s.Get(22)
+	assert.False(t, ok)
+	assert.Equal(t, 10, s.Min().Key())
+	assert.Equal(t, 20, s.Max().Key())
+
+	var keys []int
+	s.ForEach(func(key, value int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	assert.Equal(t, []int{10, 12, 14, 16, 18, 20}, keys)
+
+	keys = nil
+	s.ForEach(func(key, value int) bool {
+		keys = append(keys, key)
+		return len(keys) < 2
+	})
+	assert.Equal(t, []int{10, 12}, keys)
+
+	sub := s.OrderedMap()
+	require.NoError(t, sub.CheckInvariants())
+	assert.Equal(t, 6, sub.Len())
+	assert.Equal(t, 10, sub.Min().Key())
+	assert.Equal(t, 20, sub.Max().Key())
+
+	nested := s.SubMap(0, 15)
+	assert.Equal(t, 3, nested.Len())
+	assert.Equal(t, 14, nested.Max().Key())
+	assert.True(t, s.SubMap(30, 40).Empty())
+
+	assert.Equal(t, 50, m.SubMap(-100, 100).Len())
+	assert.Equal(t, 50, m.SubMap(-100, 100).OrderedMap().Len())
+	assert.True(t, m.SubMap(11, 12).Empty())
+	assert.Nil(t, m.SubMap(11, 12).Min())
+	assert.Nil(t, m.SubMap(11, 12).Max())
+	assert.Nil(t, m.SubMap(11, 12).OrderedMap())
+	assert.True(t, m.SubMap(20, 10).Empty())
+}
+
+func TestOrderedSubMap_Empty(t *testing.T) {
+	var s *OrderedSubMap[float64, int]
+	assert.True(t, s.Empty())
+	assert.Equal(t, 0, s.Len())
+	_, ok := s.Get(0)
+	assert.False(t, ok)
+	assert.Nil(t, s.SubMap(0, 1))
+	assert.Nil(t, s.OrderedMap())
+
+	m := (*OrderedMap[float64, int])(nil).Set(1, 1)
+	assert.True(t, m.SubMap(math.NaN(), 2).Empty())
+	assert.True(t, m.SubMap(0, math.NaN()).Empty())
+	assert.True(t, m.SubMap(0, 2).SubMap(math.NaN(), 2).Empty())
+	assert.Equal(t, 1, m.SubMap(0, 2).Len())
+}
@@ -0,0 +1,26 @@
+package immutable
+
+// Pair is a key and its associated value. It's used by any API which accepts or returns
+// associations as values, such as OrderedMapOf and OrderedMap.ToPairs.
+type Pair[K, V any] struct {
+	Key   K
+	Value V
+}
+
+// MakePair returns a pair with the given key and value. Unlike a composite literal, its type
+// parameters can be inferred.
+//
+// Complexity: O(1) worst-case
+func MakePair[K, V any](key K, value V) Pair[K, V] {
+	return Pair[K, V]{
+		Key:   key,
+		Value: value,
+	}
+}
+
+// Unpack returns the key and value of the pair.
+//
+// Complexity: O(1) worst-case
+func (p Pair[K, V]) Unpack() (K, V) {
+	return p.Key, p.Value
+}
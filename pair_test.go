@@ -0,0 +1,24 @@
+package immutable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPair(t *testing.T) {
+	p := MakePair("a", 1)
+	assert.Equal(t, Pair[string, int]{Key: "a", Value: 1}, p)
+	k, v := p.Unpack()
+	assert.Equal(t, "a", k)
+	assert.Equal(t, 1, v)
+}
+
+func TestOrderedMap_ToPairs(t *testing.T) {
+	var m *OrderedMap[string, int]
+	assert.Nil(t, m.ToPairs())
+
+	m = OrderedMapOf(MakePair("b", 2), MakePair("a", 1))
+	assert.Equal(t, []Pair[string, int]{{"a", 1}, {"b", 2}}, m.ToPairs())
+	assert.Equal(t, m.ToMap(), OrderedMapOf(m.ToPairs()...).ToMap())
+}
@@ -0,0 +1,358 @@
+package immutable
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Point is a location in two-dimensional space.
+type Point struct {
+	X, Y float64
+}
+
+// PointMap implements a map keyed by points in two-dimensional space which supports efficient
+// window and nearest-neighbor queries.
+//
+// It is a k-d tree which is kept balanced by rebuilding subtrees that become too lopsided. As such,
+// the time complexities of modifications are amortized. Like any amortized structure, their bounds
+// are not guaranteed if the same old version of the map is repeatedly modified. Lookups aren't
+// amortized: every modification leaves each subtree with no more than three quarters of its
+// elements on either side, so the depth of the tree is always O(log n), no matter how the map was
+// built.
+//
+// Nil and the zero value for PointMap are both empty maps.
+type PointMap[V any] struct {
+	root *pointMapNode[V]
+}
+
+type pointMapNode[V any] struct {
+	point Point
+	value V
+	size  int
+
+	// The bounding box of all points in the subtree.
+	lo Point
+	hi Point
+
+	left  *pointMapNode[V]
+	right *pointMapNode[V]
+}
+
+func newPointMap[V any](root *pointMapNode[V]) *PointMap[V] {
+	if root == nil {
+		return nil
+	}
+	return &PointMap[V]{
+		root: root,
+	}
+}
+
+func newPointMapNode[V any](point Point, value V, left, right *pointMapNode[V]) *pointMapNode[V] {
+	ret := &pointMapNode[V]{
+		point: point,
+		value: value,
+		size:  1 + left.Len() + right.Len(),
+		lo:    point,
+		hi:    point,
+		left:  left,
+		right: right,
+	}
+	for _, child := range [2]*pointMapNode[V]{left, right} {
+		if child != nil {
+			ret.lo.X = math.Min(ret.lo.X, child.lo.X)
+			ret.lo.Y = math.Min(ret.lo.Y, child.lo.Y)
+			ret.hi.X = math.Max(ret.hi.X, child.hi.X)
+			ret.hi.Y = math.Max(ret.hi.Y, child.hi.Y)
+		}
+	}
+	return ret
+}
+
+func (n *pointMapNode[V]) Len() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+// pointMapLess orders points for the node at the given depth. Even depths compare x coordinates
+// first, and odd depths compare y coordinates first. The other coordinate breaks ties, so that
+// every node divides its subtree strictly.
+func pointMapLess(a, b Point, depth int) bool {
+	if depth%2 == 0 {
+		return a.X < b.X || (a.X == b.X && a.Y < b.Y)
+	}
+	return a.Y < b.Y || (a.Y == b.Y && a.X < b.X)
+}
+
+// pointMapBalanced returns true if a node with subtrees of the given sizes doesn't need to be
+// rebuilt.
+func pointMapBalanced(left, right int) bool {
+	size := 1 + left + right
+	if left < right {
+		left = right
+	}
+	return size < 4 || 4*left <= 3*size
+}
+
+// Empty returns true if the map is empty.
+//
+// Complexity: O(1) worst-case
+func (m *PointMap[V]) Empty() bool {
+	return m == nil || m.root == nil
+}
+
+// Len returns the number of elements in the map.
+//
+// Complexity: O(1) worst-case
+func (m *PointMap[V]) Len() int {
+	if m == nil {
+		return 0
+	}
+	return m.root.Len()
+}
+
+// Get returns the value associated with the given point if set.
+//
+// Complexity: O(log n) worst-case, since the depth of the tree is always logarithmic
+func (m *PointMap[V]) Get(point Point) (V, bool) {
+	var n *pointMapNode[V]
+	if m != nil {
+		n = m.root
+	}
+	for depth := 0; n != nil; depth++ {
+		if n.point == point {
+			return n.value, true
+		} else if pointMapLess(point, n.point, depth) {
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Set associates a value with the given point. It panics if either coordinate is NaN.
+//
+// Complexity: O(log² n) amortized
+func (m *PointMap[V]) Set(point Point, value V) *PointMap[V] {
+	if math.IsNaN(point.X) || math.IsNaN(point.Y) {
+		panic("immutable: PointMap coordinates must not be NaN")
+	}
+	var root *pointMapNode[V]
+	if m != nil {
+		root = m.root
+	}
+	return newPointMap(root.insert(0, point, value))
+}
+
+func (n *pointMapNode[V]) insert(depth int, point Point, value V) *pointMapNode[V] {
+	if n == nil {
+		return newPointMapNode[V](point, value, nil, nil)
+	} else if n.point == point {
+		ret := *n
+		ret.value = value
+		return &ret
+	} else if pointMapLess(point, n.point, depth) {
+		return pointMapRebalance(depth, n.point, n.value, n.left.insert(depth+1, point, value), n.right)
+	}
+	return pointMapRebalance(depth, n.point, n.value, n.left, n.right.insert(depth+1, point, value))
+}
+
+// Delete removes a point from the map.
+//
+// Complexity: O(√n + log² n) amortized
+func (m *PointMap[V]) Delete(point Point) *PointMap[V] {
+	if _, ok := m.Get(point); !ok {
+		return m
+	}
+	return newPointMap(m.root.delete(0, point))
+}
+
+// delete removes the given point, which must be present in the subtree.
+func (n *pointMapNode[V]) delete(depth int, point Point) *pointMapNode[V] {
+	if n.point == point {
+		if n.right != nil {
+			replacement := n.right.min(depth+1, depth)
+			return pointMapRebalance(depth, replacement.point, replacement.value, n.left, n.right.delete(depth+1, replacement.point))
+		} else if n.left != nil {
+			replacement := n.left.max(depth+1, depth)
+			return pointMapRebalance(depth, replacement.point, replacement.value, n.left.delete(depth+1, replacement.point), nil)
+		}
+		return nil
+	} else if pointMapLess(point, n.point, depth) {
+		return pointMapRebalance(depth, n.point, n.value, n.left.delete(depth+1, point), n.right)
+	}
+	return pointMapRebalance(depth, n.point, n.value, n.left, n.right.delete(depth+1, point))
+}
+
+// min returns the node in the subtree at the given depth which is least in the order used at
+// orderDepth.
+func (n *pointMapNode[V]) min(depth, orderDepth int) *pointMapNode[V] {
+	if n == nil {
+		return nil
+	} else if depth%2 == orderDepth%2 {
+		if n.left != nil {
+			return n.left.min(depth+1, orderDepth)
+		}
+		return n
+	}
+	ret := n
+	for _, child := range [2]*pointMapNode[V]{n.left.min(depth+1, orderDepth), n.right.min(depth+1, orderDepth)} {
+		if child != nil && pointMapLess(child.point, ret.point, orderDepth) {
+			ret = child
+		}
+	}
+	return ret
+}
+
+// max returns the node in the subtree at the given depth which is greatest in the order used at
+// orderDepth.
+func (n *pointMapNode[V]) max(depth, orderDepth int) *pointMapNode[V] {
+	if n == nil {
+		return nil
+	} else if depth%2 == orderDepth%2 {
+		if n.right != nil {
+			return n.right.max(depth+1, orderDepth)
+		}
+		return n
+	}
+	ret := n
+	for _, child := range [2]*pointMapNode[V]{n.left.max(depth+1, orderDepth), n.right.max(depth+1, orderDepth)} {
+		if child != nil && pointMapLess(ret.point, child.point, orderDepth) {
+			ret = child
+		}
+	}
+	return ret
+}
+
+// pointMapRebalance returns a node with the given contents, rebuilding it if it's too lopsided.
+func pointMapRebalance[V any](depth int, point Point, value V, left, right *pointMapNode[V]) *pointMapNode[V] {
+	ret := newPointMapNode(point, value, left, right)
+	if pointMapBalanced(left.Len(), right.Len()) {
+		return ret
+	}
+	return pointMapBuild(depth, ret.appendTo(make([]*pointMapNode[V], 0, ret.size)))
+}
+
+// pointMapBuild builds a balanced subtree for the given depth containing the points and values of
+// the given nodes.
+func pointMapBuild[V any](depth int, nodes []*pointMapNode[V]) *pointMapNode[V] {
+	if len(nodes) == 0 {
+		return nil
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return pointMapLess(nodes[i].point, nodes[j].point, depth)
+	})
+	mid := len(nodes) / 2
+	left := pointMapBuild(depth+1, nodes[:mid])
+	right := pointMapBuild(depth+1, nodes[mid+1:])
+	return newPointMapNode(nodes[mid].point, nodes[mid].value, left, right)
+}
+
+func (n *pointMapNode[V]) appendTo(dst []*pointMapNode[V]) []*pointMapNode[V] {
+	if n == nil {
+		return dst
+	}
+	return n.right.appendTo(append(n.left.appendTo(dst), n))
+}
+
+// Range calls f for each point within the rectangle with the given corners, inclusive, stopping
+// early if f returns false. The order of the points is unspecified.
+//
+// Complexity: O(√n + k) worst-case, where k is the number of points in the rectangle
+func (m *PointMap[V]) Range(min, max Point, f func(point Point, value V) bool) {
+	if m.Empty() {
+		return
+	}
+	m.root.forEachInRange(min, max, f)
+}
+
+func (n *pointMapNode[V]) forEachInRange(min, max Point, f func(point Point, value V) bool) bool {
+	if n == nil || n.hi.X < min.X || n.lo.X > max.X || n.hi.Y < min.Y || n.lo.Y > max.Y {
+		return true
+	}
+	if n.point.X >= min.X && n.point.X <= max.X && n.point.Y >= min.Y && n.point.Y <= max.Y {
+		if !f(n.point, n.value) {
+			return false
+		}
+	}
+	return n.left.forEachInRange(min, max, f) && n.right.forEachInRange(min, max, f)
+}
+
+// Nearest returns the point in the map closest to the given point by Euclidean distance, along with
+// its value. If there are multiple, any of them may be returned. If the map is empty, false is
+// returned.
+//
+// Complexity: O(n) worst-case, but typically O(log n) for evenly distributed points
+func (m *PointMap[V]) Nearest(point Point) (Point, V, bool) {
+	if m.Empty() {
+		var zero V
+		return Point{}, zero, false
+	}
+	best, bestDistance := m.root, math.Inf(1)
+	m.root.nearest(point, &best, &bestDistance)
+	return best.point, best.value, true
+}
+
+func (n *pointMapNode[V]) nearest(point Point, best **pointMapNode[V], bestDistance *float64) {
+	if n == nil || n.boxDistance(point) >= *bestDistance {
+		return
+	}
+	dx, dy := n.point.X-point.X, n.point.Y-point.Y
+	if d := dx*dx + dy*dy; d < *bestDistance {
+		*best, *bestDistance = n, d
+	}
+	first, second := n.left, n.right
+	if second != nil && (first == nil || second.boxDistance(point) < first.boxDistance(point)) {
+		first, second = second, first
+	}
+	first.nearest(point, best, bestDistance)
+	second.nearest(point, best, bestDistance)
+}
+
+// boxDistance returns the squared distance from the given point to the subtree's bounding box.
+func (n *pointMapNode[V]) boxDistance(point Point) float64 {
+	dx := math.Max(0, math.Max(n.lo.X-point.X, point.X-n.hi.X))
+	dy := math.Max(0, math.Max(n.lo.Y-point.Y, point.Y-n.hi.Y))
+	return dx*dx + dy*dy
+}
+
+// CheckInvariants verifies the structural integrity of the map, returning an error describing the
+// first problem found. It checks that every node splits its subtrees on the correct axis, that
+// subtrees are within the balance bound, and that cached sizes and bounding boxes are correct.
+//
+// Complexity: O(n log n) worst-case
+func (m *PointMap[V]) CheckInvariants() error {
+	if m.Empty() {
+		return nil
+	}
+	return m.root.checkInvariants(0)
+}
+
+func (n *pointMapNode[V]) checkInvariants(depth int) error {
+	if n == nil {
+		return nil
+	}
+	for _, child := range n.left.appendTo(nil) {
+		if !pointMapLess(child.point, n.point, depth) {
+			return fmt.Errorf("point %v is out of order", child.point)
+		}
+	}
+	for _, child := range n.right.appendTo(nil) {
+		if !pointMapLess(n.point, child.point, depth) {
+			return fmt.Errorf("point %v is out of order", child.point)
+		}
+	}
+	if expected := newPointMapNode(n.point, n.value, n.left, n.right); n.size != expected.size || n.lo != expected.lo || n.hi != expected.hi {
+		return fmt.Errorf("node with point %v has incorrect size or bounds", n.point)
+	} else if !pointMapBalanced(n.left.Len(), n.right.Len()) {
+		return fmt.Errorf("node with point %v is unbalanced", n.point)
+	}
+	if err := n.left.checkInvariants(depth + 1); err != nil {
+		return err
+	}
+	return n.right.checkInvariants(depth + 1)
+}
@@ -0,0 +1,129 @@
+package immutable
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointMap(t *testing.T) {
+	var m *PointMap[string]
+	assert.True(t, m.Empty())
+	assert.Equal(t, 0, m.Len())
+	_, _, ok := m.Nearest(Point{})
+	assert.False(t, ok)
+	m.Range(Point{-1, -1}, Point{1, 1}, func(Point, string) bool {
+		t.Fatal("empty maps should have no points")
+		return true
+	})
+	assert.Nil(t, m.Delete(Point{}))
+
+	m2 := m.Set(Point{1, 2}, "a").Set(Point{3, 4}, "b").Set(Point{1, 2}, "c")
+	assert.True(t, m.Empty())
+	assert.Equal(t, 2, m2.Len())
+	v, ok := m2.Get(Point{1, 2})
+	assert.True(t, ok)
+	assert.Equal(t, "c", v)
+	_, ok = m2.Get(Point{2, 1})
+	assert.False(t, ok)
+
+	p, v, ok := m2.Nearest(Point{2.5, 3})
+	assert.True(t, ok)
+	assert.Equal(t, Point{3, 4}, p)
+	assert.Equal(t, "b", v)
+
+	assert.Equal(t, 1, m2.Delete(Point{1, 2}).Len())
+	assert.Equal(t, m2, m2.Delete(Point{5, 5}))
+	assert.True(t, m2.Delete(Point{1, 2}).Delete(Point{3, 4}).Empty())
+
+	assert.Panics(t, func() { m.Set(Point{math.NaN(), 0}, "") })
+}
+
+func TestPointMap_Fuzz(t *testing.T) {
+	ref := map[Point]int{}
+	var m *PointMap[int]
+	randomPoint := func() Point {
+		// Use a coarse grid so that points with equal coordinates are common.
+		return Point{float64(rand.Intn(40)), float64(rand.Intn(40))}
+	}
+	for i := 0; i < 5000; i++ {
+		p := randomPoint()
+		if rand.Intn(3) == 0 {
+			delete(ref, p)
+			m = m.Delete(p)
+		} else {
+			ref[p] = i
+			m = m.Set(p, i)
+		}
+		require.Equal(t, len(ref), m.Len())
+		if i%50 != 0 {
+			continue
+		}
+		require.NoError(t, m.CheckInvariants())
+
+		for p, v := range ref {
+			actual, ok := m.Get(p)
+			require.True(t, ok)
+			require.Equal(t, v, actual)
+		}
+
+		lo, hi := randomPoint(), randomPoint()
+		expected := map[Point]int{}
+		for p, v := range ref {
+			if p.X >= lo.X && p.X <= hi.X && p.Y >= lo.Y && p.Y <= hi.Y {
+				expected[p] = v
+			}
+		}
+		actual := map[Point]int{}
+		m.Range(lo, hi, func(p Point, v int) bool {
+			actual[p] = v
+			return true
+		})
+		require.Equal(t, expected, actual)
+
+		q := Point{rand.Float64() * 40, rand.Float64() * 40}
+		best := math.Inf(1)
+		for p := range ref {
+			best = math.Min(best, math.Hypot(p.X-q.X, p.Y-q.Y))
+		}
+		nearest, v, ok := m.Nearest(q)
+		require.Equal(t, len(ref) > 0, ok)
+		if ok {
+			require.Equal(t, best, math.Hypot(nearest.X-q.X, nearest.Y-q.Y))
+			require.Equal(t, ref[nearest], v)
+		}
+	}
+}
+
+func TestPointMap_RangeEarlyTermination(t *testing.T) {
+	var m *PointMap[int]
+	for i := 0; i < 100; i++ {
+		m = m.Set(Point{float64(i), float64(i)}, i)
+	}
+	count := 0
+	m.Range(Point{0, 0}, Point{100, 100}, func(Point, int) bool {
+		count++
+		return count < 5
+	})
+	assert.Equal(t, 5, count)
+}
+
+var pointMapResult *PointMap[int]
+
+func BenchmarkPointMap_Set(b *testing.B) {
+	for _, n := range []int{100, 10000, 1000000} {
+		var m *PointMap[int]
+		for i := 0; i < n; i++ {
+			m = m.Set(Point{rand.Float64(), rand.Float64()}, i)
+		}
+		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				pointMapResult = m.Set(Point{rand.Float64(), rand.Float64()}, i)
+			}
+		})
+	}
+}
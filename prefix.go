@@ -0,0 +1,28 @@
+package immutable
+
+// PrefixUpperBound returns the least key which is greater than every key beginning with prefix, so
+// that the keys with the prefix are those greater than or equal to prefix and less than the bound.
+// If there's no such key, because the prefix is empty or consists entirely of 0xff bytes, it returns
+// false: every key greater than or equal to prefix begins with it.
+//
+// Complexity: O(n) worst-case, where n is the length of the prefix
+func PrefixUpperBound[K ~string](prefix K) (K, bool) {
+	// Drop any trailing 0xff bytes, which can't be incremented, then increment the last byte.
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xff {
+			return prefix[:i] + K([]byte{prefix[i] + 1}), true
+		}
+	}
+	return "", false
+}
+
+// DeletePrefix removes all elements whose keys begin with prefix, as bytes.
+//
+// Complexity: O(log n + p) worst-case, where p is the length of the prefix
+func DeletePrefix[K ~string, V any](m *OrderedMap[K, V], prefix K) *OrderedMap[K, V] {
+	if hi, ok := PrefixUpperBound(prefix); ok {
+		return m.DeleteRange(prefix, hi)
+	}
+	left, _ := m.Split(prefix)
+	return left
+}
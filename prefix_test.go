@@ -0,0 +1,55 @@
+package immutable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixUpperBound(t *testing.T) {
+	for _, tc := range []struct {
+		prefix   string
+		expected string
+		ok       bool
+	}{
+		{"", "", false},
+		{"a", "b", true},
+		{"ab", "ac", true},
+		{"a\xff", "b", true},
+		{"a\xff\xff", "b", true},
+		{"\xfe\xff", "\xff", true},
+		{"\xff", "", false},
+		{"\xff\xff", "", false},
+	} {
+		bound, ok := PrefixUpperBound(tc.prefix)
+		assert.Equal(t, tc.ok, ok, "%q", tc.prefix)
+		assert.Equal(t, tc.expected, bound, "%q", tc.prefix)
+	}
+
+	bound, ok := PrefixUpperBound(BytesKey("a"))
+	assert.True(t, ok)
+	assert.Equal(t, BytesKey("b"), bound)
+}
+
+func TestDeletePrefix(t *testing.T) {
+	keys := []string{"", "a", "ab", "abc", "a\xff", "a\xff\x00", "b", "\xff", "\xff\xff", "\xff\xff\x01"}
+	var m *OrderedMap[string, int]
+	for i, k := range keys {
+		m = m.Set(k, i)
+	}
+	for _, prefix := range append(keys, "c", "a\xff\xff") {
+		result := DeletePrefix(m, prefix)
+		require.NoError(t, result.CheckInvariants())
+		var expected []string
+		for _, k := range keys {
+			if !strings.HasPrefix(k, prefix) {
+				expected = append(expected, k)
+			}
+		}
+		assert.Equal(t, expected, orderedMapKeys(result), "%q", prefix)
+	}
+	assert.Same(t, m, DeletePrefix(m, "c"))
+	assert.Nil(t, DeletePrefix((*OrderedMap[string, int])(nil), "a"))
+}
@@ -0,0 +1,128 @@
+package immutable
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+// PriorityQueue implements a priority queue which is stable: items with equal priorities are
+// popped in the order they were pushed. This is what task schedulers usually need, since it makes
+// each priority level first in, first out. Items with the lowest priority are popped first. Like
+// OrderedMap, it doesn't support NaN priorities.
+//
+// Each priority level is stored as a Queue, so no sequence numbers are needed to break ties.
+//
+// Nil and the zero value for PriorityQueue are both empty queues.
+type PriorityQueue[P constraints.Ordered, T any] struct {
+	levels *OrderedMap[P, *Queue[T]]
+	len    int
+}
+
+// Empty returns true if the queue is empty.
+//
+// Complexity: O(1) worst-case
+func (q *PriorityQueue[P, T]) Empty() bool {
+	return q.Len() == 0
+}
+
+// Len returns the number of items in the queue.
+//
+// Complexity: O(1) worst-case
+func (q *PriorityQueue[P, T]) Len() int {
+	if q == nil {
+		return 0
+	}
+	return q.len
+}
+
+// Push adds an item with the given priority. It will be popped after any items already in the
+// queue with the same priority. It panics if the priority is NaN.
+//
+// Complexity: O(log n) worst-case
+func (q *PriorityQueue[P, T]) Push(priority P, value T) *PriorityQueue[P, T] {
+	var ret PriorityQueue[P, T]
+	if q != nil {
+		ret = *q
+	}
+	level, _ := ret.levels.Get(priority)
+	ret.levels = ret.levels.Set(priority, level.PushBack(value))
+	ret.len++
+	return &ret
+}
+
+// Front returns the item which would be popped next, along with its priority. It panics if the
+// queue is empty.
+//
+// Complexity: O(log n) worst-case
+func (q *PriorityQueue[P, T]) Front() (P, T) {
+	if q.Empty() {
+		panic("immutable: PriorityQueue is empty")
+	}
+	min := q.levels.Min()
+	return min.Key(), min.Value().Front()
+}
+
+// TryFront returns the item which would be popped next, along with its priority, or false if the
+// queue is empty.
+//
+// Complexity: O(log n) worst-case
+func (q *PriorityQueue[P, T]) TryFront() (P, T, bool) {
+	if q.Empty() {
+		var zeroPriority P
+		var zero T
+		return zeroPriority, zero, false
+	}
+	priority, value := q.Front()
+	return priority, value, true
+}
+
+// PopFront removes the item with the lowest priority which was pushed first. It panics if the queue
+// is empty.
+//
+// Complexity: O(log n) worst-case
+func (q *PriorityQueue[P, T]) PopFront() *PriorityQueue[P, T] {
+	if q.Empty() {
+		panic("immutable: PriorityQueue is empty")
+	}
+	ret := *q
+	min := ret.levels.Min()
+	if level := min.Value().PopFront(); level.Empty() {
+		ret.levels = ret.levels.Delete(min.Key())
+	} else {
+		ret.levels = min.SetValue(level)
+	}
+	ret.len--
+	return &ret
+}
+
+// CheckInvariants verifies the structural integrity of the queue, returning an error describing the
+// first problem found. It checks that the priorities are in order, that no priority level is empty,
+// and that the recorded length is correct.
+//
+// Complexity: O(n) worst-case
+func (q *PriorityQueue[P, T]) CheckInvariants() error {
+	if q == nil {
+		return nil
+	} else if err := q.levels.CheckInvariants(); err != nil {
+		return err
+	}
+	n := 0
+	var err error
+	q.levels.ForEach(func(priority P, level *Queue[T]) bool {
+		if err = level.CheckInvariants(); err != nil {
+			return false
+		} else if level.Empty() {
+			err = fmt.Errorf("priority %v has no items", priority)
+			return false
+		}
+		n += len(level.ToSlice())
+		return true
+	})
+	if err != nil {
+		return err
+	} else if n != q.len {
+		return fmt.Errorf("queue has %v items, but %v were recorded", n, q.len)
+	}
+	return nil
+}
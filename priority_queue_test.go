@@ -0,0 +1,70 @@
+package immutable
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	var q *PriorityQueue[int, string]
+	assert.True(t, q.Empty())
+	assert.Equal(t, 0, q.Len())
+	_, _, ok := q.TryFront()
+	assert.False(t, ok)
+	assert.Panics(t, func() {
+		q.Front()
+	})
+	assert.Panics(t, func() {
+		q.PopFront()
+	})
+
+	q = q.Push(2, "a").Push(1, "b").Push(2, "c").Push(1, "d").Push(3, "e")
+	assert.Equal(t, 5, q.Len())
+
+	var popped []string
+	for p := q; !p.Empty(); p = p.PopFront() {
+		_, value := p.Front()
+		popped = append(popped, value)
+	}
+	assert.Equal(t, []string{"b", "d", "a", "c", "e"}, popped)
+
+	priority, value, ok := q.TryFront()
+	assert.True(t, ok)
+	assert.Equal(t, 1, priority)
+	assert.Equal(t, "b", value)
+
+	assert.Panics(t, func() {
+		(*PriorityQueue[float64, int])(nil).Push(math.NaN(), 1)
+	})
+}
+
+func TestPriorityQueue_Stable(t *testing.T) {
+	type item struct {
+		priority int
+		seq      int
+	}
+	var q *PriorityQueue[int, int]
+	var ref []item
+	for i := 0; i < 5000; i++ {
+		if len(ref) > 0 && rand.Intn(3) == 0 {
+			priority, seq := q.Front()
+			require.Equal(t, ref[0], item{priority, seq})
+			q = q.PopFront()
+			ref = ref[1:]
+		} else {
+			it := item{rand.Intn(10), i}
+			q = q.Push(it.priority, it.seq)
+			ref = append(ref, it)
+			sort.SliceStable(ref, func(i, j int) bool {
+				return ref[i].priority < ref[j].priority
+			})
+		}
+		require.NoError(t, q.CheckInvariants())
+		require.Equal(t, len(ref), q.Len())
+	}
+}
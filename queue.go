@@ -1,12 +1,15 @@
 package immutable
 
+import "fmt"
+
 func queueRotate[T any](f *lazyList[T], r *Stack[T], s *lazyList[T]) *lazyList[T] {
 	if f == nil {
 		return s.PushFront(r.Peek())
 	}
-	return newLazyList(f.Front(), func() *lazyList[T] {
-		return queueRotate(f.PopFront(), r.Pop(), s.PushFront(r.Peek()))
-	})
+	return &lazyList[T]{
+		value:    f.Front(),
+		rotation: lazyListRotation[T]{f, r, s},
+	}
 }
 
 func queueExec[T any](f *lazyList[T], r *Stack[T], s *lazyList[T]) *Queue[T] {
@@ -19,6 +22,16 @@ func queueExec[T any](f *lazyList[T], r *Stack[T], s *lazyList[T]) *Queue[T] {
 
 // Queue implements a first in, first out container.
 //
+// Queue is Okasaki's real-time queue. Its front is a lazily evaluated list which is periodically
+// rebuilt from the rear, and a schedule advances that evaluation by exactly one step on every
+// operation. As a result, Front, PushBack, PopFront, and PushFront are O(1) in the worst case, not
+// just amortized, even when old versions of the queue are reused. No operation ever triggers a
+// long chain of deferred work, which makes Queue suitable for latency-sensitive code.
+//
+// Deferred work is memoized: once any version of a queue evaluates part of its front, every other
+// version sharing that part uses the result, even across goroutines. Popping from the same version
+// many times, as when several consumers branch from it, repeats none of its pending evaluation.
+//
 // Nil and the zero value for Queue are both empty queues.
 type Queue[T any] struct {
 	f *lazyList[T]
@@ -26,6 +39,26 @@ type Queue[T any] struct {
 	s *lazyList[T]
 }
 
+// QueueFromSlice returns a new queue containing the given items. The first item will be at the
+// front.
+//
+// Complexity: O(n) worst-case
+func QueueFromSlice[T any](items []T) *Queue[T] {
+	var f *lazyList[T]
+	for i := len(items) - 1; i >= 0; i-- {
+		f = f.PushFront(items[i])
+	}
+	// The front is already fully evaluated, so it can double as the schedule.
+	return &Queue[T]{f, nil, f}
+}
+
+// QueueOf returns a new queue containing the given items. The first item will be at the front.
+//
+// Complexity: O(n) worst-case
+func QueueOf[T any](items ...T) *Queue[T] {
+	return QueueFromSlice(items)
+}
+
 // Empty returns true if the queue is empty.
 //
 // Complexity: O(1) worst-case
@@ -33,23 +66,231 @@ func (q *Queue[T]) Empty() bool {
 	return q == nil || q.f == nil
 }
 
-// Front returns the item at the front of the queue.
+// Front returns the item at the front of the queue. It panics if the queue is empty.
 //
 // Complexity: O(1) worst-case
 func (q *Queue[T]) Front() T {
+	if q.Empty() {
+		panic("immutable: Queue is empty")
+	}
 	return q.f.Front()
 }
 
-// PopFront removes the item at the front of the queue.
+// TryFront returns the item at the front of the queue, or false if the queue is empty.
+//
+// Complexity: O(1) worst-case
+func (q *Queue[T]) TryFront() (T, bool) {
+	if q.Empty() {
+		var zero T
+		return zero, false
+	}
+	return q.f.Front(), true
+}
+
+// PeekN returns up to n items from the front of the queue, starting with the front item, without
+// removing them. If the queue has fewer than n items, all of them are returned.
+//
+// Complexity: O(n) worst-case
+func (q *Queue[T]) PeekN(n int) []T {
+	if q.Empty() || n <= 0 {
+		return nil
+	}
+	var ret []T
+	for l := q.f; l != nil && len(ret) < n; l = l.PopFront() {
+		ret = append(ret, l.Front())
+	}
+	if len(ret) < n {
+		// The rear is never longer than the front, so reversing it is within the bound.
+		rear := q.r.ToSlice()
+		for i := len(rear) - 1; i >= 0 && len(ret) < n; i-- {
+			ret = append(ret, rear[i])
+		}
+	}
+	return ret
+}
+
+// PopFront removes the item at the front of the queue. It panics if the queue is empty.
 //
 // Complexity: O(1) worst-case
 func (q *Queue[T]) PopFront() *Queue[T] {
+	if q.Empty() {
+		panic("immutable: Queue is empty")
+	}
 	return queueExec(q.f.PopFront(), q.r, q.s)
 }
 
+// Split divides the queue into a queue containing the first n items and a queue containing the rest.
+// If n is out of range, one of the queues is empty and the other is this queue. The rest of the
+// queue is reached by popping rather than rebuilt, so its structure is shared with this queue, and
+// the work done is proportional to n rather than to the length of the queue. This is useful for
+// taking batches of work from a queue, such as for work-stealing consumers.
+//
+// Complexity: O(n) worst-case
+func (q *Queue[T]) Split(n int) (front, back *Queue[T]) {
+	if n <= 0 {
+		return nil, q
+	}
+	size := n
+	if size > 64 {
+		size = 64
+	}
+	items := make([]T, 0, size)
+	for back = q; len(items) < n && !back.Empty(); back = back.PopFront() {
+		items = append(items, back.Front())
+	}
+	if back.Empty() {
+		return q, nil
+	}
+	return QueueFromSlice(items), back
+}
+
 // PushBack pushes an item onto the back of the queue.
 //
 // Complexity: O(1) worst-case
 func (q *Queue[T]) PushBack(value T) *Queue[T] {
+	if q == nil {
+		q = &Queue[T]{}
+	}
 	return queueExec(q.f, q.r.Push(value), q.s)
 }
+
+// PushFront pushes an item onto the front of the queue.
+//
+// Complexity: O(1) worst-case
+func (q *Queue[T]) PushFront(value T) *Queue[T] {
+	if q == nil {
+		q = &Queue[T]{}
+	}
+	// The schedule must remain the same length as the difference between the front and rear, so the
+	// item is also added to it. It's already evaluated, so this doesn't add any future work.
+	return &Queue[T]{q.f.PushFront(value), q.r, q.s.PushFront(value)}
+}
+
+// Concat returns a queue containing the items of this queue followed by the items of the other
+// queue.
+//
+// Complexity: O(m) worst-case, where m is the size of the other queue
+func (q *Queue[T]) Concat(other *Queue[T]) *Queue[T] {
+	if q.Empty() {
+		return other
+	} else if other.Empty() {
+		return q
+	}
+	for ; !other.Empty(); other = other.PopFront() {
+		q = q.PushBack(other.Front())
+	}
+	return q
+}
+
+// CheckInvariants verifies the structural integrity of the queue, returning an error describing the
+// first problem found. It checks that the schedule is as long as the difference between the lengths
+// of the front and rear, which is what keeps every operation constant time.
+//
+// Complexity: O(n) worst-case
+func (q *Queue[T]) CheckInvariants() error {
+	if q == nil {
+		return nil
+	}
+	f, r, s := 0, 0, 0
+	for l := q.f; l != nil; l = l.PopFront() {
+		f++
+	}
+	for l := q.r; !l.Empty(); l = l.Pop() {
+		r++
+	}
+	for l := q.s; l != nil; l = l.PopFront() {
+		s++
+	}
+	if s != f-r {
+		return fmt.Errorf("schedule length is %v, but front and rear lengths are %v and %v", s, f, r)
+	}
+	return nil
+}
+
+// Contains returns true if the queue contains an item equal to the given one according to eq. This
+// makes it possible to avoid pushing duplicates without draining a copy of the queue.
+//
+// Complexity: O(n) worst-case
+func (q *Queue[T]) Contains(value T, eq func(a, b T) bool) bool {
+	return q.count(value, eq, true) > 0
+}
+
+// Count returns the number of items in the queue equal to the given one according to eq.
+//
+// Complexity: O(n) worst-case
+func (q *Queue[T]) Count(value T, eq func(a, b T) bool) int {
+	return q.count(value, eq, false)
+}
+
+// count counts the items equal to value, stopping at the first one if first is true. Order doesn't
+// matter, so the front and rear are walked directly without reversing the rear.
+func (q *Queue[T]) count(value T, eq func(a, b T) bool, first bool) int {
+	if q.Empty() {
+		return 0
+	}
+	n := 0
+	for l := q.f; l != nil; l = l.PopFront() {
+		if eq(l.Front(), value) {
+			if n++; first {
+				return n
+			}
+		}
+	}
+	if first {
+		if q.r.Contains(value, eq) {
+			return 1
+		}
+		return 0
+	}
+	return n + q.r.Count(value, eq)
+}
+
+// ToSlice returns the items in the queue as a slice, starting with the front item.
+//
+// Complexity: O(n) worst-case
+func (q *Queue[T]) ToSlice() []T {
+	if q.Empty() {
+		return nil
+	}
+	// Rather than popping each item, which would create a new queue and advance the schedule every
+	// time, walk the front and then the rear directly.
+	var ret []T
+	for l := q.f; l != nil; l = l.PopFront() {
+		ret = append(ret, l.Front())
+	}
+	rear := q.r.ToSlice()
+	for i := len(rear) - 1; i >= 0; i-- {
+		ret = append(ret, rear[i])
+	}
+	return ret
+}
+
+// Drain returns the items in the queue as a slice, starting with the front item. Since queues are
+// immutable, the queue itself is unchanged. Pending rotations are forced along the way, so this is
+// as efficient as ToSlice.
+//
+// Complexity: O(n) worst-case
+func (q *Queue[T]) Drain() []T {
+	return q.ToSlice()
+}
+
+// ForEach calls f for each item in the queue, starting with the front item, stopping early if f
+// returns false. The order is always the order in which the items would be popped.
+//
+// Complexity: O(n) worst-case
+func (q *Queue[T]) ForEach(f func(value T) bool) {
+	if q.Empty() {
+		return
+	}
+	for l := q.f; l != nil; l = l.PopFront() {
+		if !f(l.Front()) {
+			return
+		}
+	}
+	rear := q.r.ToSlice()
+	for i := len(rear) - 1; i >= 0; i-- {
+		if !f(rear[i]) {
+			return
+		}
+	}
+}
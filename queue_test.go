@@ -2,9 +2,12 @@ package immutable
 
 import (
 	"fmt"
+	"math/rand"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestQueue(t *testing.T) {
@@ -24,6 +27,307 @@ func TestQueue(t *testing.T) {
 	assert.True(t, q4.PopFront().PopFront().PopFront().Empty())
 }
 
+func TestQueue_PushFront(t *testing.T) {
+	var q *Queue[int]
+	q = q.PushFront(1)
+	assert.Equal(t, []int{1}, q.ToSlice())
+	q = q.PushBack(2).PushFront(0)
+	assert.Equal(t, []int{0, 1, 2}, q.ToSlice())
+	assert.Equal(t, []int{-1, 1, 2}, q.PopFront().PushFront(-1).ToSlice())
+
+	var ref []int
+	q = &Queue[int]{}
+	for i := 0; i < 10000; i++ {
+		switch rand.Intn(3) {
+		case 0:
+			ref = append([]int{i}, ref...)
+			q = q.PushFront(i)
+		case 1:
+			ref = append(ref, i)
+			q = q.PushBack(i)
+		default:
+			if len(ref) > 0 {
+				require.Equal(t, ref[0], q.Front())
+				ref = ref[1:]
+				q = q.PopFront()
+			}
+		}
+		require.Equal(t, len(ref) == 0, q.Empty())
+		require.NoError(t, q.CheckInvariants())
+	}
+	assert.Equal(t, append([]int{}, ref...), append([]int{}, q.ToSlice()...))
+}
+
+// queueScheduleIsAhead returns true if every cell of the queue's front that precedes the schedule
+// has already been evaluated. This is what guarantees that no operation has to do more than a
+// constant amount of deferred work.
+func queueScheduleIsAhead[T any](q *Queue[T]) bool {
+	r := 0
+	for s := q.r; !s.Empty(); s = s.Pop() {
+		r++
+	}
+	l := q.f
+	for i := 0; i < r; i++ {
+		if l.rotation.r != nil {
+			return false
+		}
+		l = l.next
+	}
+	return true
+}
+
+func TestQueue_RealTime(t *testing.T) {
+	var versions []*Queue[int]
+	q := &Queue[int]{}
+	for i := 0; i < 10000; i++ {
+		switch rand.Intn(4) {
+		case 0:
+			if !q.Empty() {
+				q = q.PopFront()
+			}
+		case 1:
+			q = q.PushFront(i)
+		case 2:
+			// Operations on old versions must also be constant time.
+			if len(versions) > 0 {
+				q = versions[rand.Intn(len(versions))]
+			}
+		default:
+			q = q.PushBack(i)
+		}
+		require.True(t, queueScheduleIsAhead(q))
+		if i%10 == 0 {
+			versions = append(versions, q)
+		}
+	}
+}
+
+// queueFrontCells returns the cells of the queue's front, forcing each one.
+func queueFrontCells[T any](q *Queue[T]) []*lazyList[T] {
+	var ret []*lazyList[T]
+	for l := q.f; l != nil; l = l.PopFront() {
+		ret = append(ret, l)
+	}
+	return ret
+}
+
+func TestQueue_SharedEvaluation(t *testing.T) {
+	// Build a version whose front has a long rotation pending. The rotation which moves the rear to
+	// the front begins with the 1023rd item and is only partially evaluated by the 1100th.
+	q := &Queue[int]{}
+	for i := 0; i < 1100; i++ {
+		q = q.PushBack(i)
+	}
+	q = q.PopFront()
+	pending := q.f
+	for pending != nil && pending.rotation.r == nil {
+		pending = pending.next
+	}
+	require.NotNil(t, pending)
+
+	// Force the version's front from many branches at once. Every branch must see the same cells,
+	// which means each step of the rotation was performed once and then shared.
+	var wg sync.WaitGroup
+	branches := make([][]*lazyList[int], 8)
+	for i := range branches {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			b := q
+			if i%2 == 1 {
+				b = b.PushBack(i).PushFront(i).PopFront()
+			}
+			for !b.Empty() {
+				b = b.PopFront()
+			}
+			branches[i] = queueFrontCells(q)
+		}(i)
+	}
+	wg.Wait()
+	for _, cells := range branches[1:] {
+		require.Equal(t, len(branches[0]), len(cells))
+		for i := range cells {
+			require.Same(t, branches[0][i], cells[i])
+		}
+	}
+	for _, l := range branches[0] {
+		require.Nil(t, l.rotation.r, "forced cells should release their pending rotation")
+	}
+
+	if raceEnabled {
+		t.Skip("the race detector affects allocations")
+	}
+	// Now that the front is evaluated, popping from the version again must not redo any of its
+	// rotation: each PopFront only allocates the new queue. Popping far enough would start a new
+	// rotation belonging to the new versions, so this stops well short of that.
+	n := len(q.ToSlice()) / 2
+	assert.Equal(t, float64(n), testing.AllocsPerRun(10, func() {
+		b := q
+		for i := 0; i < n; i++ {
+			b = b.PopFront()
+		}
+	}))
+}
+
+func TestQueue_TryFront(t *testing.T) {
+	var q *Queue[int]
+	_, ok := q.TryFront()
+	assert.False(t, ok)
+	assert.PanicsWithValue(t, "immutable: Queue is empty", func() { q.Front() })
+	assert.PanicsWithValue(t, "immutable: Queue is empty", func() { q.PopFront() })
+	assert.PanicsWithValue(t, "immutable: Queue is empty", func() { QueueOf(1).PopFront().Front() })
+
+	v, ok := QueueOf(1, 2).TryFront()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+}
+
+func TestQueue_PeekN(t *testing.T) {
+	var q *Queue[int]
+	assert.Empty(t, q.PeekN(3))
+
+	q = &Queue[int]{}
+	var ref []int
+	for i := 0; i < 50; i++ {
+		for n := 0; n <= len(ref)+1; n++ {
+			expected := ref
+			if n < len(ref) {
+				expected = ref[:n]
+			}
+			require.Equal(t, len(expected), len(q.PeekN(n)))
+			if n > 0 {
+				require.Equal(t, expected, q.PeekN(n))
+			}
+		}
+		if i%3 == 2 {
+			q = q.PopFront()
+			ref = ref[1:]
+		}
+		q = q.PushBack(i)
+		ref = append(ref, i)
+	}
+}
+
+func TestQueue_Contains(t *testing.T) {
+	eq := func(a, b int) bool {
+		return a == b
+	}
+	var q *Queue[int]
+	assert.False(t, q.Contains(1, eq))
+	assert.Equal(t, 0, q.Count(1, eq))
+
+	q = &Queue[int]{}
+	ref := map[int]int{}
+	for i := 0; i < 100; i++ {
+		if i%4 == 3 {
+			ref[q.Front()%7]--
+			q = q.PopFront()
+		}
+		q = q.PushBack(i % 7)
+		ref[i%7]++
+		for v := -1; v < 8; v++ {
+			require.Equal(t, ref[v] > 0, q.Contains(v, eq))
+			require.Equal(t, ref[v], q.Count(v, eq))
+		}
+	}
+}
+
+func TestQueue_Split(t *testing.T) {
+	front, back := (*Queue[int])(nil).Split(1)
+	assert.True(t, front.Empty())
+	assert.True(t, back.Empty())
+
+	q := &Queue[int]{}
+	for i := 0; i < 20; i++ {
+		q = q.PushBack(i)
+	}
+	q = q.PopFront().PopFront().PushBack(20)
+	ref := q.ToSlice()
+	for n := -1; n <= len(ref)+1; n++ {
+		front, back := q.Split(n)
+		require.NoError(t, front.CheckInvariants())
+		require.NoError(t, back.CheckInvariants())
+		i := n
+		if i < 0 {
+			i = 0
+		} else if i > len(ref) {
+			i = len(ref)
+		}
+		require.Equal(t, ref[:i], append([]int{}, front.ToSlice()...))
+		require.Equal(t, ref[i:], append([]int{}, back.ToSlice()...))
+
+		// Both halves remain usable as queues.
+		require.Equal(t, append(append([]int{}, ref[i:]...), -1), back.PushBack(-1).ToSlice())
+		require.Equal(t, append(append([]int{}, ref[:i]...), -1), front.PushBack(-1).ToSlice())
+	}
+
+	front, back = q.Split(0)
+	assert.Same(t, q, back)
+	front, back = q.Split(100)
+	assert.Same(t, q, front)
+	assert.True(t, back.Empty())
+}
+
+func TestQueue_Nil(t *testing.T) {
+	var q *Queue[int]
+	assert.Equal(t, []int{1}, q.PushBack(1).ToSlice())
+	assert.Equal(t, []int{1}, q.PushFront(1).ToSlice())
+}
+
+func TestQueueFromSlice(t *testing.T) {
+	assert.True(t, QueueFromSlice[int](nil).Empty())
+	assert.Empty(t, QueueFromSlice[int](nil).ToSlice())
+
+	q := QueueFromSlice([]int{1, 2, 3})
+	assert.Equal(t, 1, q.Front())
+	assert.Equal(t, []int{1, 2, 3}, q.ToSlice())
+	assert.Equal(t, []int{2, 3, 4}, q.PopFront().PushBack(4).ToSlice())
+}
+
+func TestQueueOf(t *testing.T) {
+	assert.True(t, QueueOf[int]().Empty())
+
+	q := QueueOf(1, 2, 3)
+	require.NoError(t, q.CheckInvariants())
+	assert.Equal(t, 1, q.Front())
+	assert.Equal(t, []int{1, 2, 3}, q.Drain())
+	assert.Equal(t, []int{1, 2, 3}, q.Drain())
+	assert.Equal(t, []int{2, 3, 4, 5}, q.PopFront().PushBack(4).PushBack(5).Drain())
+}
+
+func TestQueue_Drain(t *testing.T) {
+	var q *Queue[int]
+	assert.Nil(t, q.Drain())
+
+	q = &Queue[int]{}
+	var ref []int
+	for i := 0; i < 100; i++ {
+		require.Equal(t, ref, q.Drain())
+		if i%3 == 2 {
+			q = q.PopFront()
+			ref = ref[1:]
+		}
+		q = q.PushBack(i)
+		ref = append(ref, i)
+	}
+}
+
+func TestQueue_Concat(t *testing.T) {
+	var q *Queue[int]
+	assert.True(t, q.Concat(nil).Empty())
+	assert.Equal(t, []int{1, 2}, q.Concat(QueueFromSlice([]int{1, 2})).ToSlice())
+	assert.Equal(t, []int{1, 2}, QueueFromSlice([]int{1, 2}).Concat(nil).ToSlice())
+
+	a := QueueFromSlice([]int{1, 2, 3})
+	b := QueueFromSlice([]int{4, 5})
+	c := a.Concat(b)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, c.ToSlice())
+	assert.Equal(t, []int{2, 3, 4, 5, 6}, c.PopFront().PushBack(6).ToSlice())
+	assert.Equal(t, []int{1, 2, 3}, a.ToSlice())
+	assert.Equal(t, []int{4, 5}, b.ToSlice())
+	require.NoError(t, c.CheckInvariants())
+}
+
 var stringQueueResult *Queue[string]
 
 func BenchmarkQueue_PushBack(b *testing.B) {
@@ -33,6 +337,7 @@ func BenchmarkQueue_PushBack(b *testing.B) {
 			q = q.PushBack("foo")
 		}
 		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			b.ReportAllocs()
 			for i := 0; i < b.N; i++ {
 				stringQueueResult = q.PushBack("foo")
 			}
@@ -49,9 +354,45 @@ func BenchmarkQueue_PopFront(b *testing.B) {
 			q = q.PushBack(i)
 		}
 		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			b.ReportAllocs()
 			for i := 0; i < b.N; i++ {
 				intQueueResult = q.PopFront()
 			}
 		})
 	}
 }
+
+func BenchmarkQueue_Drain(b *testing.B) {
+	for _, n := range []int{100, 10000, 200000} {
+		q := &Queue[int]{}
+		for i := 0; i < n; i++ {
+			q = q.PushBack(i)
+		}
+		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for q := q; !q.Empty(); q = q.PopFront() {
+					intQueueResult = q
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkQueue_Steady pushes and pops in equal measure, so the queue is continually rotating.
+func BenchmarkQueue_Steady(b *testing.B) {
+	for _, n := range []int{100, 10000} {
+		q := &Queue[int]{}
+		for i := 0; i < n; i++ {
+			q = q.PushBack(i)
+		}
+		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			b.ReportAllocs()
+			q := q
+			for i := 0; i < b.N; i++ {
+				q = q.PushBack(i).PopFront()
+			}
+			intQueueResult = q
+		})
+	}
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package immutable
+
+func init() {
+	raceEnabled = true
+}
@@ -0,0 +1,129 @@
+package immutable
+
+import "fmt"
+
+// Ring implements a circular buffer with a fixed capacity. Once it's full, pushing an item
+// overwrites the oldest one. This is useful for keeping a sliding window over the most recent
+// events, such as for analytics, where each version of the window can be retained cheaply.
+//
+// Items are stored in a Vector, so unchanged chunks of items are shared between versions.
+//
+// Ring must be created with NewRing.
+type Ring[T any] struct {
+	items    *Vector[T]
+	start    int
+	capacity int
+}
+
+// NewRing returns an empty ring with the given capacity. It panics if the capacity is not positive.
+//
+// Complexity: O(1) worst-case
+func NewRing[T any](capacity int) *Ring[T] {
+	if capacity < 1 {
+		panic("immutable: Ring requires a positive capacity")
+	}
+	return &Ring[T]{
+		capacity: capacity,
+	}
+}
+
+// Len returns the number of items in the ring.
+//
+// Complexity: O(1) worst-case
+func (r *Ring[T]) Len() int {
+	return r.items.Len()
+}
+
+// Cap returns the capacity of the ring.
+//
+// Complexity: O(1) worst-case
+func (r *Ring[T]) Cap() int {
+	return r.capacity
+}
+
+// Push adds an item to the ring as the newest item. If the ring is full, the oldest item is
+// removed.
+//
+// Complexity: O(log n) worst-case
+func (r *Ring[T]) Push(value T) *Ring[T] {
+	ret := *r
+	if ret.items.Len() < ret.capacity {
+		ret.items = ret.items.Append(value)
+	} else {
+		ret.items = ret.items.Set(ret.start, value)
+		ret.start = (ret.start + 1) % ret.capacity
+	}
+	return &ret
+}
+
+// At returns the item at the given index, where 0 is the oldest item. It panics if the index is out
+// of range.
+//
+// Complexity: O(log n) worst-case
+func (r *Ring[T]) At(i int) T {
+	if i < 0 || i >= r.Len() {
+		panic("immutable: Ring index out of range")
+	}
+	return r.items.Get((r.start + i) % r.capacity)
+}
+
+// ForEach calls f for each item from oldest to newest, stopping early if f returns false.
+//
+// Complexity: O(n) worst-case
+func (r *Ring[T]) ForEach(f func(value T) bool) {
+	// The oldest items are stored from start to the end of the vector, followed by the rest.
+	i := 0
+	done := false
+	r.items.ForEach(func(value T) bool {
+		if i >= r.start {
+			done = !f(value)
+		}
+		i++
+		return !done
+	})
+	if done {
+		return
+	}
+	i = 0
+	r.items.ForEach(func(value T) bool {
+		if i >= r.start {
+			return false
+		}
+		i++
+		return f(value)
+	})
+}
+
+// ToSlice returns the items in the ring from oldest to newest.
+//
+// Complexity: O(n) worst-case
+func (r *Ring[T]) ToSlice() []T {
+	ret := make([]T, 0, r.Len())
+	r.ForEach(func(value T) bool {
+		ret = append(ret, value)
+		return true
+	})
+	return ret
+}
+
+// CheckInvariants verifies the structural integrity of the ring, returning an error describing the
+// first problem found. It checks that the ring is within its capacity and that the position of the
+// oldest item is within the ring, and is only past the first slot once the ring is full.
+//
+// Complexity: O(n) worst-case
+func (r *Ring[T]) CheckInvariants() error {
+	if r == nil {
+		return nil
+	} else if err := r.items.CheckInvariants(); err != nil {
+		return err
+	} else if r.capacity < 1 {
+		return fmt.Errorf("ring has capacity %v", r.capacity)
+	} else if r.items.Len() > r.capacity {
+		return fmt.Errorf("ring has %v items, but a capacity of %v", r.items.Len(), r.capacity)
+	} else if r.start < 0 || r.start >= r.capacity {
+		return fmt.Errorf("oldest item is at %v, but the capacity is %v", r.start, r.capacity)
+	} else if r.start != 0 && r.items.Len() < r.capacity {
+		return fmt.Errorf("oldest item is at %v, but the ring isn't full", r.start)
+	}
+	return nil
+}
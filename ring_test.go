@@ -0,0 +1,66 @@
+package immutable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRing(t *testing.T) {
+	assert.Panics(t, func() {
+		NewRing[int](0)
+	})
+
+	r := NewRing[int](50)
+	assert.Equal(t, 0, r.Len())
+	assert.Equal(t, 50, r.Cap())
+	assert.Equal(t, []int{}, r.ToSlice())
+	assert.Panics(t, func() {
+		r.At(0)
+	})
+
+	var versions []*Ring[int]
+	for i := 0; i < 200; i++ {
+		r = r.Push(i)
+		versions = append(versions, r)
+	}
+	for i, v := range versions {
+		var expected []int
+		start := i - 49
+		if start < 0 {
+			start = 0
+		}
+		for j := start; j <= i; j++ {
+			expected = append(expected, j)
+		}
+		require.NoError(t, v.CheckInvariants())
+		require.Equal(t, len(expected), v.Len())
+		require.Equal(t, expected, v.ToSlice())
+		for j, value := range expected {
+			require.Equal(t, value, v.At(j))
+		}
+	}
+	assert.Panics(t, func() {
+		r.At(50)
+	})
+	assert.Panics(t, func() {
+		r.At(-1)
+	})
+
+	// A ring which isn't full must start at the beginning of its items.
+	corrupted := *NewRing[int](5).Push(0).Push(1)
+	corrupted.start = 1
+	assert.Error(t, corrupted.CheckInvariants())
+
+	// Iteration can stop early on either side of the wraparound.
+	r = NewRing[int](5).Push(0).Push(1).Push(2).Push(3).Push(4).Push(5).Push(6)
+	for n := 1; n <= 5; n++ {
+		var items []int
+		r.ForEach(func(v int) bool {
+			items = append(items, v)
+			return len(items) < n
+		})
+		assert.Equal(t, []int{2, 3, 4, 5, 6}[:n], items)
+	}
+}
@@ -0,0 +1,155 @@
+package immutable
+
+import "io"
+
+const ropeChunkSize = 512
+
+// Rope implements an immutable sequence of bytes, such as a text buffer, which can be efficiently
+// edited.
+//
+// The bytes are stored in chunks at the leaves of a balanced tree, so inserting, deleting, slicing,
+// and concatenating don't require copying the entire sequence, and unchanged chunks are shared
+// between versions. This makes ropes well suited for editors and for assembling large outputs.
+//
+// Nil and the zero value for Rope are both empty ropes.
+type Rope struct {
+	root *chunkTree[byte]
+}
+
+// RopeFromString returns a new rope containing the given string.
+//
+// Complexity: O(n) worst-case
+func RopeFromString(s string) *Rope {
+	return newRope(chunkTreeFromSlice([]byte(s), ropeChunkSize))
+}
+
+// RopeFromBytes returns a new rope containing a copy of the given bytes.
+//
+// Complexity: O(n) worst-case
+func RopeFromBytes(b []byte) *Rope {
+	return newRope(chunkTreeFromSlice(append([]byte(nil), b...), ropeChunkSize))
+}
+
+func newRope(root *chunkTree[byte]) *Rope {
+	if root == nil {
+		return nil
+	}
+	return &Rope{
+		root: root,
+	}
+}
+
+func (r *Rope) tree() *chunkTree[byte] {
+	if r == nil {
+		return nil
+	}
+	return r.root
+}
+
+// Empty returns true if the rope is empty.
+//
+// Complexity: O(1) worst-case
+func (r *Rope) Empty() bool {
+	return r.tree() == nil
+}
+
+// Len returns the number of bytes in the rope.
+//
+// Complexity: O(1) worst-case
+func (r *Rope) Len() int {
+	return r.tree().Len()
+}
+
+// Index returns the byte at the given index. It panics if the index is out of range.
+//
+// Complexity: O(log n) worst-case
+func (r *Rope) Index(i int) byte {
+	if i < 0 || i >= r.Len() {
+		panic("immutable: Rope index out of range")
+	}
+	return r.root.at(i)
+}
+
+// Concat returns a rope containing the bytes of this rope followed by the bytes of the other.
+//
+// Complexity: O(log n) worst-case
+func (r *Rope) Concat(other *Rope) *Rope {
+	return newRope(chunkTreeConcat(r.tree(), other.tree(), ropeChunkSize))
+}
+
+// Insert returns a rope with the given string inserted before the byte at index i. If i is equal to
+// the length of the rope, the string is appended. It panics if the index is out of range.
+//
+// Complexity: O(log n + m) worst-case, where m is the length of the string
+func (r *Rope) Insert(i int, s string) *Rope {
+	if i < 0 || i > r.Len() {
+		panic("immutable: Rope index out of range")
+	}
+	left, right := r.tree().split(i, ropeChunkSize)
+	inserted := chunkTreeFromSlice([]byte(s), ropeChunkSize)
+	return newRope(chunkTreeConcat(chunkTreeConcat(left, inserted, ropeChunkSize), right, ropeChunkSize))
+}
+
+// Delete returns a rope without the bytes in the range [i, j). It panics if the range is invalid.
+//
+// Complexity: O(log n) worst-case
+func (r *Rope) Delete(i, j int) *Rope {
+	r.checkRange(i, j)
+	left, rest := r.tree().split(i, ropeChunkSize)
+	_, right := rest.split(j-i, ropeChunkSize)
+	return newRope(chunkTreeConcat(left, right, ropeChunkSize))
+}
+
+// Slice returns a rope containing the bytes in the range [i, j). It panics if the range is invalid.
+//
+// Complexity: O(log n) worst-case
+func (r *Rope) Slice(i, j int) *Rope {
+	r.checkRange(i, j)
+	_, rest := r.tree().split(i, ropeChunkSize)
+	middle, _ := rest.split(j-i, ropeChunkSize)
+	return newRope(middle)
+}
+
+func (r *Rope) checkRange(i, j int) {
+	if i < 0 || j < i || j > r.Len() {
+		panic("immutable: Rope range out of bounds")
+	}
+}
+
+// String returns the contents of the rope as a string.
+//
+// Complexity: O(n) worst-case
+func (r *Rope) String() string {
+	return string(r.Bytes())
+}
+
+// Bytes returns the contents of the rope as a new byte slice.
+//
+// Complexity: O(n) worst-case
+func (r *Rope) Bytes() []byte {
+	return r.tree().appendTo(make([]byte, 0, r.Len()))
+}
+
+// WriteTo implements io.WriterTo, writing the contents of the rope to w one chunk at a time.
+//
+// Complexity: O(n) worst-case
+func (r *Rope) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	var err error
+	r.tree().forEachChunk(func(chunk []byte) bool {
+		var n int
+		n, err = w.Write(chunk)
+		written += int64(n)
+		return err == nil
+	})
+	return written, err
+}
+
+// CheckInvariants verifies the structural integrity of the rope, returning an error describing the
+// first problem found. It checks that the chunk tree is AVL balanced, that every chunk is non-empty
+// and within the chunk size, and that cached lengths and heights are correct.
+//
+// Complexity: O(n) worst-case
+func (r *Rope) CheckInvariants() error {
+	return r.tree().checkInvariants(ropeChunkSize)
+}
@@ -0,0 +1,94 @@
+package immutable
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRope(t *testing.T) {
+	var r *Rope
+	assert.True(t, r.Empty())
+	assert.Equal(t, 0, r.Len())
+	assert.Equal(t, "", r.String())
+	assert.Panics(t, func() { r.Index(0) })
+	assert.True(t, RopeFromString("").Empty())
+
+	r = r.Insert(0, "world")
+	r = r.Insert(0, "hello ")
+	r = r.Insert(r.Len(), "!")
+	assert.Equal(t, "hello world!", r.String())
+	assert.Equal(t, byte('w'), r.Index(6))
+	assert.Equal(t, "world", r.Slice(6, 11).String())
+	assert.Equal(t, "hello!", r.Delete(5, 11).String())
+	assert.Equal(t, "hello world!hello world!", r.Concat(r).String())
+	assert.Equal(t, "hello world!", r.String())
+	assert.True(t, r.Slice(3, 3).Empty())
+	assert.True(t, r.Delete(0, r.Len()).Empty())
+
+	assert.Panics(t, func() { r.Insert(13, "") })
+	assert.Panics(t, func() { r.Slice(2, 1) })
+	assert.Panics(t, func() { r.Delete(0, 13) })
+
+	var buf bytes.Buffer
+	n, err := RopeFromBytes([]byte("foo")).Concat(RopeFromString("bar")).WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), n)
+	assert.Equal(t, "foobar", buf.String())
+}
+
+func TestRope_Fuzz(t *testing.T) {
+	var ref string
+	var r *Rope
+	for i := 0; i < 2000; i++ {
+		switch rand.Intn(5) {
+		case 0:
+			i := rand.Intn(len(ref) + 1)
+			j := i + rand.Intn(len(ref)-i+1)
+			ref = ref[:i] + ref[j:]
+			r = r.Delete(i, j)
+		case 1:
+			i := rand.Intn(len(ref) + 1)
+			j := i + rand.Intn(len(ref)-i+1)
+			require.Equal(t, ref[i:j], r.Slice(i, j).String())
+		case 2:
+			s := strings.Repeat(fmt.Sprint(i%10), rand.Intn(2000))
+			ref = ref + s + ref
+			r = r.Concat(RopeFromString(s)).Concat(r)
+			if len(ref) > 100000 {
+				ref = ref[:len(ref)/2]
+				r = r.Slice(0, r.Len()/2)
+			}
+		default:
+			i := rand.Intn(len(ref) + 1)
+			s := strings.Repeat(fmt.Sprint(i%10), rand.Intn(100))
+			ref = ref[:i] + s + ref[i:]
+			r = r.Insert(i, s)
+		}
+		require.NoError(t, r.CheckInvariants())
+		require.Equal(t, len(ref), r.Len())
+		if len(ref) > 0 {
+			i := rand.Intn(len(ref))
+			require.Equal(t, ref[i], r.Index(i))
+		}
+	}
+	assert.Equal(t, ref, r.String())
+}
+
+var ropeResult *Rope
+
+func BenchmarkRope_Insert(b *testing.B) {
+	for _, n := range []int{100, 10000, 1000000} {
+		r := RopeFromString(strings.Repeat("x", n))
+		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ropeResult = r.Insert(i%n, "foo")
+			}
+		})
+	}
+}
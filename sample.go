@@ -0,0 +1,53 @@
+//go:build go1.22
+
+package immutable
+
+import (
+	"math/rand/v2"
+	"slices"
+)
+
+// Sample returns an element of the map chosen uniformly at random using rng, or nil if the map is
+// empty. Since each node knows the length of its subtree, this doesn't visit the other elements.
+// This is useful for load balancing and randomized eviction.
+//
+// Complexity: O(log n) worst-case
+func (m *OrderedMap[K, V]) Sample(rng *rand.Rand) *OrderedMapElement[K, V] {
+	if m.Empty() {
+		return nil
+	}
+	return m.elementAt(rng.IntN(m.Len()))
+}
+
+// SampleN returns n distinct elements of the map chosen uniformly at random using rng, in ascending
+// order of keys. If the map has n or fewer elements, all of them are returned.
+//
+// Complexity: O(k log n) worst-case, where k is the number of elements returned
+func (m *OrderedMap[K, V]) SampleN(rng *rand.Rand, n int) []*OrderedMapElement[K, V] {
+	size := m.Len()
+	if n > size {
+		n = size
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	// Robert Floyd's algorithm chooses n distinct indexes with exactly n random numbers.
+	chosen := make(map[int]struct{}, n)
+	indexes := make([]int, 0, n)
+	for j := size - n; j < size; j++ {
+		i := rng.IntN(j + 1)
+		if _, ok := chosen[i]; ok {
+			i = j
+		}
+		chosen[i] = struct{}{}
+		indexes = append(indexes, i)
+	}
+	slices.Sort(indexes)
+
+	ret := make([]*OrderedMapElement[K, V], n)
+	for k, i := range indexes {
+		ret[k] = m.elementAt(i)
+	}
+	return ret
+}
@@ -0,0 +1,82 @@
+//go:build go1.22
+
+package immutable
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedMap_Sample(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	var m *OrderedMap[int, int]
+	assert.Nil(t, m.Sample(rng))
+
+	for i := 0; i < 10; i++ {
+		m = m.Set(i, i*10)
+	}
+	counts := make([]int, 10)
+	for i := 0; i < 10000; i++ {
+		e := m.Sample(rng)
+		require.Equal(t, e.Key()*10, e.Value())
+		counts[e.Key()]++
+	}
+	for _, n := range counts {
+		// Each element is expected 1000 times, with a standard deviation of 30.
+		assert.InDelta(t, 1000, n, 200)
+	}
+
+	// Sampled elements can be used for iteration.
+	e := m.Sample(rng)
+	if next := e.Next(); next != nil {
+		assert.Equal(t, e.Key()+1, next.Key())
+	}
+}
+
+func TestOrderedMap_SampleN(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	var m *OrderedMap[int, int]
+	assert.Empty(t, m.SampleN(rng, 3))
+
+	for i := 0; i < 10; i++ {
+		m = m.Set(i, i)
+	}
+	assert.Empty(t, m.SampleN(rng, 0))
+	assert.Empty(t, m.SampleN(rng, -1))
+	assert.Len(t, m.SampleN(rng, 100), 10)
+
+	counts := make([]int, 10)
+	for i := 0; i < 5000; i++ {
+		sample := m.SampleN(rng, 3)
+		require.Len(t, sample, 3)
+		for j, e := range sample {
+			if j > 0 {
+				require.Less(t, sample[j-1].Key(), e.Key())
+			}
+			counts[e.Key()]++
+		}
+	}
+	for _, n := range counts {
+		// Each element is expected 1500 times, with a standard deviation of about 32.
+		assert.InDelta(t, 1500, n, 200)
+	}
+}
+
+var orderedMapElementResult *OrderedMapElement[int, string]
+
+func BenchmarkOrderedMap_Sample(b *testing.B) {
+	m := &OrderedMap[int, string]{}
+	for i := 0; i < 1000000; i++ {
+		m = m.Set(i, "foo")
+	}
+	rng := rand.New(rand.NewPCG(1, 2))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		orderedMapElementResult = m.Sample(rng)
+	}
+}
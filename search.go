@@ -0,0 +1,44 @@
+package immutable
+
+import "golang.org/x/exp/constraints"
+
+// BinarySearchFunc searches the view for target like slices.BinarySearchFunc, but without copying
+// the view's elements into a slice. It returns the position within the view where target is found,
+// or would be if it were inserted, and whether it was found.
+//
+// The view's elements must be in ascending order with respect to cmp, which returns a negative
+// number if the element precedes target, a positive number if it follows target, and zero if it
+// matches. Since elements are in ascending order of keys, this is always the case if cmp only
+// compares keys, but cmp may also compare values which increase along with keys, such as
+// cumulative totals or timestamps.
+//
+// Complexity: O(log n) worst-case
+func BinarySearchFunc[K constraints.Ordered, V, T any](s *OrderedSubMap[K, V], target T, cmp func(key K, value V, target T) int) (int, bool) {
+	if s == nil || s.m.Empty() {
+		return 0, false
+	}
+	m := s.m
+	lo := m.countLess(s.lo)
+	hi := m.countLess(s.hi)
+
+	// Descend to the first element of the view for which cmp isn't negative, using the ranks of
+	// nodes to steer around the elements outside of the view.
+	i := hi
+	found := false
+	for rank := 0; !m.Empty(); {
+		r := rank + m.left.Len()
+		if r < lo {
+			rank = r + 1
+			m = m.right
+		} else if r >= hi {
+			m = m.left
+		} else if c := cmp(m.key, m.value, target); c >= 0 {
+			i, found = r, c == 0
+			m = m.left
+		} else {
+			rank = r + 1
+			m = m.right
+		}
+	}
+	return i - lo, found
+}
@@ -0,0 +1,56 @@
+package immutable
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinarySearchFunc(t *testing.T) {
+	byKey := func(key int, value string, target int) int {
+		return key - target
+	}
+
+	i, found := BinarySearchFunc((*OrderedSubMap[int, string])(nil), 1, byKey)
+	assert.Equal(t, 0, i)
+	assert.False(t, found)
+
+	var m *OrderedMap[int, string]
+	var keys []int
+	for i := 0; i < 200; i++ {
+		k := rand.Intn(1000)
+		if _, ok := m.Get(k); !ok {
+			keys = append(keys, k)
+		}
+		m = m.Set(k, "")
+	}
+	sort.Ints(keys)
+
+	for n := 0; n < 100; n++ {
+		lo, hi := rand.Intn(1100)-50, rand.Intn(1100)-50
+		s := m.SubMap(lo, hi)
+		var view []int
+		for _, k := range keys {
+			if k >= lo && k < hi {
+				view = append(view, k)
+			}
+		}
+		for target := lo - 5; target < hi+5; target++ {
+			expectedIndex := sort.SearchInts(view, target)
+			expectedFound := expectedIndex < len(view) && view[expectedIndex] == target
+			i, found := BinarySearchFunc(s, target, byKey)
+			assert.Equal(t, expectedIndex, i, "lo=%v hi=%v target=%v", lo, hi, target)
+			assert.Equal(t, expectedFound, found, "lo=%v hi=%v target=%v", lo, hi, target)
+		}
+	}
+
+	// Values which increase with keys can also be searched.
+	totals := OrderedMapOf(MakePair("a", 3), MakePair("b", 5), MakePair("c", 9), MakePair("d", 10))
+	i, found = BinarySearchFunc(totals.SubMap("a", "z"), 6, func(key string, total int, target int) int {
+		return total - target
+	})
+	assert.Equal(t, 2, i)
+	assert.False(t, found)
+}
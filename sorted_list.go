@@ -0,0 +1,206 @@
+package immutable
+
+import (
+	"sort"
+
+	"golang.org/x/exp/constraints"
+)
+
+// SortedList implements a sorted collection of values which, unlike the keys of an OrderedMap, may
+// contain duplicates. Like OrderedMap, it doesn't support NaN values.
+//
+// It is backed by the same red-black tree as OrderedMap, so it also supports efficient rank queries
+// and access by index.
+//
+// Nil and the zero value for SortedList are both empty lists.
+type SortedList[T constraints.Ordered] struct {
+	m *OrderedMap[T, struct{}]
+}
+
+// SortedListFromSlice returns a new list containing the given values.
+//
+// Complexity: O(n log n) worst-case
+func SortedListFromSlice[T constraints.Ordered](values []T) *SortedList[T] {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := make([]T, len(values))
+	for i, value := range values {
+		orderedMapCheckKey(value)
+		sorted[i] = value
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	return &SortedList[T]{
+		m: orderedMapFromSorted(sorted, make([]struct{}, len(sorted)), false),
+	}
+}
+
+func newSortedList[T constraints.Ordered](m *OrderedMap[T, struct{}]) *SortedList[T] {
+	if m.Empty() {
+		return nil
+	}
+	return &SortedList[T]{
+		m: m,
+	}
+}
+
+// Empty returns true if the list is empty.
+//
+// Complexity: O(1) worst-case
+func (l *SortedList[T]) Empty() bool {
+	return l == nil || l.m.Empty()
+}
+
+// Len returns the number of values in the list, including duplicates.
+//
+// Complexity: O(1) worst-case
+func (l *SortedList[T]) Len() int {
+	if l == nil {
+		return 0
+	}
+	return l.m.Len()
+}
+
+// Insert adds a value to the list. If equal values are already present, it is added after them.
+//
+// Complexity: O(log n) worst-case
+func (l *SortedList[T]) Insert(value T) *SortedList[T] {
+	orderedMapCheckKey(value)
+	var m *OrderedMap[T, struct{}]
+	if l != nil {
+		m = l.m
+	}
+	ret := m.insertDuplicate(value, struct{}{})
+	ret.setColor(orderedMapBlack)
+	return newSortedList(ret)
+}
+
+// DeleteOne removes a single occurrence of the given value from the list.
+//
+// Complexity: O(log n) worst-case
+func (l *SortedList[T]) DeleteOne(value T) *SortedList[T] {
+	if l.Empty() || value != value {
+		return l
+	}
+	ret, didDelete := l.m.delete(value)
+	if !didDelete {
+		return l
+	} else if !ret.Empty() {
+		ret.setColor(orderedMapBlack)
+	}
+	return newSortedList(ret)
+}
+
+// DeleteAll removes every occurrence of the given value from the list.
+//
+// Complexity: O(log n) worst-case
+func (l *SortedList[T]) DeleteAll(value T) *SortedList[T] {
+	if l.Count(value) == 0 {
+		return l
+	}
+	left, rest := l.m.splitLess(value)
+	_, right := rest.splitLessOrEqual(value)
+	return newSortedList(orderedMapJoin2(left, right))
+}
+
+// Contains returns true if the list contains at least one occurrence of the given value.
+//
+// Complexity: O(log n) worst-case
+func (l *SortedList[T]) Contains(value T) bool {
+	if l == nil {
+		return false
+	}
+	return l.m.Contains(value)
+}
+
+// Count returns the number of occurrences of the given value in the list.
+//
+// Complexity: O(log n) worst-case
+func (l *SortedList[T]) Count(value T) int {
+	if value != value {
+		return 0
+	}
+	return l.CountLessOrEqual(value) - l.CountLess(value)
+}
+
+// CountLess returns the number of values in the list that are less than the given value. This is
+// the index at which the first occurrence of the value would be found.
+//
+// Complexity: O(log n) worst-case
+func (l *SortedList[T]) CountLess(value T) int {
+	if l == nil {
+		return 0
+	}
+	return l.m.countLess(value)
+}
+
+// CountLessOrEqual returns the number of values in the list that are less than or equal to the given
+// value.
+//
+// Complexity: O(log n) worst-case
+func (l *SortedList[T]) CountLessOrEqual(value T) int {
+	if l == nil {
+		return 0
+	}
+	return l.m.countLessOrEqual(value)
+}
+
+// At returns the value at the given index. It panics if the index is out of range.
+//
+// Complexity: O(log n) worst-case
+func (l *SortedList[T]) At(i int) T {
+	if i < 0 || i >= l.Len() {
+		panic("immutable: SortedList index out of range")
+	}
+	return l.m.at(i).key
+}
+
+// Min returns the smallest value in the list. It panics if the list is empty.
+//
+// Complexity: O(log n) worst-case
+func (l *SortedList[T]) Min() T {
+	return l.At(0)
+}
+
+// Max returns the greatest value in the list. It panics if the list is empty.
+//
+// Complexity: O(log n) worst-case
+func (l *SortedList[T]) Max() T {
+	return l.At(l.Len() - 1)
+}
+
+// ToSlice returns the values in the list as a slice, in ascending order.
+//
+// Complexity: O(n) worst-case
+func (l *SortedList[T]) ToSlice() []T {
+	if l.Empty() {
+		return nil
+	}
+	ret := make([]T, 0, l.Len())
+	var appendValues func(m *OrderedMap[T, struct{}])
+	appendValues = func(m *OrderedMap[T, struct{}]) {
+		if m.Empty() {
+			return
+		}
+		appendValues(m.left)
+		ret = append(ret, m.key)
+		appendValues(m.right)
+	}
+	appendValues(l.m)
+	return ret
+}
+
+// CheckInvariants verifies the structural integrity of the list, returning an error describing the
+// first problem found. It checks the red-black invariants of the underlying tree and that its items
+// are in non-descending order.
+//
+// Complexity: O(n) worst-case
+func (l *SortedList[T]) CheckInvariants() error {
+	if l.Empty() {
+		return nil
+	}
+	_, err := l.m.checkInvariants(nil, nil, true)
+	return err
+}
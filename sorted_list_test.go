@@ -0,0 +1,81 @@
+package immutable
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortedList(t *testing.T) {
+	var l *SortedList[int]
+	assert.True(t, l.Empty())
+	assert.Equal(t, 0, l.Len())
+	assert.False(t, l.Contains(1))
+	assert.Equal(t, 0, l.Count(1))
+	assert.Nil(t, l.DeleteOne(1))
+	assert.Nil(t, l.DeleteAll(1))
+	assert.Panics(t, func() { l.At(0) })
+
+	l2 := l.Insert(2).Insert(1).Insert(2).Insert(3).Insert(2)
+	assert.True(t, l.Empty())
+	assert.Equal(t, []int{1, 2, 2, 2, 3}, l2.ToSlice())
+	assert.Equal(t, 3, l2.Count(2))
+	assert.Equal(t, 1, l2.CountLess(2))
+	assert.Equal(t, 4, l2.CountLessOrEqual(2))
+	assert.Equal(t, 1, l2.Min())
+	assert.Equal(t, 3, l2.Max())
+	assert.Equal(t, 2, l2.At(3))
+	assert.Panics(t, func() { l2.At(5) })
+
+	assert.Equal(t, []int{1, 2, 2, 3}, l2.DeleteOne(2).ToSlice())
+	assert.Equal(t, []int{1, 3}, l2.DeleteAll(2).ToSlice())
+	assert.Equal(t, l2, l2.DeleteOne(4))
+	assert.Equal(t, l2, l2.DeleteAll(4))
+	assert.True(t, l2.DeleteAll(1).DeleteAll(2).DeleteAll(3).Empty())
+}
+
+func TestSortedListFromSlice(t *testing.T) {
+	assert.True(t, SortedListFromSlice[int](nil).Empty())
+
+	values := []int{5, 3, 3, 1, 5, 5, 2}
+	l := SortedListFromSlice(values)
+	require.NoError(t, l.CheckInvariants())
+	assert.Equal(t, []int{1, 2, 3, 3, 5, 5, 5}, l.ToSlice())
+	assert.Equal(t, []int{5, 3, 3, 1, 5, 5, 2}, values)
+	assert.Equal(t, []int{1, 2, 3, 3, 4, 5, 5, 5}, l.Insert(4).ToSlice())
+}
+
+func TestSortedList_Fuzz(t *testing.T) {
+	var ref []int
+	var l *SortedList[int]
+	for i := 0; i < 5000; i++ {
+		v := rand.Intn(50)
+		switch rand.Intn(5) {
+		case 0:
+			if n := sort.SearchInts(ref, v); n < len(ref) && ref[n] == v {
+				ref = append(ref[:n], ref[n+1:]...)
+			}
+			l = l.DeleteOne(v)
+		case 1:
+			lo, hi := sort.SearchInts(ref, v), sort.SearchInts(ref, v+1)
+			ref = append(ref[:lo], ref[hi:]...)
+			l = l.DeleteAll(v)
+		default:
+			n := sort.SearchInts(ref, v)
+			ref = append(ref[:n], append([]int{v}, ref[n:]...)...)
+			l = l.Insert(v)
+		}
+		require.NoError(t, l.CheckInvariants())
+		require.Equal(t, len(ref), l.Len())
+		require.Equal(t, sort.SearchInts(ref, v), l.CountLess(v))
+		require.Equal(t, sort.SearchInts(ref, v+1), l.CountLessOrEqual(v))
+		if len(ref) > 0 {
+			k := rand.Intn(len(ref))
+			require.Equal(t, ref[k], l.At(k))
+		}
+	}
+	assert.Equal(t, ref, l.ToSlice())
+}
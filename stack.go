@@ -1,5 +1,7 @@
 package immutable
 
+import "unsafe"
+
 // Stack implements a last in, first out container.
 //
 // Nil and the zero value for Stack are both empty stacks.
@@ -8,6 +10,24 @@ type Stack[T any] struct {
 	bottom *Stack[T]
 }
 
+// StackFromSlice returns a new stack containing the given items. The first item will be on top.
+//
+// Complexity: O(n) worst-case
+func StackFromSlice[T any](items []T) *Stack[T] {
+	var ret *Stack[T]
+	for i := len(items) - 1; i >= 0; i-- {
+		ret = ret.Push(items[i])
+	}
+	return ret
+}
+
+// StackOf returns a new stack containing the given items. The first item will be on top.
+//
+// Complexity: O(n) worst-case
+func StackOf[T any](items ...T) *Stack[T] {
+	return StackFromSlice(items)
+}
+
 // Empty returns true if the stack is empty.
 //
 // Complexity: O(1) worst-case
@@ -15,17 +35,34 @@ func (s *Stack[T]) Empty() bool {
 	return s == nil || s.bottom == nil
 }
 
-// Peek returns the top item on the stack.
+// Peek returns the top item on the stack. It panics if the stack is empty.
 //
 // Complexity: O(1) worst-case
 func (s *Stack[T]) Peek() T {
+	if s.Empty() {
+		panic("immutable: Stack is empty")
+	}
 	return s.top
 }
 
-// Pop removes the top item from the stack.
+// TryPeek returns the top item on the stack, or false if the stack is empty.
+//
+// Complexity: O(1) worst-case
+func (s *Stack[T]) TryPeek() (T, bool) {
+	if s.Empty() {
+		var zero T
+		return zero, false
+	}
+	return s.top, true
+}
+
+// Pop removes the top item from the stack. It panics if the stack is empty.
 //
 // Complexity: O(1) worst-case
 func (s *Stack[T]) Pop() *Stack[T] {
+	if s.Empty() {
+		panic("immutable: Stack is empty")
+	}
 	return s.bottom
 }
 
@@ -41,3 +78,154 @@ func (s *Stack[T]) Push(value T) *Stack[T] {
 		bottom: s,
 	}
 }
+
+// PushAll places the given items onto the top of the stack in order, so the last item ends up on
+// top. This is equivalent to calling Push for each item, but allocates all of the new nodes at once.
+//
+// Complexity: O(m) worst-case, where m is the number of items
+func (s *Stack[T]) PushAll(values ...T) *Stack[T] {
+	if len(values) == 0 {
+		return s
+	} else if s == nil {
+		s = &Stack[T]{}
+	}
+	nodes := make([]Stack[T], len(values))
+	for i, value := range values {
+		nodes[i] = Stack[T]{
+			top:    value,
+			bottom: s,
+		}
+		s = &nodes[i]
+	}
+	return s
+}
+
+// PopN removes up to n items from the top of the stack, returning them starting with the top item.
+// If the stack has fewer than n items, all of them are removed.
+//
+// Complexity: O(n) worst-case
+func (s *Stack[T]) PopN(n int) ([]T, *Stack[T]) {
+	var ret []T
+	for ; len(ret) < n && !s.Empty(); s = s.Pop() {
+		ret = append(ret, s.Peek())
+	}
+	return ret, s
+}
+
+// ToSlice returns the items in the stack as a slice, starting with the top item.
+//
+// Complexity: O(n) worst-case
+func (s *Stack[T]) ToSlice() []T {
+	var ret []T
+	for ; !s.Empty(); s = s.Pop() {
+		ret = append(ret, s.Peek())
+	}
+	return ret
+}
+
+// Equal returns true if the stacks contain the same items in the same order, comparing items with
+// eq. Once the stacks reach a shared node, such as when one was derived from the other, the rest of
+// their items are known to be equal without being compared.
+//
+// Complexity: O(n) worst-case, but shared nodes are not traversed
+func (s *Stack[T]) Equal(other *Stack[T], eq func(a, b T) bool) bool {
+	for ; s != other; s, other = s.Pop(), other.Pop() {
+		if s.Empty() || other.Empty() {
+			return s.Empty() && other.Empty()
+		} else if !eq(s.top, other.top) {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains returns true if the stack contains an item equal to the given one according to eq.
+//
+// Complexity: O(n) worst-case
+func (s *Stack[T]) Contains(value T, eq func(a, b T) bool) bool {
+	for ; !s.Empty(); s = s.Pop() {
+		if eq(s.top, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the number of items in the stack equal to the given one according to eq.
+//
+// Complexity: O(n) worst-case
+func (s *Stack[T]) Count(value T, eq func(a, b T) bool) int {
+	n := 0
+	for ; !s.Empty(); s = s.Pop() {
+		if eq(s.top, value) {
+			n++
+		}
+	}
+	return n
+}
+
+// CountMemory adds the stack's nodes to the counter. Nodes which were already counted, such as those
+// shared with previously counted stacks, are skipped along with the rest of the stack beneath them.
+//
+// Complexity: O(n) worst-case, but nodes beneath already counted ones are not traversed
+func (s *Stack[T]) CountMemory(c *MemoryCounter) {
+	for ; !s.Empty() && c.visit(unsafe.Pointer(s), unsafe.Sizeof(*s)); s = s.Pop() {
+	}
+}
+
+// length returns the number of items in the stack.
+func (s *Stack[T]) length() int {
+	n := 0
+	for ; !s.Empty(); s = s.Pop() {
+		n++
+	}
+	return n
+}
+
+// CommonSuffix returns the longest stack of nodes shared by this stack and the other, i.e. the
+// version from which both were derived by popping and pushing. If the stacks share no nodes, the
+// result is empty.
+//
+// Items are compared by identity rather than value, so stacks which were built independently have
+// no common suffix even if their items are equal.
+//
+// Complexity: O(n + m) worst-case
+func (s *Stack[T]) CommonSuffix(other *Stack[T]) *Stack[T] {
+	n, m := s.length(), other.length()
+	for ; n > m; n-- {
+		s = s.Pop()
+	}
+	for ; m > n; m-- {
+		other = other.Pop()
+	}
+	for s != other && !s.Empty() {
+		s, other = s.Pop(), other.Pop()
+	}
+	if s.Empty() {
+		return nil
+	}
+	return s
+}
+
+// Concat returns a stack with the items of this stack placed on top of the items of the other stack.
+// The other stack's structure is shared with the result.
+//
+// Complexity: O(n) worst-case, where n is the size of this stack
+func (s *Stack[T]) Concat(other *Stack[T]) *Stack[T] {
+	items := s.ToSlice()
+	for i := len(items) - 1; i >= 0; i-- {
+		other = other.Push(items[i])
+	}
+	return other
+}
+
+// Reverse returns a stack with the same items in the opposite order.
+//
+// Complexity: O(n) worst-case
+func (s *Stack[T]) Reverse() *Stack[T] {
+	var ret *Stack[T]
+	for ; !s.Empty(); s = s.Pop() {
+		ret = ret.Push(s.Peek())
+	}
+	return ret
+}
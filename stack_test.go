@@ -17,3 +17,133 @@ func TestStack(t *testing.T) {
 	assert.Equal(t, s3.Peek(), "bar")
 	assert.Equal(t, s3.Pop().Peek(), "foo")
 }
+
+func TestStack_TryPeek(t *testing.T) {
+	var s *Stack[int]
+	_, ok := s.TryPeek()
+	assert.False(t, ok)
+	assert.PanicsWithValue(t, "immutable: Stack is empty", func() { s.Peek() })
+	assert.PanicsWithValue(t, "immutable: Stack is empty", func() { s.Push(1).Pop().Peek() })
+	assert.PanicsWithValue(t, "immutable: Stack is empty", func() { s.Pop() })
+	assert.PanicsWithValue(t, "immutable: Stack is empty", func() { s.Push(1).Pop().Pop() })
+	assert.PanicsWithValue(t, "immutable: Stack is empty", func() { (&Stack[int]{}).Pop() })
+
+	v, ok := s.Push(1).TryPeek()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+}
+
+func TestStackFromSlice(t *testing.T) {
+	assert.True(t, StackFromSlice[int](nil).Empty())
+	assert.Empty(t, StackFromSlice[int](nil).ToSlice())
+
+	s := StackFromSlice([]int{1, 2, 3})
+	assert.Equal(t, 1, s.Peek())
+	assert.Equal(t, []int{1, 2, 3}, s.ToSlice())
+	assert.Equal(t, []int{0, 1, 2, 3}, s.Push(0).ToSlice())
+}
+
+func TestStackOf(t *testing.T) {
+	assert.True(t, StackOf[int]().Empty())
+	assert.Equal(t, []int{1, 2, 3}, StackOf(1, 2, 3).ToSlice())
+	assert.Equal(t, 1, StackOf(1, 2, 3).Peek())
+}
+
+func TestStack_Concat(t *testing.T) {
+	var s *Stack[int]
+	assert.True(t, s.Concat(nil).Empty())
+	assert.Equal(t, []int{1, 2}, s.Concat(StackFromSlice([]int{1, 2})).ToSlice())
+	assert.Equal(t, []int{1, 2}, StackFromSlice([]int{1, 2}).Concat(nil).ToSlice())
+	assert.Equal(t, []int{1, 2, 3, 4}, StackFromSlice([]int{1, 2}).Concat(StackFromSlice([]int{3, 4})).ToSlice())
+	assert.Equal(t, []int{2, 3, 4}, StackFromSlice([]int{1, 2}).Concat(StackFromSlice([]int{3, 4})).Pop().ToSlice())
+}
+
+func TestStack_Reverse(t *testing.T) {
+	var s *Stack[int]
+	assert.True(t, s.Reverse().Empty())
+	assert.Equal(t, []int{3, 2, 1}, StackFromSlice([]int{1, 2, 3}).Reverse().ToSlice())
+	assert.Equal(t, []int{0, 3, 2, 1}, StackFromSlice([]int{1, 2, 3}).Reverse().Push(0).ToSlice())
+}
+
+func TestStack_PushAll(t *testing.T) {
+	var s *Stack[int]
+	assert.True(t, s.PushAll().Empty())
+	s = s.PushAll(1, 2, 3)
+	assert.Equal(t, []int{3, 2, 1}, s.ToSlice())
+	assert.Equal(t, []int{5, 4, 3, 2, 1}, s.PushAll(4, 5).ToSlice())
+	assert.Equal(t, []int{3, 2, 1}, s.ToSlice())
+	assert.Equal(t, s.Push(4).Push(5).ToSlice(), s.PushAll(4, 5).ToSlice())
+}
+
+func TestStack_PopN(t *testing.T) {
+	var s *Stack[int]
+	items, rest := s.PopN(2)
+	assert.Empty(t, items)
+	assert.True(t, rest.Empty())
+
+	s = StackFromSlice([]int{1, 2, 3})
+	items, rest = s.PopN(2)
+	assert.Equal(t, []int{1, 2}, items)
+	assert.Equal(t, []int{3}, rest.ToSlice())
+
+	items, rest = s.PopN(5)
+	assert.Equal(t, []int{1, 2, 3}, items)
+	assert.True(t, rest.Empty())
+
+	items, rest = s.PopN(0)
+	assert.Empty(t, items)
+	assert.Equal(t, s, rest)
+}
+
+func TestStack_Contains(t *testing.T) {
+	eq := func(a, b int) bool {
+		return a == b
+	}
+	var s *Stack[int]
+	assert.False(t, s.Contains(1, eq))
+	assert.Equal(t, 0, s.Count(1, eq))
+
+	s = StackOf(1, 2, 1, 3)
+	assert.True(t, s.Contains(1, eq))
+	assert.True(t, s.Contains(3, eq))
+	assert.False(t, s.Contains(4, eq))
+	assert.Equal(t, 2, s.Count(1, eq))
+	assert.Equal(t, 1, s.Count(2, eq))
+	assert.Equal(t, 0, s.Count(4, eq))
+}
+
+func TestStack_Equal(t *testing.T) {
+	eq := func(a, b int) bool {
+		return a == b
+	}
+	var empty *Stack[int]
+	assert.True(t, empty.Equal(nil, eq))
+	assert.True(t, empty.Equal(&Stack[int]{}, eq))
+	assert.True(t, StackOf(1, 2).Equal(StackOf(1, 2), eq))
+	assert.False(t, StackOf(1, 2).Equal(StackOf(1, 3), eq))
+	assert.False(t, StackOf(1, 2).Equal(StackOf(1), eq))
+	assert.False(t, StackOf(1).Equal(StackOf(1, 2), eq))
+	assert.False(t, StackOf(1).Equal(nil, eq))
+
+	// Shared nodes aren't compared.
+	base := StackOf(1, 2, 3)
+	calls := 0
+	assert.True(t, base.Push(4).Equal(base.Push(4), func(a, b int) bool {
+		calls++
+		return a == b
+	}))
+	assert.Equal(t, 1, calls)
+}
+
+func TestStack_CommonSuffix(t *testing.T) {
+	var empty *Stack[int]
+	assert.Nil(t, empty.CommonSuffix(nil))
+	assert.Nil(t, StackOf(1, 2).CommonSuffix(StackOf(1, 2)))
+
+	base := StackOf(1, 2, 3)
+	assert.Same(t, base, base.CommonSuffix(base))
+	assert.Same(t, base, base.Push(4).Push(5).CommonSuffix(base.Push(6)))
+	assert.Same(t, base.Pop(), base.Pop().Push(7).CommonSuffix(base.Push(8)))
+	assert.Same(t, base, base.CommonSuffix(base.Push(9)))
+	assert.Nil(t, base.CommonSuffix(nil))
+}
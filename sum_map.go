@@ -0,0 +1,113 @@
+package immutable
+
+import "golang.org/x/exp/constraints"
+
+// SumMap implements a map with numeric values which can efficiently compute the sum of the values
+// in any range of keys. It is an AugmentedMap whose aggregate is the sum of the values.
+//
+// Like OrderedMap, SumMap doesn't support NaN keys. Floating point sums are subject to rounding, and
+// may differ slightly depending on the structure of the map.
+//
+// Nil and the zero value for SumMap are both empty maps.
+type SumMap[K constraints.Ordered, V constraints.Integer | constraints.Float] struct {
+	m *AugmentedMap[K, V, V]
+}
+
+func (s *SumMap[K, V]) augmented() *AugmentedMap[K, V, V] {
+	if s == nil || s.m == nil {
+		return NewAugmentedMap(func(key K, value V) V {
+			return value
+		}, func(a, b V) V {
+			return a + b
+		}, 0)
+	}
+	return s.m
+}
+
+// Empty returns true if the map is empty.
+//
+// Complexity: O(1) worst-case
+func (s *SumMap[K, V]) Empty() bool {
+	return s.Len() == 0
+}
+
+// Len returns the number of elements in the map.
+//
+// Complexity: O(1) worst-case
+func (s *SumMap[K, V]) Len() int {
+	if s == nil || s.m == nil {
+		return 0
+	}
+	return s.m.Len()
+}
+
+// Get returns the value associated with the given key if set.
+//
+// Complexity: O(log n) worst-case
+func (s *SumMap[K, V]) Get(key K) (V, bool) {
+	if s.Empty() {
+		return 0, false
+	}
+	return s.m.Get(key)
+}
+
+// Set associates a value with the given key. It panics if the key is NaN.
+//
+// Complexity: O(log n) worst-case
+func (s *SumMap[K, V]) Set(key K, value V) *SumMap[K, V] {
+	return &SumMap[K, V]{
+		m: s.augmented().Set(key, value),
+	}
+}
+
+// Delete removes a key from the map.
+//
+// Complexity: O(log n) worst-case
+func (s *SumMap[K, V]) Delete(key K) *SumMap[K, V] {
+	if _, ok := s.Get(key); !ok {
+		return s
+	}
+	return &SumMap[K, V]{
+		m: s.m.Delete(key),
+	}
+}
+
+// Sum returns the sum of every value in the map.
+//
+// Complexity: O(1) worst-case
+func (s *SumMap[K, V]) Sum() V {
+	if s.Empty() {
+		return 0
+	}
+	return s.m.Aggregate()
+}
+
+// SumLess returns the sum of the values with keys less than the given key.
+//
+// Complexity: O(log n) worst-case
+func (s *SumMap[K, V]) SumLess(key K) V {
+	if s.Empty() {
+		return 0
+	}
+	return s.m.AggregateLess(key)
+}
+
+// SumRange returns the sum of the values with keys greater than or equal to lo and less than hi.
+//
+// Complexity: O(log n) worst-case
+func (s *SumMap[K, V]) SumRange(lo, hi K) V {
+	if s.Empty() {
+		return 0
+	}
+	return s.m.AggregateRange(lo, hi)
+}
+
+// ForEach calls f for each element in the map in ascending order of keys, stopping early if f
+// returns false.
+//
+// Complexity: O(n) worst-case
+func (s *SumMap[K, V]) ForEach(f func(key K, value V) bool) {
+	if !s.Empty() {
+		s.m.ForEach(f)
+	}
+}
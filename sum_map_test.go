@@ -0,0 +1,63 @@
+package immutable
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSumMap(t *testing.T) {
+	var m *SumMap[string, float64]
+	assert.True(t, m.Empty())
+	assert.Equal(t, 0.0, m.Sum())
+	assert.Equal(t, 0.0, m.SumLess("z"))
+	assert.Equal(t, 0.0, m.SumRange("a", "z"))
+	_, ok := m.Get("a")
+	assert.False(t, ok)
+	assert.Nil(t, m.Delete("a"))
+	m.ForEach(func(key string, value float64) bool {
+		t.Fatal("empty maps should have no elements")
+		return true
+	})
+
+	m = m.Set("a", 1).Set("b", 2).Set("c", 4).Set("d", 8)
+	assert.Equal(t, 4, m.Len())
+	assert.Equal(t, 15.0, m.Sum())
+	assert.Equal(t, 3.0, m.SumLess("c"))
+	assert.Equal(t, 6.0, m.SumRange("b", "d"))
+	v, ok := m.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 4.0, v)
+
+	m2 := m.Delete("b").Set("c", 0.5)
+	assert.Equal(t, 9.5, m2.Sum())
+	assert.Equal(t, 15.0, m.Sum())
+
+	var zero SumMap[int, int]
+	assert.Equal(t, 5, zero.Set(1, 5).Sum())
+}
+
+func TestSumMap_Fuzz(t *testing.T) {
+	var m *SumMap[int, int]
+	ref := map[int]int{}
+	for i := 0; i < 1000; i++ {
+		key := rand.Intn(100)
+		if rand.Intn(3) == 0 {
+			m = m.Delete(key)
+			delete(ref, key)
+		} else {
+			m = m.Set(key, rand.Intn(1000)-500)
+			ref[key], _ = m.Get(key)
+		}
+		hi := rand.Intn(110)
+		expected := 0
+		for k, v := range ref {
+			if k < hi {
+				expected += v
+			}
+		}
+		require.Equal(t, expected, m.SumLess(hi))
+	}
+}
@@ -0,0 +1,8 @@
+// Package fuzz contains fuzz targets for the containers of package immutable. Each target applies
+// a sequence of operations decoded from the fuzzer's input to a container and to a simple model of
+// it, checking the container's invariants after every operation.
+//
+// Run a target with, for example:
+//
+//	go test ./testing/fuzz -run '^$' -fuzz FuzzOrderedMap
+package fuzz
@@ -0,0 +1,58 @@
+package fuzz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ccbrown/go-immutable"
+)
+
+func FuzzOrderedMap(f *testing.F) {
+	f.Add([]byte{1, 2, 3, 4, 5, 129, 130, 3, 4})
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		ref := make(map[byte]int)
+		var m *immutable.OrderedMap[byte, int]
+		for i, op := range ops {
+			// The high bit of each op determines whether it's a deletion.
+			k := op & 0x7f
+			if op&0x80 != 0 {
+				delete(ref, k)
+				m = m.Delete(k)
+			} else {
+				ref[k] = i
+				m = m.Set(k, i)
+			}
+			require.NoError(t, m.CheckInvariants())
+			require.Equal(t, len(ref), m.Len())
+		}
+		assert.Equal(t, ref, m.ToMap())
+	})
+}
+
+func FuzzQueue(f *testing.F) {
+	f.Add([]byte{0, 1, 2, 0, 0, 1, 2, 2})
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		var ref []byte
+		q := &immutable.Queue[byte]{}
+		for i, op := range ops {
+			switch op % 3 {
+			case 0:
+				ref = append(ref, byte(i))
+				q = q.PushBack(byte(i))
+			case 1:
+				ref = append([]byte{byte(i)}, ref...)
+				q = q.PushFront(byte(i))
+			default:
+				if len(ref) > 0 {
+					require.Equal(t, ref[0], q.Front())
+					ref = ref[1:]
+					q = q.PopFront()
+				}
+			}
+			require.NoError(t, q.CheckInvariants())
+			require.Equal(t, len(ref) == 0, q.Empty())
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package immutable
+
+import (
+	"fmt"
+	"sort"
+
+	"golang.org/x/exp/constraints"
+)
+
+// TopK implements a collection with a fixed capacity which retains only the best items pushed to
+// it. Depending on how it's created, the best items are either the largest or the smallest, or the
+// greatest according to a comparison function. This is useful for leaderboards and for keeping
+// samples of extreme values from a stream. When created with NewTopK or NewBottomK, like
+// OrderedMap, it doesn't support NaN values.
+//
+// When an item is pushed to a full collection, it replaces the worst item only if it's strictly
+// better, so among equal items, the earliest pushed are retained.
+//
+// Items are stored in an AugmentedMap keyed by the order in which they were pushed, which keeps
+// track of the best and worst items.
+//
+// TopK should be created with NewTopK, NewBottomK, or NewTopKFunc. Nil and the zero value for TopK
+// are both empty collections with a capacity of zero, which admit no items.
+type TopK[T any] struct {
+	m        *AugmentedMap[uint64, T, topKBounds[T]]
+	next     uint64
+	capacity int
+	cmp      func(a, b T) int
+	check    func(value T)
+}
+
+// topKBounds is the aggregate of a range of a TopK's items: the best and worst of them, along with
+// the keys they're stored under.
+type topKBounds[T any] struct {
+	best, worst       T
+	bestKey, worstKey uint64
+	ok                bool
+}
+
+// NewTopK returns an empty collection which retains the k largest items. It panics if k is not
+// positive.
+//
+// Complexity: O(1) worst-case
+func NewTopK[T constraints.Ordered](k int) *TopK[T] {
+	ret := NewTopKFunc(k, topKCompare[T])
+	ret.check = orderedMapCheckKey[T]
+	return ret
+}
+
+// NewBottomK returns an empty collection which retains the k smallest items. It panics if k is not
+// positive.
+//
+// Complexity: O(1) worst-case
+func NewBottomK[T constraints.Ordered](k int) *TopK[T] {
+	ret := NewTopKFunc(k, func(a, b T) int {
+		return topKCompare(b, a)
+	})
+	ret.check = orderedMapCheckKey[T]
+	return ret
+}
+
+// NewTopKFunc returns an empty collection which retains the k greatest items according to cmp,
+// which must return a negative number if a is less than b, a positive number if a is greater than
+// b, and zero if they're equal. To retain the k least items, reverse the comparison. It panics if k
+// is not positive.
+//
+// Complexity: O(1) worst-case
+func NewTopKFunc[T any](k int, cmp func(a, b T) int) *TopK[T] {
+	if k < 1 {
+		panic("immutable: TopK requires a positive capacity")
+	}
+	return &TopK[T]{
+		m: NewAugmentedMap(func(key uint64, value T) topKBounds[T] {
+			return topKBounds[T]{
+				best:     value,
+				worst:    value,
+				bestKey:  key,
+				worstKey: key,
+				ok:       true,
+			}
+		}, func(a, b topKBounds[T]) topKBounds[T] {
+			if !a.ok {
+				return b
+			} else if !b.ok {
+				return a
+			}
+			// a holds earlier items than b, so ties favor a for the best item and b for the worst,
+			// which is evicted first.
+			ret := a
+			if cmp(b.best, a.best) > 0 {
+				ret.best, ret.bestKey = b.best, b.bestKey
+			}
+			if cmp(b.worst, a.worst) <= 0 {
+				ret.worst, ret.worstKey = b.worst, b.worstKey
+			}
+			return ret
+		}, topKBounds[T]{}),
+		capacity: k,
+		cmp:      cmp,
+	}
+}
+
+func topKCompare[T constraints.Ordered](a, b T) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func (t *TopK[T]) items() *AugmentedMap[uint64, T, topKBounds[T]] {
+	if t == nil {
+		return nil
+	}
+	return t.m
+}
+
+// Len returns the number of items in the collection.
+//
+// Complexity: O(1) worst-case
+func (t *TopK[T]) Len() int {
+	return t.items().Len()
+}
+
+// Cap returns the maximum number of items the collection retains.
+//
+// Complexity: O(1) worst-case
+func (t *TopK[T]) Cap() int {
+	if t == nil {
+		return 0
+	}
+	return t.capacity
+}
+
+// Push offers an item to the collection. It returns the new collection, and true if the item was
+// admitted, possibly displacing the worst item. If the item wasn't admitted, the collection is
+// returned unchanged. If the collection was created with NewTopK or NewBottomK, it panics if the
+// item is NaN.
+//
+// Complexity: O(log k) worst-case
+func (t *TopK[T]) Push(value T) (*TopK[T], bool) {
+	if t == nil || t.capacity < 1 {
+		return t, false
+	}
+	if t.check != nil {
+		t.check(value)
+	}
+	m := t.m
+	if m.Len() >= t.capacity {
+		bounds := m.Aggregate()
+		if t.cmp(value, bounds.worst) <= 0 {
+			return t, false
+		}
+		m = m.Delete(bounds.worstKey)
+	}
+	ret := *t
+	ret.m = m.Set(ret.next, value)
+	ret.next++
+	return &ret, true
+}
+
+// Best returns the best item in the collection, or false if the collection is empty.
+//
+// Complexity: O(1) worst-case
+func (t *TopK[T]) Best() (T, bool) {
+	bounds := t.items().Aggregate()
+	return bounds.best, bounds.ok
+}
+
+// Worst returns the worst item in the collection, or false if the collection is empty. Once the
+// collection is full, items must be better than this to be admitted.
+//
+// Complexity: O(1) worst-case
+func (t *TopK[T]) Worst() (T, bool) {
+	bounds := t.items().Aggregate()
+	return bounds.worst, bounds.ok
+}
+
+// ToSlice returns the items in the collection as a slice, starting with the best. Equal items are
+// in the order they were pushed.
+//
+// Complexity: O(k log k) worst-case
+func (t *TopK[T]) ToSlice() []T {
+	var ret []T
+	t.items().ForEach(func(_ uint64, value T) bool {
+		ret = append(ret, value)
+		return true
+	})
+	sort.SliceStable(ret, func(i, j int) bool {
+		return t.cmp(ret[i], ret[j]) > 0
+	})
+	return ret
+}
+
+// CheckInvariants verifies the structural integrity of the collection, returning an error
+// describing the first problem found. It checks the underlying map, that the best and worst items
+// it keeps track of are stored in it and bound every other item, and that the collection is within
+// its capacity.
+//
+// Complexity: O(k) worst-case
+func (t *TopK[T]) CheckInvariants() error {
+	if t == nil {
+		return nil
+	} else if err := t.m.CheckInvariants(); err != nil {
+		return err
+	} else if t.m.Len() > t.capacity {
+		return fmt.Errorf("collection has %v items, but a capacity of %v", t.m.Len(), t.capacity)
+	}
+	bounds := t.m.Aggregate()
+	if bounds.ok != !t.m.Empty() {
+		return fmt.Errorf("collection has %v items, but its bounds are inconsistent", t.m.Len())
+	} else if !bounds.ok {
+		return nil
+	} else if best, ok := t.m.Get(bounds.bestKey); !ok || t.cmp(best, bounds.best) != 0 {
+		return fmt.Errorf("best item isn't stored under %v", bounds.bestKey)
+	} else if worst, ok := t.m.Get(bounds.worstKey); !ok || t.cmp(worst, bounds.worst) != 0 {
+		return fmt.Errorf("worst item isn't stored under %v", bounds.worstKey)
+	}
+	var err error
+	t.m.ForEach(func(key uint64, value T) bool {
+		if key >= t.next {
+			err = fmt.Errorf("item is stored under %v, but the next key is %v", key, t.next)
+		} else if t.cmp(value, bounds.best) > 0 {
+			err = fmt.Errorf("item stored under %v is better than the best item", key)
+		} else if t.cmp(value, bounds.worst) < 0 {
+			err = fmt.Errorf("item stored under %v is worse than the worst item", key)
+		}
+		return err == nil
+	})
+	return err
+}
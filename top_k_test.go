@@ -0,0 +1,141 @@
+package immutable
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopK(t *testing.T) {
+	assert.Panics(t, func() {
+		NewTopK[int](0)
+	})
+	assert.Panics(t, func() {
+		NewBottomK[int](-1)
+	})
+
+	top := NewTopK[int](3)
+	assert.Equal(t, 0, top.Len())
+	assert.Equal(t, 3, top.Cap())
+	assert.Nil(t, top.ToSlice())
+	_, ok := top.Best()
+	assert.False(t, ok)
+	_, ok = top.Worst()
+	assert.False(t, ok)
+
+	for _, v := range []int{5, 1, 9} {
+		top, ok = top.Push(v)
+		assert.True(t, ok)
+	}
+	assert.Equal(t, []int{9, 5, 1}, top.ToSlice())
+
+	top2, ok := top.Push(1)
+	assert.False(t, ok)
+	assert.Same(t, top, top2)
+
+	top2, ok = top.Push(7)
+	assert.True(t, ok)
+	assert.Equal(t, []int{9, 7, 5}, top2.ToSlice())
+	assert.Equal(t, []int{9, 5, 1}, top.ToSlice())
+
+	best, _ := top2.Best()
+	worst, _ := top2.Worst()
+	assert.Equal(t, 9, best)
+	assert.Equal(t, 5, worst)
+
+	assert.Panics(t, func() {
+		NewTopK[float64](1).Push(math.NaN())
+	})
+}
+
+func TestTopK_Random(t *testing.T) {
+	for _, smallest := range []bool{false, true} {
+		for _, k := range []int{1, 2, 10} {
+			top := NewTopK[int](k)
+			if smallest {
+				top = NewBottomK[int](k)
+			}
+			var all []int
+			for i := 0; i < 500; i++ {
+				v := rand.Intn(100)
+				all = append(all, v)
+				top, _ = top.Push(v)
+				require.NoError(t, top.CheckInvariants())
+
+				expected := append([]int(nil), all...)
+				sort.Ints(expected)
+				if !smallest {
+					sort.Sort(sort.Reverse(sort.IntSlice(expected)))
+				}
+				if len(expected) > k {
+					expected = expected[:k]
+				}
+				require.Equal(t, expected, top.ToSlice())
+			}
+		}
+	}
+}
+
+func TestBottomK(t *testing.T) {
+	bottom := NewBottomK[string](2)
+	for _, s := range []string{"m", "c", "x", "a"} {
+		bottom, _ = bottom.Push(s)
+	}
+	assert.Equal(t, []string{"a", "c"}, bottom.ToSlice())
+	_, ok := bottom.Push("d")
+	assert.False(t, ok)
+	best, _ := bottom.Best()
+	assert.Equal(t, "a", best)
+}
+
+func TestTopKFunc(t *testing.T) {
+	type player struct {
+		name  string
+		score int
+	}
+	top := NewTopKFunc(2, func(a, b player) int {
+		return a.score - b.score
+	})
+	for _, p := range []player{{"a", 10}, {"b", 30}, {"c", 20}, {"d", 30}, {"e", 20}} {
+		top, _ = top.Push(p)
+	}
+	assert.Equal(t, []player{{"b", 30}, {"d", 30}}, top.ToSlice())
+	best, _ := top.Best()
+	assert.Equal(t, player{"b", 30}, best)
+	worst, _ := top.Worst()
+	assert.Equal(t, player{"d", 30}, worst)
+
+	// Equal items don't displace earlier ones.
+	_, ok := top.Push(player{"f", 30})
+	assert.False(t, ok)
+	require.NoError(t, top.CheckInvariants())
+
+	// A collection over its capacity is detected.
+	corrupted := *top
+	corrupted.capacity = 1
+	assert.Error(t, corrupted.CheckInvariants())
+
+	assert.Panics(t, func() {
+		NewTopKFunc(0, func(a, b player) int { return 0 })
+	})
+}
+
+func TestTopK_Zero(t *testing.T) {
+	for _, top := range []*TopK[int]{nil, {}} {
+		assert.Equal(t, 0, top.Len())
+		assert.Equal(t, 0, top.Cap())
+		assert.Nil(t, top.ToSlice())
+		_, ok := top.Best()
+		assert.False(t, ok)
+		_, ok = top.Worst()
+		assert.False(t, ok)
+		top2, ok := top.Push(1)
+		assert.False(t, ok)
+		assert.Same(t, top, top2)
+		assert.NoError(t, top.CheckInvariants())
+	}
+}
@@ -0,0 +1,349 @@
+package immutable
+
+import (
+	"fmt"
+	"math/rand"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Treap implements an ordered map as a treap: a binary search tree whose nodes are also heap-ordered
+// by random priorities. It has the same interface as OrderedMap, but is balanced only in
+// expectation, so its complexities are expected rather than worst-case. For individual lookups and
+// updates, OrderedMap is usually faster, but treaps can be combined with Union, Intersection, and
+// Difference far more efficiently than by modifying one map element by element. Workloads vary, and
+// OrderedContainer makes it easy to benchmark both.
+//
+// Like OrderedMap, it doesn't support NaN keys.
+//
+// Nil and the zero value for Treap are both empty maps.
+type Treap[K constraints.Ordered, V any] struct {
+	left     *Treap[K, V]
+	right    *Treap[K, V]
+	key      K
+	value    V
+	priority uint64
+	len      int
+}
+
+// Empty returns true if the map is empty.
+//
+// Complexity: O(1) worst-case
+func (t *Treap[K, V]) Empty() bool {
+	return t == nil || t.len == 0
+}
+
+// Len returns the number of elements in the map.
+//
+// Complexity: O(1) worst-case
+func (t *Treap[K, V]) Len() int {
+	if t == nil {
+		return 0
+	}
+	return t.len
+}
+
+// Get returns the value associated with the given key if set.
+//
+// Complexity: O(log n) expected
+func (t *Treap[K, V]) Get(key K) (v V, exists bool) {
+	if key != key {
+		return v, false
+	}
+	for !t.Empty() {
+		if key < t.key {
+			t = t.left
+		} else if t.key < key {
+			t = t.right
+		} else {
+			return t.value, true
+		}
+	}
+	return v, false
+}
+
+// Set associates a value with the given key. It panics if the key is NaN.
+//
+// Complexity: O(log n) expected
+func (t *Treap[K, V]) Set(key K, value V) *Treap[K, V] {
+	orderedMapCheckKey(key)
+	return t.insert(key, value, rand.Uint64())
+}
+
+// Delete removes a key from the map.
+//
+// Complexity: O(log n) expected
+func (t *Treap[K, V]) Delete(key K) *Treap[K, V] {
+	if key != key {
+		return t
+	}
+	ret, didDelete := t.delete(key)
+	if !didDelete {
+		return t
+	}
+	return ret
+}
+
+// Split divides the map into a map containing the elements with keys less than the given key and a
+// map containing the rest. It panics if the key is NaN.
+//
+// Complexity: O(log n) expected
+func (t *Treap[K, V]) Split(key K) (left, right *Treap[K, V]) {
+	orderedMapCheckKey(key)
+	return t.splitLess(key)
+}
+
+// Concat returns a map containing the elements of both maps. Every key of this map must be less
+// than every key of the other, or it panics. Unlike Union, this doesn't need to compare the maps'
+// keys beyond checking that requirement.
+//
+// Complexity: O(log n) expected
+func (t *Treap[K, V]) Concat(other *Treap[K, V]) *Treap[K, V] {
+	if !t.Empty() && !other.Empty() && !(t.max().key < other.min().key) {
+		panic("immutable: Concat requires the keys of the other map to be greater")
+	}
+	return treapMerge(t, other)
+}
+
+// Union returns a map containing the elements of both maps. For keys in both, the value is given by
+// f, which receives this map's value and then the other map's.
+//
+// This is much faster than setting the elements of one map in the other: when m is the length of the
+// smaller map and n is the length of the larger one, it's only linear in m.
+//
+// Complexity: O(m log(n/m + 1)) expected
+func (t *Treap[K, V]) Union(other *Treap[K, V], f func(key K, a, b V) V) *Treap[K, V] {
+	return treapUnion(t, other, f, false)
+}
+
+// Intersection returns a map containing the keys in both maps, with values given by f, which
+// receives this map's value and then the other map's.
+//
+// Complexity: O(m log(n/m + 1)) expected
+func (t *Treap[K, V]) Intersection(other *Treap[K, V], f func(key K, a, b V) V) *Treap[K, V] {
+	return treapIntersection(t, other, f, false)
+}
+
+// Difference returns a map containing the elements of this map whose keys aren't in the other map.
+//
+// Complexity: O(m log(n/m + 1)) expected
+func (t *Treap[K, V]) Difference(other *Treap[K, V]) *Treap[K, V] {
+	if t.Empty() || other.Empty() {
+		return t
+	}
+	return treapDifference(t, other)
+}
+
+// ForEach calls f for each element in the map in ascending order of keys, stopping early if f
+// returns false.
+//
+// Complexity: O(n) worst-case
+func (t *Treap[K, V]) ForEach(f func(key K, value V) bool) {
+	t.forEach(f)
+}
+
+func (t *Treap[K, V]) forEach(f func(key K, value V) bool) bool {
+	if t.Empty() {
+		return true
+	}
+	return t.left.forEach(f) && f(t.key, t.value) && t.right.forEach(f)
+}
+
+// CheckInvariants verifies the structural integrity of the map, returning an error describing the
+// first problem found. It checks that keys are in ascending order, that every node's priority is at
+// least that of its children, and that cached lengths are correct.
+//
+// Complexity: O(n) worst-case
+func (t *Treap[K, V]) CheckInvariants() error {
+	if t != nil && t.len == 0 {
+		if t.left != nil || t.right != nil {
+			return fmt.Errorf("invalid empty map")
+		}
+		return nil
+	}
+	return t.checkInvariants(nil, nil)
+}
+
+// checkInvariants checks the invariants of the subtree, whose keys must be between lo and hi if
+// given.
+func (t *Treap[K, V]) checkInvariants(lo, hi *K) error {
+	if t == nil {
+		return nil
+	}
+	if (lo != nil && !(*lo < t.key)) || (hi != nil && !(t.key < *hi)) {
+		return fmt.Errorf("key %v is out of order", t.key)
+	}
+	if t.len != 1+t.left.Len()+t.right.Len() {
+		return fmt.Errorf("node with key %v has incorrect length %v", t.key, t.len)
+	}
+	if (t.left != nil && t.left.priority > t.priority) || (t.right != nil && t.right.priority > t.priority) {
+		return fmt.Errorf("node with key %v has a child with a higher priority", t.key)
+	}
+	if err := t.left.checkInvariants(lo, &t.key); err != nil {
+		return err
+	}
+	return t.right.checkInvariants(&t.key, hi)
+}
+
+// treapNode returns a new node with the given children. Empty children are replaced with nil.
+func treapNode[K constraints.Ordered, V any](left *Treap[K, V], key K, value V, priority uint64, right *Treap[K, V]) *Treap[K, V] {
+	if left.Empty() {
+		left = nil
+	}
+	if right.Empty() {
+		right = nil
+	}
+	return &Treap[K, V]{
+		left:     left,
+		right:    right,
+		key:      key,
+		value:    value,
+		priority: priority,
+		len:      1 + left.Len() + right.Len(),
+	}
+}
+
+// withChildren returns a copy of the node with the given children.
+func (t *Treap[K, V]) withChildren(left, right *Treap[K, V]) *Treap[K, V] {
+	return treapNode(left, t.key, t.value, t.priority, right)
+}
+
+func (t *Treap[K, V]) insert(key K, value V, priority uint64) *Treap[K, V] {
+	if t.Empty() || priority > t.priority {
+		// The new node belongs above this one, so this subtree is split to become its children. If
+		// the key is already set, its node is discarded by the split.
+		left, _, right := t.split(key)
+		return treapNode(left, key, value, priority, right)
+	} else if key < t.key {
+		return t.withChildren(t.left.insert(key, value, priority), t.right)
+	} else if t.key < key {
+		return t.withChildren(t.left, t.right.insert(key, value, priority))
+	}
+	ret := *t
+	ret.value = value
+	return &ret
+}
+
+func (t *Treap[K, V]) delete(key K) (*Treap[K, V], bool) {
+	if t.Empty() {
+		return nil, false
+	} else if key < t.key {
+		left, ok := t.left.delete(key)
+		if !ok {
+			return t, false
+		}
+		return t.withChildren(left, t.right), true
+	} else if t.key < key {
+		right, ok := t.right.delete(key)
+		if !ok {
+			return t, false
+		}
+		return t.withChildren(t.left, right), true
+	}
+	return treapMerge(t.left, t.right), true
+}
+
+// split returns the elements less than key, the node with the key if it exists, and the elements
+// greater than key.
+func (t *Treap[K, V]) split(key K) (left, found, right *Treap[K, V]) {
+	if t.Empty() {
+		return nil, nil, nil
+	} else if key < t.key {
+		left, found, right = t.left.split(key)
+		return left, found, t.withChildren(right, t.right)
+	} else if t.key < key {
+		left, found, right = t.right.split(key)
+		return t.withChildren(t.left, left), found, right
+	}
+	return t.left, t, t.right
+}
+
+func (t *Treap[K, V]) splitLess(key K) (left, right *Treap[K, V]) {
+	if t.Empty() {
+		return nil, nil
+	} else if t.key < key {
+		left, right = t.right.splitLess(key)
+		return t.withChildren(t.left, left), right
+	}
+	left, right = t.left.splitLess(key)
+	return left, t.withChildren(right, t.right)
+}
+
+func (t *Treap[K, V]) min() *Treap[K, V] {
+	for t.left != nil {
+		t = t.left
+	}
+	return t
+}
+
+func (t *Treap[K, V]) max() *Treap[K, V] {
+	for t.right != nil {
+		t = t.right
+	}
+	return t
+}
+
+// treapUnion returns the union of two maps. The root with the higher priority stays at the top, and
+// the other map is split around its key. If swapped is true, a and b have been swapped relative to
+// the arguments f expects.
+func treapUnion[K constraints.Ordered, V any](a, b *Treap[K, V], f func(key K, a, b V) V, swapped bool) *Treap[K, V] {
+	if a.Empty() {
+		return b
+	} else if b.Empty() {
+		return a
+	} else if a.priority < b.priority {
+		a, b, swapped = b, a, !swapped
+	}
+	left, found, right := b.split(a.key)
+	value := a.value
+	if found != nil {
+		if swapped {
+			value = f(a.key, found.value, a.value)
+		} else {
+			value = f(a.key, a.value, found.value)
+		}
+	}
+	return treapNode(treapUnion(a.left, left, f, swapped), a.key, value, a.priority, treapUnion(a.right, right, f, swapped))
+}
+
+func treapIntersection[K constraints.Ordered, V any](a, b *Treap[K, V], f func(key K, a, b V) V, swapped bool) *Treap[K, V] {
+	if a.Empty() || b.Empty() {
+		return nil
+	} else if a.priority < b.priority {
+		a, b, swapped = b, a, !swapped
+	}
+	left, found, right := b.split(a.key)
+	left = treapIntersection(a.left, left, f, swapped)
+	right = treapIntersection(a.right, right, f, swapped)
+	if found == nil {
+		return treapMerge(left, right)
+	} else if swapped {
+		return treapNode(left, a.key, f(a.key, found.value, a.value), a.priority, right)
+	}
+	return treapNode(left, a.key, f(a.key, a.value, found.value), a.priority, right)
+}
+
+// treapDifference returns the elements of a whose keys aren't in b. The first map is split around
+// the key of the second's root, whose subtrees are then subtracted from the halves.
+func treapDifference[K constraints.Ordered, V any](a, b *Treap[K, V]) *Treap[K, V] {
+	if a.Empty() {
+		return nil
+	} else if b.Empty() {
+		return a
+	}
+	left, _, right := a.split(b.key)
+	return treapMerge(treapDifference(left, b.left), treapDifference(right, b.right))
+}
+
+// treapMerge returns the union of two maps, where every key of left is less than every key of
+// right.
+func treapMerge[K constraints.Ordered, V any](left, right *Treap[K, V]) *Treap[K, V] {
+	if left.Empty() {
+		return right
+	} else if right.Empty() {
+		return left
+	} else if left.priority > right.priority {
+		return left.withChildren(left.left, treapMerge(left.right, right))
+	}
+	return right.withChildren(treapMerge(left, right.left), right.right)
+}
@@ -0,0 +1,214 @@
+package immutable
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTreap(t *testing.T) {
+	var m *Treap[int, int]
+	assert.True(t, m.Empty())
+	assert.Equal(t, 0, m.Len())
+	assert.Nil(t, m.Delete(1))
+	assert.NoError(t, m.CheckInvariants())
+	_, ok := m.Get(1)
+	assert.False(t, ok)
+
+	assert.Equal(t, 0, (&Treap[int, int]{}).Len())
+	assert.NoError(t, (&Treap[int, int]{}).CheckInvariants())
+	assert.Equal(t, 1, (&Treap[int, int]{}).Set(1, 1).Len())
+
+	ref := map[int]int{}
+	for i := 0; i < 10000; i++ {
+		k := rand.Intn(1000)
+		if rand.Intn(3) == 0 {
+			delete(ref, k)
+			m = m.Delete(k)
+		} else {
+			ref[k] = i
+			m = m.Set(k, i)
+		}
+		require.NoError(t, m.CheckInvariants())
+		require.Equal(t, len(ref), m.Len())
+		v, ok := m.Get(k)
+		expected, expectedOk := ref[k]
+		require.Equal(t, expectedOk, ok)
+		require.Equal(t, expected, v)
+	}
+
+	actual := map[int]int{}
+	prev := math.MinInt
+	m.ForEach(func(key, value int) bool {
+		assert.Greater(t, key, prev)
+		prev = key
+		actual[key] = value
+		return true
+	})
+	assert.Equal(t, ref, actual)
+
+	n := 0
+	m.ForEach(func(key, value int) bool {
+		n++
+		return n < 3
+	})
+	assert.Equal(t, 3, n)
+
+	// Deleting a key that doesn't exist returns the same map.
+	assert.Same(t, m, m.Delete(-1))
+}
+
+func TestTreap_NaN(t *testing.T) {
+	m := (*Treap[float64, int])(nil).Set(1, 1)
+	assert.Panics(t, func() {
+		m.Set(math.NaN(), 1)
+	})
+	_, ok := m.Get(math.NaN())
+	assert.False(t, ok)
+	assert.Same(t, m, m.Delete(math.NaN()))
+}
+
+func TestTreap_Persistence(t *testing.T) {
+	var versions []*Treap[int, int]
+	var m *Treap[int, int]
+	for i := 0; i < 100; i++ {
+		m = m.Set(i, i)
+		versions = append(versions, m)
+	}
+	for i := 0; i < 100; i += 2 {
+		m = m.Delete(i)
+	}
+	m = m.Set(1, -1)
+	for i, v := range versions {
+		require.Equal(t, i+1, v.Len())
+		value, ok := v.Get(i)
+		require.True(t, ok)
+		require.Equal(t, i, value)
+	}
+	assert.Equal(t, 50, m.Len())
+	v, _ := m.Get(1)
+	assert.Equal(t, -1, v)
+}
+
+func randomTreap(n, keys int) (*Treap[int, int], map[int]int) {
+	var m *Treap[int, int]
+	ref := map[int]int{}
+	for i := 0; i < n; i++ {
+		k := rand.Intn(keys)
+		m = m.Set(k, i)
+		ref[k] = i
+	}
+	return m, ref
+}
+
+func TestTreap_Split(t *testing.T) {
+	m, ref := randomTreap(1000, 2000)
+	for _, key := range []int{-1, 0, 500, 1000, 1999, 2000} {
+		left, right := m.Split(key)
+		require.NoError(t, left.CheckInvariants())
+		require.NoError(t, right.CheckInvariants())
+		assert.Equal(t, m.Len(), left.Len()+right.Len())
+		left.ForEach(func(k, v int) bool {
+			assert.Less(t, k, key)
+			assert.Equal(t, ref[k], v)
+			return true
+		})
+		right.ForEach(func(k, v int) bool {
+			assert.GreaterOrEqual(t, k, key)
+			assert.Equal(t, ref[k], v)
+			return true
+		})
+
+		joined := left.Concat(right)
+		require.NoError(t, joined.CheckInvariants())
+		assert.Equal(t, ref, orderedContainerToMap(joined))
+	}
+
+	assert.Panics(t, func() {
+		m.Concat(m)
+	})
+	assert.Panics(t, func() {
+		(*Treap[float64, int])(nil).Split(math.NaN())
+	})
+	assert.Same(t, m, m.Concat(nil))
+	assert.Same(t, m, (&Treap[int, int]{}).Concat(m))
+}
+
+func TestTreap_SetAlgebra(t *testing.T) {
+	sum := func(key, a, b int) int {
+		return a*1000 + b
+	}
+	for _, sizes := range [][2]int{{0, 0}, {0, 100}, {100, 0}, {10, 1000}, {1000, 10}, {1000, 1000}} {
+		a, aRef := randomTreap(sizes[0], 2000)
+		b, bRef := randomTreap(sizes[1], 2000)
+
+		union := map[int]int{}
+		intersection := map[int]int{}
+		difference := map[int]int{}
+		for k, v := range aRef {
+			if bv, ok := bRef[k]; ok {
+				union[k] = sum(k, v, bv)
+				intersection[k] = sum(k, v, bv)
+			} else {
+				union[k] = v
+				difference[k] = v
+			}
+		}
+		for k, v := range bRef {
+			if _, ok := aRef[k]; !ok {
+				union[k] = v
+			}
+		}
+
+		u := a.Union(b, sum)
+		require.NoError(t, u.CheckInvariants())
+		assert.Equal(t, union, orderedContainerToMap(u))
+
+		i := a.Intersection(b, sum)
+		require.NoError(t, i.CheckInvariants())
+		assert.Equal(t, intersection, orderedContainerToMap(i))
+
+		d := a.Difference(b)
+		require.NoError(t, d.CheckInvariants())
+		assert.Equal(t, difference, orderedContainerToMap(d))
+
+		// The inputs are unchanged.
+		assert.Equal(t, aRef, orderedContainerToMap(a))
+		assert.Equal(t, bRef, orderedContainerToMap(b))
+	}
+}
+
+var treapResult *Treap[int, int]
+
+func BenchmarkTreap_Union(b *testing.B) {
+	for _, n := range []int{100, 10000} {
+		var x, y *Treap[int, int]
+		for i := 0; i < n; i++ {
+			x = x.Set(i*2, i)
+			y = y.Set(i*2+1, i)
+		}
+		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				treapResult = x.Union(y, func(key, a, b int) int {
+					return b
+				})
+			}
+		})
+		b.Run(fmt.Sprintf("n=%v/Set", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m := x
+				y.ForEach(func(key, value int) bool {
+					m = m.Set(key, value)
+					return true
+				})
+				treapResult = m
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package immutable
+
+// Ref holds a value, typically a struct whose fields are persistent containers, which can be
+// replaced atomically. Combined with Txn, it makes updating several containers at once all or
+// nothing: readers see either all of a transaction's changes or none of them.
+//
+// Ref is safe for concurrent use.
+//
+// The zero value holds the zero value of S and is ready to use. A Ref must not be copied after
+// first use.
+type Ref[S any] struct {
+	// The counters come first so that they're 64-bit aligned on 32-bit platforms.
+	commits   atomicUint64
+	conflicts atomicUint64
+	p         atomicPointer[refValue[S]]
+}
+
+type refValue[S any] struct {
+	value   S
+	version uint64
+}
+
+func (v *refValue[S]) next(value S) *refValue[S] {
+	ret := &refValue[S]{
+		value:   value,
+		version: 1,
+	}
+	if v != nil {
+		ret.version += v.version
+	}
+	return ret
+}
+
+// NewRef returns a Ref holding the given value.
+//
+// Complexity: O(1) worst-case
+func NewRef[S any](value S) *Ref[S] {
+	ret := &Ref[S]{}
+	ret.p.Store(&refValue[S]{
+		value: value,
+	})
+	return ret
+}
+
+// Load returns the current value.
+//
+// Complexity: O(1) worst-case
+func (r *Ref[S]) Load() S {
+	if p := r.p.Load(); p != nil {
+		return p.value
+	}
+	var zero S
+	return zero
+}
+
+// Version returns the number of times the value has been replaced, by Store, Update, or a committed
+// transaction. This is useful for monitoring, and for detecting changes without comparing values.
+//
+// Complexity: O(1) worst-case
+func (r *Ref[S]) Version() uint64 {
+	if p := r.p.Load(); p != nil {
+		return p.version
+	}
+	return 0
+}
+
+// Commits returns the number of transactions which were committed, including those made by Update.
+// Unlike Version, it doesn't include calls to Store.
+//
+// Complexity: O(1) worst-case
+func (r *Ref[S]) Commits() uint64 {
+	return r.commits.Load()
+}
+
+// Conflicts returns the number of transactions which failed to commit because the value was
+// replaced after they began. This includes retries made by Update. A high rate of conflicts
+// indicates heavy contention.
+//
+// Complexity: O(1) worst-case
+func (r *Ref[S]) Conflicts() uint64 {
+	return r.conflicts.Load()
+}
+
+// RetainedBytes estimates the memory retained by the current value, which count adds to a
+// MemoryCounter, typically by calling CountMemory on the containers within it. Values from older
+// versions are only retained by whoever still holds them, so they aren't included. To estimate the
+// memory retained by several versions together, such as snapshots loaded from the Ref over time,
+// add them to the same MemoryCounter.
+//
+// Complexity: O(1) worst-case, not including the cost of count
+func (r *Ref[S]) RetainedBytes(count func(value S, c *MemoryCounter)) uintptr {
+	var c MemoryCounter
+	count(r.Load(), &c)
+	return c.Bytes()
+}
+
+// Store replaces the current value.
+//
+// Complexity: O(1) worst-case, if there is no contention
+func (r *Ref[S]) Store(value S) {
+	for {
+		old := r.p.Load()
+		if r.p.CompareAndSwap(old, old.next(value)) {
+			return
+		}
+	}
+}
+
+// Update replaces the current value with the result of f, retrying if another goroutine changed the
+// value first. As such, f may be called more than once and should not have side effects.
+//
+// Complexity: O(1) worst-case, if there is no contention, not including the cost of f
+func (r *Ref[S]) Update(f func(value S) S) S {
+	for {
+		txn := r.Begin()
+		txn.State = f(txn.State)
+		if txn.Commit() {
+			return txn.State
+		}
+	}
+}
+
+// Begin starts a transaction based on the current value.
+//
+// Complexity: O(1) worst-case
+func (r *Ref[S]) Begin() *Txn[S] {
+	base := r.p.Load()
+	ret := &Txn[S]{
+		ref:  r,
+		base: base,
+	}
+	if base != nil {
+		ret.State = base.value
+	}
+	return ret
+}
+
+// Txn is a transaction which groups changes to the value of a Ref. Changes are made to State, and
+// take effect only if the transaction is committed.
+//
+// Since the containers in State are persistent, modifying them doesn't affect the Ref or any other
+// transaction, so discarding a transaction never requires undoing anything.
+type Txn[S any] struct {
+	// State is the transaction's working copy of the value. It starts out as the Ref's value at the
+	// time the transaction began.
+	State S
+
+	ref  *Ref[S]
+	base *refValue[S]
+	done bool
+}
+
+// Commit atomically replaces the Ref's value with State. If the Ref's value was replaced since the
+// transaction began, nothing is changed and false is returned. In that case, the caller can begin a
+// new transaction and try again. It panics if the transaction was already committed or discarded.
+//
+// Complexity: O(1) worst-case
+func (t *Txn[S]) Commit() bool {
+	t.finish()
+	if !t.ref.p.CompareAndSwap(t.base, t.base.next(t.State)) {
+		t.ref.conflicts.Add(1)
+		return false
+	}
+	t.ref.commits.Add(1)
+	return true
+}
+
+// Discard abandons the transaction, leaving the Ref unchanged. It panics if the transaction was
+// already committed or discarded.
+//
+// Complexity: O(1) worst-case
+func (t *Txn[S]) Discard() {
+	t.finish()
+}
+
+func (t *Txn[S]) finish() {
+	if t.done {
+		panic("immutable: Txn is already finished")
+	}
+	t.done = true
+}
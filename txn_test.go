@@ -0,0 +1,107 @@
+package immutable
+
+import (
+	"sync"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type txnTestState struct {
+	accounts *OrderedMap[string, int]
+	log      *Queue[string]
+}
+
+func TestRef(t *testing.T) {
+	var r Ref[txnTestState]
+	assert.Nil(t, r.Load().accounts)
+
+	txn := r.Begin()
+	txn.State.accounts = txn.State.accounts.Set("a", 100).Set("b", 0)
+	txn.State.log = txn.State.log.PushBack("open")
+	assert.Nil(t, r.Load().accounts)
+	require.True(t, txn.Commit())
+	assert.Equal(t, map[string]int{"a": 100, "b": 0}, r.Load().accounts.ToMap())
+	assert.Equal(t, []string{"open"}, r.Load().log.ToSlice())
+
+	txn = r.Begin()
+	txn.State.accounts = txn.State.accounts.Set("a", 0)
+	txn.Discard()
+	assert.Equal(t, map[string]int{"a": 100, "b": 0}, r.Load().accounts.ToMap())
+
+	// A transaction conflicts with changes made after it began.
+	txn = r.Begin()
+	txn.State.log = txn.State.log.PushBack("stale")
+	r.Update(func(s txnTestState) txnTestState {
+		s.log = s.log.PushBack("update")
+		return s
+	})
+	assert.False(t, txn.Commit())
+	assert.Equal(t, []string{"open", "update"}, r.Load().log.ToSlice())
+	assert.Equal(t, uint64(2), r.Version())
+	assert.Equal(t, uint64(2), r.Commits())
+	assert.Equal(t, uint64(1), r.Conflicts())
+
+	assert.Panics(t, func() {
+		txn.Commit()
+	})
+	assert.Panics(t, func() {
+		txn.Discard()
+	})
+
+	r2 := NewRef(3)
+	assert.Equal(t, 3, r2.Load())
+	assert.Equal(t, uint64(0), r2.Version())
+	r2.Store(4)
+	assert.Equal(t, 4, r2.Load())
+	assert.Equal(t, uint64(1), r2.Version())
+	assert.Equal(t, uint64(0), r2.Commits())
+}
+
+func TestRef_Concurrency(t *testing.T) {
+	r := NewRef(txnTestState{
+		accounts: (*OrderedMap[string, int])(nil).Set("a", 1000).Set("b", 1000),
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.Update(func(s txnTestState) txnTestState {
+					a, _ := s.accounts.Get("a")
+					b, _ := s.accounts.Get("b")
+					s.accounts = s.accounts.Set("a", a-1).Set("b", b+1)
+					s.log = s.log.PushBack("transfer")
+					return s
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	s := r.Load()
+	a, _ := s.accounts.Get("a")
+	b, _ := s.accounts.Get("b")
+	assert.Equal(t, 200, a)
+	assert.Equal(t, 1800, b)
+	assert.Len(t, s.log.ToSlice(), 800)
+	assert.Equal(t, uint64(800), r.Version())
+	assert.Equal(t, uint64(800), r.Commits())
+}
+
+func TestRef_RetainedBytes(t *testing.T) {
+	count := func(s txnTestState, c *MemoryCounter) {
+		s.accounts.CountMemory(c)
+	}
+	var r Ref[txnTestState]
+	assert.Equal(t, uintptr(0), r.RetainedBytes(count))
+	r.Update(func(s txnTestState) txnTestState {
+		s.accounts = s.accounts.Set("a", 1).Set("b", 2)
+		return s
+	})
+	assert.Equal(t, 2*unsafe.Sizeof(OrderedMap[string, int]{}), r.RetainedBytes(count))
+}
@@ -0,0 +1,176 @@
+package immutable
+
+import "fmt"
+
+// UnionFind implements a disjoint-set structure, which partitions elements into sets that can be
+// merged and queried for membership. Because it is persistent, merges can be undone by keeping old
+// versions.
+//
+// Elements can be any comparable type. Every element implicitly starts out in a set of its own, so
+// elements don't need to be added before use.
+//
+// Sets are merged by size, which keeps every tree shallow without the path compression that a
+// mutable implementation would use.
+//
+// Nil and the zero value for UnionFind are both structures in which every element is in its own
+// set.
+type UnionFind[T comparable] struct {
+	// The parent of each element which isn't the root of its set.
+	parents *hashMap[T, T]
+
+	// The size of each set with more than one element, keyed by root.
+	sizes *hashMap[T, int]
+
+	sets int
+}
+
+func (u *UnionFind[T]) parentMap() *hashMap[T, T] {
+	if u == nil {
+		return nil
+	}
+	return u.parents
+}
+
+// Find returns the representative element of the set containing x. Two elements are in the same
+// set if and only if they have the same representative.
+//
+// Complexity: O(log² n) expected
+func (u *UnionFind[T]) Find(x T) T {
+	parents := u.parentMap()
+	for {
+		parent, ok := parents.Get(x)
+		if !ok {
+			return x
+		}
+		x = parent
+	}
+}
+
+// Connected returns true if a and b are in the same set.
+//
+// Complexity: O(log² n) expected
+func (u *UnionFind[T]) Connected(a, b T) bool {
+	return u.Find(a) == u.Find(b)
+}
+
+// Size returns the number of elements in the set containing x.
+//
+// Complexity: O(log² n) expected
+func (u *UnionFind[T]) Size(x T) int {
+	if u == nil {
+		return 1
+	}
+	if size, ok := u.sizes.Get(u.Find(x)); ok {
+		return size
+	}
+	return 1
+}
+
+// MergedSets returns the number of sets with more than one element.
+//
+// Complexity: O(1) worst-case
+func (u *UnionFind[T]) MergedSets() int {
+	if u == nil {
+		return 0
+	}
+	return u.sets
+}
+
+// Union merges the sets containing a and b. If they're already in the same set, u is returned
+// unchanged. Like OrderedMap.Set, it panics if either element is NaN.
+//
+// Complexity: O(log² n) expected
+func (u *UnionFind[T]) Union(a, b T) *UnionFind[T] {
+	a, b = u.Find(a), u.Find(b)
+	if a == b {
+		return u
+	}
+	var sizes *hashMap[T, int]
+	sets := 0
+	if u != nil {
+		sizes, sets = u.sizes, u.sets
+	}
+	sizeA, mergedA := sizes.Get(a)
+	if !mergedA {
+		sizeA = 1
+	}
+	sizeB, mergedB := sizes.Get(b)
+	if !mergedB {
+		sizeB = 1
+	}
+	if sizeA < sizeB {
+		a, b = b, a
+	}
+	// Attach b's tree beneath a's root.
+	switch {
+	case !mergedA && !mergedB:
+		sets++
+	case mergedA && mergedB:
+		sets--
+	}
+	return &UnionFind[T]{
+		parents: u.parentMap().Set(b, a),
+		sizes:   sizes.Delete(b).Set(a, sizeA+sizeB),
+		sets:    sets,
+	}
+}
+
+// CheckInvariants verifies the structural integrity of the structure, returning an error describing
+// the first problem found. It checks that following parents from any element leads to a root
+// without cycles, that the recorded size of each root is the number of elements in its set, that
+// no element is deeper than the logarithm of its set's size, which is what merging by size
+// guarantees, and that the recorded number of merged sets is correct.
+//
+// Complexity: O(n log n) expected
+func (u *UnionFind[T]) CheckInvariants() error {
+	if u == nil {
+		return nil
+	} else if err := u.parents.checkInvariants(); err != nil {
+		return err
+	} else if err := u.sizes.checkInvariants(); err != nil {
+		return err
+	} else if u.sizes.Len() != u.sets {
+		return fmt.Errorf("structure has %v merged sets, but %v were recorded", u.sizes.Len(), u.sets)
+	}
+	var err error
+	u.sizes.ForEach(func(root T, size int) bool {
+		if _, ok := u.parents.Get(root); ok {
+			err = fmt.Errorf("set size is recorded for %v, which isn't a root", root)
+		} else if size < 2 {
+			err = fmt.Errorf("merged set rooted at %v has size %v", root, size)
+		}
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	counts := map[T]int{}
+	u.parents.ForEach(func(x, _ T) bool {
+		root, depth := x, 0
+		for parent, ok := u.parents.Get(root); ok; parent, ok = u.parents.Get(root) {
+			if depth++; depth > u.parents.Len() {
+				err = fmt.Errorf("parents of %v form a cycle", x)
+				return false
+			}
+			root = parent
+		}
+		size, ok := u.sizes.Get(root)
+		if !ok {
+			err = fmt.Errorf("%v has a parent, but its root %v has no recorded size", x, root)
+		} else if 1<<depth > size {
+			err = fmt.Errorf("%v has depth %v in a set of size %v", x, depth, size)
+		}
+		counts[root]++
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	u.sizes.ForEach(func(root T, size int) bool {
+		if counts[root]+1 != size {
+			err = fmt.Errorf("set rooted at %v has %v elements, but a size of %v was recorded", root, counts[root]+1, size)
+		}
+		return err == nil
+	})
+	return err
+}
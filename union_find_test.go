@@ -0,0 +1,84 @@
+package immutable
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnionFind(t *testing.T) {
+	var u *UnionFind[string]
+	assert.Equal(t, "a", u.Find("a"))
+	assert.False(t, u.Connected("a", "b"))
+	assert.True(t, u.Connected("a", "a"))
+	assert.Equal(t, 1, u.Size("a"))
+	assert.Equal(t, 0, u.MergedSets())
+
+	u1 := u.Union("a", "b").Union("c", "d")
+	assert.True(t, u1.Connected("a", "b"))
+	assert.True(t, u1.Connected("c", "d"))
+	assert.False(t, u1.Connected("a", "c"))
+	assert.Equal(t, 2, u1.Size("b"))
+	assert.Equal(t, 2, u1.MergedSets())
+	assert.Same(t, u1, u1.Union("b", "a"))
+
+	u2 := u1.Union("b", "d")
+	assert.True(t, u2.Connected("a", "c"))
+	assert.Equal(t, 4, u2.Size("a"))
+	assert.Equal(t, 1, u2.MergedSets())
+
+	// The old version is unaffected.
+	assert.False(t, u1.Connected("a", "c"))
+	assert.Equal(t, 2, u1.Size("a"))
+	require.NoError(t, u2.CheckInvariants())
+
+	// Sizes which don't match the trees are detected.
+	corrupted := *u2
+	corrupted.sizes = corrupted.sizes.Set(u2.Find("a"), 3)
+	assert.Error(t, corrupted.CheckInvariants())
+
+	assert.Panics(t, func() { (*UnionFind[float64])(nil).Union(math.NaN(), 1) })
+}
+
+func TestUnionFind_Comparable(t *testing.T) {
+	type cell struct{ x, y int }
+	var u *UnionFind[cell]
+	u = u.Union(cell{0, 0}, cell{0, 1}).Union(cell{0, 1}, cell{1, 1})
+	assert.True(t, u.Connected(cell{0, 0}, cell{1, 1}))
+	assert.False(t, u.Connected(cell{0, 0}, cell{1, 0}))
+	assert.Equal(t, 3, u.Size(cell{1, 1}))
+}
+
+func TestUnionFind_Fuzz(t *testing.T) {
+	const n = 200
+	ref := make([]int, n)
+	for i := range ref {
+		ref[i] = i
+	}
+	var u *UnionFind[int]
+	for i := 0; i < 500; i++ {
+		a, b := rand.Intn(n), rand.Intn(n)
+		u = u.Union(a, b)
+		if from, to := ref[a], ref[b]; from != to {
+			for j := range ref {
+				if ref[j] == from {
+					ref[j] = to
+				}
+			}
+		}
+		require.NoError(t, u.CheckInvariants())
+
+		a, b = rand.Intn(n), rand.Intn(n)
+		require.Equal(t, ref[a] == ref[b], u.Connected(a, b))
+		size := 0
+		for j := range ref {
+			if ref[j] == ref[a] {
+				size++
+			}
+		}
+		require.Equal(t, size, u.Size(a))
+	}
+}
@@ -0,0 +1,139 @@
+package immutable
+
+const vectorChunkSize = 32
+
+// Vector implements an indexed sequence of items which can be efficiently concatenated and sliced.
+//
+// The items are stored in chunks at the leaves of a balanced tree, like Rope, so concatenating or
+// slicing vectors doesn't require copying their items, and unchanged chunks are shared between
+// versions. This makes vectors well suited for assembling sequences out of many smaller ones.
+//
+// Vector isn't an RRB tree. An RRB tree's wide nodes make indexing faster, but its concatenation and
+// slicing are also logarithmic, and a binary tree of chunks provides the same bounds with much
+// simpler rebalancing.
+//
+// Nil and the zero value for Vector are both empty vectors.
+type Vector[T any] struct {
+	root *chunkTree[T]
+}
+
+// VectorFromSlice returns a new vector containing a copy of the given items.
+//
+// Complexity: O(n) worst-case
+func VectorFromSlice[T any](items []T) *Vector[T] {
+	return newVector(chunkTreeFromSlice(append([]T(nil), items...), vectorChunkSize))
+}
+
+func newVector[T any](root *chunkTree[T]) *Vector[T] {
+	if root == nil {
+		return nil
+	}
+	return &Vector[T]{
+		root: root,
+	}
+}
+
+func (v *Vector[T]) tree() *chunkTree[T] {
+	if v == nil {
+		return nil
+	}
+	return v.root
+}
+
+// Empty returns true if the vector is empty.
+//
+// Complexity: O(1) worst-case
+func (v *Vector[T]) Empty() bool {
+	return v.tree() == nil
+}
+
+// Len returns the number of items in the vector.
+//
+// Complexity: O(1) worst-case
+func (v *Vector[T]) Len() int {
+	return v.tree().Len()
+}
+
+// Get returns the item at the given index. It panics if the index is out of range.
+//
+// Complexity: O(log n) worst-case
+func (v *Vector[T]) Get(i int) T {
+	v.checkIndex(i)
+	return v.root.at(i)
+}
+
+// Set returns a vector with the item at the given index replaced. It panics if the index is out of
+// range.
+//
+// Complexity: O(log n) worst-case
+func (v *Vector[T]) Set(i int, value T) *Vector[T] {
+	v.checkIndex(i)
+	return newVector(v.root.set(i, value))
+}
+
+func (v *Vector[T]) checkIndex(i int) {
+	if i < 0 || i >= v.Len() {
+		panic("immutable: Vector index out of range")
+	}
+}
+
+// Append returns a vector with the given item added to the end. The item is added to the last chunk
+// if it has room, so vectors built by appending are as compact as those built by VectorFromSlice.
+//
+// Complexity: O(log n) worst-case
+func (v *Vector[T]) Append(value T) *Vector[T] {
+	return newVector(v.tree().push(value, vectorChunkSize))
+}
+
+// Concat returns a vector containing the items of this vector followed by the items of the other.
+//
+// Complexity: O(log n) worst-case
+func (v *Vector[T]) Concat(other *Vector[T]) *Vector[T] {
+	return newVector(chunkTreeConcat(v.tree(), other.tree(), vectorChunkSize))
+}
+
+// Slice returns a vector containing the items in the range [i, j). It panics if the range is
+// invalid.
+//
+// Complexity: O(log n) worst-case
+func (v *Vector[T]) Slice(i, j int) *Vector[T] {
+	if i < 0 || j < i || j > v.Len() {
+		panic("immutable: Vector range out of bounds")
+	}
+	_, rest := v.tree().split(i, vectorChunkSize)
+	middle, _ := rest.split(j-i, vectorChunkSize)
+	return newVector(middle)
+}
+
+// ForEach calls f for each item in order, stopping early if f returns false.
+//
+// Complexity: O(n) worst-case
+func (v *Vector[T]) ForEach(f func(T) bool) {
+	v.tree().forEachChunk(func(chunk []T) bool {
+		for _, value := range chunk {
+			if !f(value) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// ToSlice returns the items of the vector as a new slice.
+//
+// Complexity: O(n) worst-case
+func (v *Vector[T]) ToSlice() []T {
+	if v.Empty() {
+		return nil
+	}
+	return v.root.appendTo(make([]T, 0, v.Len()))
+}
+
+// CheckInvariants verifies the structural integrity of the vector, returning an error describing
+// the first problem found. It checks that the tree of chunks is balanced, that no chunk is empty or
+// over capacity, and that each node's cached length and height are correct.
+//
+// Complexity: O(n) worst-case
+func (v *Vector[T]) CheckInvariants() error {
+	return v.tree().checkInvariants(vectorChunkSize)
+}
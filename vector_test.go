@@ -0,0 +1,133 @@
+package immutable
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVector(t *testing.T) {
+	var v *Vector[int]
+	assert.True(t, v.Empty())
+	assert.Equal(t, 0, v.Len())
+	assert.Nil(t, v.ToSlice())
+	assert.Panics(t, func() { v.Get(0) })
+	assert.True(t, VectorFromSlice([]int(nil)).Empty())
+
+	for i := 0; i < 100; i++ {
+		v = v.Append(i)
+		require.NoError(t, v.CheckInvariants())
+	}
+	assert.Equal(t, 100, v.Len())
+	assert.Equal(t, 42, v.Get(42))
+
+	w := v.Set(42, -1)
+	assert.Equal(t, -1, w.Get(42))
+	assert.Equal(t, 42, v.Get(42))
+
+	assert.Equal(t, []int{10, 11, 12}, v.Slice(10, 13).ToSlice())
+	assert.True(t, v.Slice(5, 5).Empty())
+	assert.Equal(t, 200, v.Concat(v).Len())
+	assert.Equal(t, 99, v.Concat(v).Get(199))
+
+	var sum int
+	v.ForEach(func(value int) bool {
+		sum += value
+		return value < 9
+	})
+	assert.Equal(t, 45, sum)
+
+	assert.Panics(t, func() { v.Get(100) })
+	assert.Panics(t, func() { v.Set(-1, 0) })
+	assert.Panics(t, func() { v.Slice(2, 1) })
+	assert.Panics(t, func() { v.Slice(0, 101) })
+}
+
+func TestVector_Append(t *testing.T) {
+	var v *Vector[int]
+	for i := 0; i < 10000; i++ {
+		v = v.Append(i)
+	}
+	require.NoError(t, v.CheckInvariants())
+	require.Equal(t, 10000, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		require.Equal(t, i, v.Get(i))
+	}
+
+	// Every chunk but the last should be full.
+	var chunks []int
+	v.tree().forEachChunk(func(chunk []int) bool {
+		chunks = append(chunks, len(chunk))
+		return true
+	})
+	assert.Len(t, chunks, (10000+vectorChunkSize-1)/vectorChunkSize)
+	for _, n := range chunks[:len(chunks)-1] {
+		assert.Equal(t, vectorChunkSize, n)
+	}
+
+	// Appending to an older version doesn't affect newer ones.
+	w := v.Slice(0, 5)
+	w2 := w.Append(-1)
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, w.ToSlice())
+	assert.Equal(t, []int{0, 1, 2, 3, 4, -1}, w2.ToSlice())
+	assert.Equal(t, 5, v.Get(5))
+}
+
+func TestVector_Fuzz(t *testing.T) {
+	var ref []int
+	var v *Vector[int]
+	for i := 0; i < 2000; i++ {
+		switch rand.Intn(5) {
+		case 0:
+			i := rand.Intn(len(ref) + 1)
+			j := i + rand.Intn(len(ref)-i+1)
+			ref = append([]int(nil), ref[i:j]...)
+			v = v.Slice(i, j)
+		case 1:
+			items := make([]int, rand.Intn(100))
+			for j := range items {
+				items[j] = rand.Int()
+			}
+			ref = append(append(append([]int(nil), ref...), items...), ref...)
+			v = v.Concat(VectorFromSlice(items)).Concat(v)
+			if len(ref) > 10000 {
+				ref = ref[:len(ref)/2]
+				v = v.Slice(0, v.Len()/2)
+			}
+		case 2:
+			if len(ref) > 0 {
+				i, value := rand.Intn(len(ref)), rand.Int()
+				ref = append([]int(nil), ref...)
+				ref[i] = value
+				v = v.Set(i, value)
+			}
+		default:
+			value := rand.Int()
+			ref = append(ref[:len(ref):len(ref)], value)
+			v = v.Append(value)
+		}
+		require.NoError(t, v.CheckInvariants())
+		require.Equal(t, len(ref), v.Len())
+		if len(ref) > 0 {
+			i := rand.Intn(len(ref))
+			require.Equal(t, ref[i], v.Get(i))
+		}
+	}
+	assert.Equal(t, ref, v.ToSlice())
+}
+
+var vectorResult *Vector[int]
+
+func BenchmarkVector_Concat(b *testing.B) {
+	for _, n := range []int{100, 10000, 1000000} {
+		v := VectorFromSlice(make([]int, n))
+		b.Run(fmt.Sprintf("n=%v", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				vectorResult = v.Slice(i%n, n).Concat(v)
+			}
+		})
+	}
+}
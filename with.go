@@ -0,0 +1,60 @@
+package immutable
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// With returns a copy of the given struct with the field at the given path set to value. This makes
+// it convenient to update aggregate state composed of plain structs and persistent containers
+// without modifying the original:
+//
+//	state = immutable.With(state, "Pending", state.Pending.PushBack(job))
+//
+// The path is a field name, or a sequence of field names separated by dots to set a field of a
+// nested struct. The record and any pointers to structs along the path are copied rather than
+// modified, so the original record is never changed. A nil value sets the field to its zero value.
+//
+// With panics if the path doesn't refer to an exported field or if the value can't be assigned to
+// it.
+//
+// Complexity: O(d) worst-case, where d is the total size of the structs along the path
+func With[T any](record T, path string, value any) T {
+	withField(reflect.ValueOf(&record).Elem(), strings.Split(path, "."), path, value)
+	return record
+}
+
+func withField(v reflect.Value, fields []string, path string, value any) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			panic(fmt.Sprintf("immutable: cannot set %q through a nil pointer", path))
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(v.Elem())
+		v.Set(copied)
+		v = copied.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("immutable: cannot set %q of non-struct type %v", path, v.Type()))
+	}
+	field := v.FieldByName(fields[0])
+	if !field.IsValid() {
+		panic(fmt.Sprintf("immutable: %v has no field %q", v.Type(), fields[0]))
+	} else if !field.CanSet() {
+		panic(fmt.Sprintf("immutable: field %q of %v is not exported", fields[0], v.Type()))
+	}
+	if len(fields) > 1 {
+		withField(field, fields[1:], path, value)
+		return
+	}
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return
+	}
+	rv := reflect.ValueOf(value)
+	if !rv.Type().AssignableTo(field.Type()) {
+		panic(fmt.Sprintf("immutable: cannot assign %v to %q of type %v", rv.Type(), path, field.Type()))
+	}
+	field.Set(rv)
+}
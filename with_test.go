@@ -0,0 +1,57 @@
+package immutable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type withTestStats struct {
+	Processed int
+}
+
+type withTestState struct {
+	Name    string
+	Pending *Queue[string]
+	Stats   withTestStats
+	Owner   *withTestStats
+	hidden  int
+}
+
+func TestWith(t *testing.T) {
+	original := withTestState{
+		Name:    "a",
+		Pending: QueueFromSlice([]string{"x"}),
+		Owner:   &withTestStats{Processed: 1},
+	}
+
+	updated := With(original, "Name", "b")
+	assert.Equal(t, "a", original.Name)
+	assert.Equal(t, "b", updated.Name)
+
+	updated = With(updated, "Pending", updated.Pending.PushBack("y"))
+	assert.Equal(t, []string{"x"}, original.Pending.ToSlice())
+	assert.Equal(t, []string{"x", "y"}, updated.Pending.ToSlice())
+
+	updated = With(updated, "Stats.Processed", 5)
+	assert.Equal(t, 0, original.Stats.Processed)
+	assert.Equal(t, 5, updated.Stats.Processed)
+
+	updated = With(updated, "Owner.Processed", 2)
+	assert.Equal(t, 1, original.Owner.Processed)
+	assert.Equal(t, 2, updated.Owner.Processed)
+
+	updated = With(updated, "Pending", nil)
+	assert.Nil(t, updated.Pending)
+
+	pointer := With(&original, "Name", "c")
+	assert.Equal(t, "a", original.Name)
+	assert.Equal(t, "c", pointer.Name)
+
+	assert.Panics(t, func() { With(original, "Missing", 1) })
+	assert.Panics(t, func() { With(original, "hidden", 1) })
+	assert.Panics(t, func() { With(original, "Name", 1) })
+	assert.Panics(t, func() { With(original, "Name.Length", 1) })
+	assert.Panics(t, func() { With(withTestState{}, "Owner.Processed", 1) })
+	assert.Panics(t, func() { With(1, "Name", 1) })
+}